@@ -0,0 +1,145 @@
+// Command dictionary imports a Wiktionary dump extracted by wiktextract, as
+// downloaded from kaikki.org, into the dictionary, optionally with the CEFR
+// levels of a CSV word list. Entries already imported are replaced.
+//
+// Usage:
+//
+//	APP_ENV=prod go run ./cmd/dictionary -wiktionary kaikki.org-dictionary-English.jsonl.gz [-cefr levels.csv] [-batch 500]
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	stdlog "log"
+	"os"
+	"os/signal"
+	"shadowify/internal/config"
+	"shadowify/internal/database"
+	"shadowify/internal/dictionary"
+	"shadowify/internal/logger"
+	"shadowify/internal/model"
+	"shadowify/internal/repository"
+	"strings"
+	"syscall"
+)
+
+func main() {
+	wiktionary := flag.String("wiktionary", "", "path of the wiktextract JSON lines dump, optionally gzipped")
+	cefr := flag.String("cefr", "", "path of a CSV word list with CEFR levels")
+	batch := flag.Int("batch", 500, "number of entries written per query")
+	flag.Parse()
+	if *wiktionary == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	env := os.Getenv("APP_ENV")
+	if env == "" {
+		env = "dev"
+	}
+	cfg, err := config.LoadConfig(fmt.Sprintf("configs/config.%s.yml", env))
+	if err != nil {
+		stdlog.Fatalf("Failed to load config: %v", err)
+	}
+	logger.SetDefaultLogger(logger.NewZerologAdapter(cfg.Logger))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var levels *dictionary.CefrLevels
+	if *cefr != "" {
+		file, err := os.Open(*cefr)
+		if err != nil {
+			stdlog.Fatalf("Failed to open CEFR word list: %v", err)
+		}
+		levels, err = dictionary.ReadCefr(file)
+		file.Close()
+		if err != nil {
+			stdlog.Fatalf("Failed to read CEFR word list: %v", err)
+		}
+	}
+
+	dump, err := open(*wiktionary)
+	if err != nil {
+		stdlog.Fatalf("Failed to open dump: %v", err)
+	}
+	defer dump.Close()
+
+	db, err := database.NewGromDatabase(cfg.Database)
+	if err != nil {
+		stdlog.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer database.Close(db)
+
+	imported, err := importEntries(ctx, repository.NewDictionaryRepository(db), dump, levels, *batch)
+	if err != nil {
+		stdlog.Fatalf("Failed to import dictionary after %d entries: %v", imported, err)
+	}
+	logger.WithFields(logger.Fields{"imported": imported}).Info("Dictionary import completed")
+}
+
+// open opens a file, decompressing it when its name ends in .gz.
+func open(path string) (io.ReadCloser, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(path, ".gz") {
+		return file, nil
+	}
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{reader, file}, nil
+}
+
+// importEntries writes the entries of the dump in batches.
+func importEntries(ctx context.Context, repo *repository.DictionaryRepository, dump io.Reader, levels *dictionary.CefrLevels, batch int) (int, error) {
+	imported := 0
+	entries := make([]*model.DictionaryEntry, 0, batch)
+	// A batch cannot update the same row twice
+	batched := make(map[string]bool, batch)
+	flush := func() error {
+		if err := repo.SaveAll(ctx, entries); err != nil {
+			return err
+		}
+		imported += len(entries)
+		entries = entries[:0]
+		clear(batched)
+		logger.WithFields(logger.Fields{"imported": imported}).Info("Importing")
+		return nil
+	}
+
+	err := dictionary.ReadWiktionary(dump, func(entry *model.DictionaryEntry) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if levels != nil {
+			entry.Cefr = levels.Level(entry.Word, entry.PartOfSpeech)
+		}
+		key := entry.Word + "\x00" + entry.PartOfSpeech
+		if batched[key] {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+		batched[key] = true
+		entries = append(entries, entry)
+		if len(entries) < batch {
+			return nil
+		}
+		return flush()
+	})
+	if err != nil {
+		return imported, err
+	}
+	return imported, flush()
+}
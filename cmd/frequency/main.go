@@ -0,0 +1,67 @@
+// Command frequency imports an English word frequency list, which ranks the
+// lemmas the vocabulary is seeded and the placement quiz is sampled from. The
+// list has a word and its count on each line, most frequent first, such as
+// en_50k.txt of the FrequencyWords project. The previous list is replaced.
+//
+// Usage:
+//
+//	APP_ENV=prod go run ./cmd/frequency -list en_50k.txt
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	stdlog "log"
+	"os"
+	"os/signal"
+	"shadowify/internal/config"
+	"shadowify/internal/database"
+	"shadowify/internal/frequency"
+	"shadowify/internal/logger"
+	"shadowify/internal/repository"
+	"syscall"
+)
+
+func main() {
+	list := flag.String("list", "", "path of the frequency list")
+	flag.Parse()
+	if *list == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	env := os.Getenv("APP_ENV")
+	if env == "" {
+		env = "dev"
+	}
+	cfg, err := config.LoadConfig(fmt.Sprintf("configs/config.%s.yml", env))
+	if err != nil {
+		stdlog.Fatalf("Failed to load config: %v", err)
+	}
+	logger.SetDefaultLogger(logger.NewZerologAdapter(cfg.Logger))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	file, err := os.Open(*list)
+	if err != nil {
+		stdlog.Fatalf("Failed to open frequency list: %v", err)
+	}
+	frequencies, err := frequency.Read(file)
+	file.Close()
+	if err != nil {
+		stdlog.Fatalf("Failed to read frequency list: %v", err)
+	}
+
+	db, err := database.NewGromDatabase(cfg.Database)
+	if err != nil {
+		stdlog.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer database.Close(db)
+
+	if err := repository.NewVocabularyRepository(db).ReplaceFrequencies(ctx, frequencies); err != nil {
+		stdlog.Fatalf("Failed to import frequency list: %v", err)
+	}
+	logger.WithFields(logger.Fields{"lemmas": len(frequencies)}).Info("Frequency list import completed")
+}
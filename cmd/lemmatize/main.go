@@ -0,0 +1,132 @@
+// Command lemmatize recomputes the lemmas stored in the database after the
+// nlp package changed: the lemma of every saved word and the lemmas spoken in
+// every video.
+//
+// Usage:
+//
+//	APP_ENV=prod go run ./cmd/lemmatize [-batch 100]
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	stdlog "log"
+	"os"
+	"os/signal"
+	"shadowify/internal/apperr"
+	"shadowify/internal/config"
+	"shadowify/internal/database"
+	"shadowify/internal/logger"
+	"shadowify/internal/model"
+	"shadowify/internal/nlp"
+	"shadowify/internal/pagination"
+	"shadowify/internal/repository"
+	"shadowify/internal/service"
+	"syscall"
+)
+
+func main() {
+	batch := flag.Int("batch", 100, "number of rows read per query")
+	flag.Parse()
+
+	env := os.Getenv("APP_ENV")
+	if env == "" {
+		env = "dev"
+	}
+	cfg, err := config.LoadConfig(fmt.Sprintf("configs/config.%s.yml", env))
+	if err != nil {
+		stdlog.Fatalf("Failed to load config: %v", err)
+	}
+	logger.SetDefaultLogger(logger.NewZerologAdapter(cfg.Logger))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	db, err := database.NewGromDatabase(cfg.Database)
+	if err != nil {
+		stdlog.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer database.Close(db)
+
+	wordRepository := repository.NewWordRepository(db)
+	videoRepository := repository.NewVideoRepository(db)
+	segmentRepository := repository.NewSegmentRepository(db)
+	vocabularyService := service.NewVocabularyService(repository.NewVocabularyRepository(db))
+
+	words, err := lemmatizeWords(ctx, wordRepository, *batch)
+	if err != nil {
+		stdlog.Fatalf("Failed to lemmatize words after %d words: %v", words, err)
+	}
+	videos, err := lemmatizeVideos(ctx, vocabularyService, videoRepository, segmentRepository, *batch)
+	if err != nil {
+		stdlog.Fatalf("Failed to lemmatize videos after %d videos: %v", videos, err)
+	}
+	logger.WithFields(logger.Fields{"words": words, "videos": videos}).Info("Lemmatize completed")
+}
+
+// lemmatizeWords updates the words whose stored lemma differs from the
+// current one. A word whose new lemma was already saved by the user is a
+// duplicate and is deleted.
+func lemmatizeWords(ctx context.Context, wordRepository *repository.WordRepository, batch int) (int, error) {
+	updated := 0
+	afterId := ""
+	for {
+		words, err := wordRepository.ListAfter(ctx, afterId, batch)
+		if err != nil {
+			return updated, err
+		}
+		for _, word := range words {
+			lemma := nlp.Normalize(word.MeaningEN)
+			if lemma == word.Lemma {
+				continue
+			}
+			err := wordRepository.UpdateLemma(ctx, word.Id, lemma)
+			if apperr.Is(err, apperr.KindConflict) {
+				err = wordRepository.Delete(ctx, word.Id)
+			}
+			if err != nil {
+				return updated, err
+			}
+			updated++
+		}
+		if len(words) < batch {
+			return updated, nil
+		}
+		afterId = words[len(words)-1].Id
+	}
+}
+
+// lemmatizeVideos walks the videos from newest to oldest and stores the
+// lemmas of their segments again.
+func lemmatizeVideos(ctx context.Context, vocabularyService *service.VocabularyService, videoRepository *repository.VideoRepository, segmentRepository *repository.SegmentRepository, batch int) (int, error) {
+	indexed := 0
+	includeTotal := false
+	filter := &model.VideoFilter{
+		Type:       model.VideoNewest,
+		Pagination: pagination.Pagination{PageSize: batch, IncludeTotal: &includeTotal},
+	}
+	for {
+		videos, _, err := videoRepository.List(ctx, filter)
+		if err != nil {
+			return indexed, err
+		}
+		for _, video := range videos {
+			segments, err := segmentRepository.FindByVideoID(ctx, video.Id)
+			if err != nil {
+				return indexed, err
+			}
+			if err := vocabularyService.IndexVideo(ctx, video.Id, segments); err != nil {
+				return indexed, err
+			}
+			indexed++
+		}
+		logger.WithFields(logger.Fields{"videos": indexed}).Info("Lemmatizing")
+
+		if !filter.HasMore {
+			return indexed, nil
+		}
+		filter.Cursor = filter.NextCursor
+		filter.NextCursor = ""
+	}
+}
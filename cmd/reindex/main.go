@@ -0,0 +1,118 @@
+// Command reindex copies the videos and their segments from the database to
+// the search index selected by the config, for example after switching to
+// Elasticsearch or when the index missed updates.
+//
+// Usage:
+//
+//	APP_ENV=prod go run ./cmd/reindex [-recreate] [-batch 100]
+//
+// Without -recreate the indexes are updated in place, so videos deleted from
+// the database stay in the index.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	stdlog "log"
+	"os"
+	"os/signal"
+	"shadowify/internal/config"
+	"shadowify/internal/database"
+	"shadowify/internal/logger"
+	"shadowify/internal/model"
+	"shadowify/internal/pagination"
+	"shadowify/internal/repository"
+	"shadowify/internal/search"
+	"syscall"
+)
+
+func main() {
+	recreate := flag.Bool("recreate", false, "delete and recreate the indexes before copying")
+	batch := flag.Int("batch", 100, "number of videos read per query")
+	flag.Parse()
+
+	env := os.Getenv("APP_ENV")
+	if env == "" {
+		env = "dev"
+	}
+	cfg, err := config.LoadConfig(fmt.Sprintf("configs/config.%s.yml", env))
+	if err != nil {
+		stdlog.Fatalf("Failed to load config: %v", err)
+	}
+	logger.SetDefaultLogger(logger.NewZerologAdapter(cfg.Logger))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	db, err := database.NewGromDatabase(cfg.Database)
+	if err != nil {
+		stdlog.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer database.Close(db)
+
+	videoRepository := repository.NewVideoRepository(db)
+	segmentRepository := repository.NewSegmentRepository(db)
+	index, err := search.New(cfg.Search, videoRepository, segmentRepository)
+	if err != nil {
+		stdlog.Fatalf("Failed to create search index: %v", err)
+	}
+	if _, ok := index.(*search.PostgresIndex); ok {
+		logger.Info("The postgres search backend reads the tables directly, nothing to reindex")
+		return
+	}
+	if es, ok := index.(*search.ElasticsearchIndex); ok {
+		if err := es.EnsureIndexes(ctx, *recreate); err != nil {
+			stdlog.Fatalf("Failed to create indexes: %v", err)
+		}
+	}
+
+	indexed, failed, err := reindex(ctx, index, videoRepository, segmentRepository, *batch)
+	if err != nil {
+		stdlog.Fatalf("Failed to reindex after %d videos: %v", indexed, err)
+	}
+	logger.WithFields(logger.Fields{"indexed": indexed, "failed": failed}).Info("Reindex completed")
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// reindex walks the videos from newest to oldest. A video that fails to index
+// is logged and skipped, a failure to read the database stops the walk.
+func reindex(ctx context.Context, index search.Index, videoRepository *repository.VideoRepository, segmentRepository *repository.SegmentRepository, batch int) (int, int, error) {
+	indexed, failed := 0, 0
+	includeTotal := false
+	filter := &model.VideoFilter{
+		Type:       model.VideoNewest,
+		Pagination: pagination.Pagination{PageSize: batch, IncludeTotal: &includeTotal},
+	}
+	for {
+		videos, _, err := videoRepository.List(ctx, filter)
+		if err != nil {
+			return indexed, failed, err
+		}
+		for _, video := range videos {
+			segments, err := segmentRepository.FindByVideoID(ctx, video.Id)
+			if err != nil {
+				return indexed, failed, err
+			}
+			err = index.IndexVideo(ctx, video)
+			if err == nil {
+				err = index.IndexSegments(ctx, video, segments)
+			}
+			if err != nil {
+				logger.WithFields(logger.Fields{"video_id": video.Id, "error": err.Error()}).Error("Failed to index video")
+				failed++
+				continue
+			}
+			indexed++
+		}
+		logger.WithFields(logger.Fields{"indexed": indexed}).Info("Reindexing")
+
+		if !filter.HasMore {
+			return indexed, failed, nil
+		}
+		filter.Cursor = filter.NextCursor
+		filter.NextCursor = ""
+	}
+}
@@ -2,22 +2,39 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	stdlog "log"
+	"net/http"
 	"os"
 	"os/signal"
 	"shadowify/internal/config"
 	"shadowify/internal/database"
 	"shadowify/internal/handler"
+	"shadowify/internal/lifecycle"
 	"shadowify/internal/logger"
+	"shadowify/internal/metrics"
 	"shadowify/internal/middleware"
+	"shadowify/internal/procpool"
+	"shadowify/internal/ratelimit"
 	"shadowify/internal/repository"
+	"shadowify/internal/runner"
+	"shadowify/internal/search"
 	"shadowify/internal/service"
+	"shadowify/internal/tracing"
+	"shadowify/internal/validation"
+	"shadowify/internal/views"
+	"shadowify/internal/workspace"
+	"syscall"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	_echomiddleware "github.com/labstack/echo/v4/middleware"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/labstack/echo/otelecho"
 )
 
+const defaultShutdownTimeout = 30 * time.Second
+
 func main() {
 	// Load environment variables
 	env := os.Getenv("APP_ENV")
@@ -33,64 +50,205 @@ func main() {
 
 	// Setup logger
 	logger.SetDefaultLogger(logger.NewZerologAdapter(cfg.Logger))
-	logger.Infof("App started in %s mode", env)
+	logger.WithFields(logger.Fields{"env": env}).Info("App started")
+
+	// Cancelled on SIGINT/SIGTERM to start the shutdown sequence
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	db := database.NewGromDatabase(cfg.Database)
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing)
+	if err != nil {
+		stdlog.Fatalf("Failed to setup tracing: %v", err)
+	}
+
+	db, err := database.NewGromDatabase(cfg.Database)
 	if err != nil {
 		stdlog.Fatalf("Failed to connect to database: %v", err)
 	}
+	if err := db.Use(metrics.NewGormPlugin()); err != nil {
+		stdlog.Fatalf("Failed to register database metrics: %v", err)
+	}
+	if err := db.Use(tracing.NewGormPlugin()); err != nil {
+		stdlog.Fatalf("Failed to register database tracing: %v", err)
+	}
+
+	// Background work that must finish before the process exits
+	background := lifecycle.New()
+
+	workspaceManager, err := workspace.NewManager(cfg.Workspace)
+	if err != nil {
+		stdlog.Fatalf("Failed to create workspace manager: %v", err)
+	}
+	background.Go(func(context.Context) {
+		workspaceManager.RunJanitor(ctx)
+	})
 
 	// Setup service dependencies (use nil for repository and grpc client for now)
-	whisperService := service.NewWhisperService()
-	ytDLPService := service.NewYTDLPService()
+	commandRunner := metrics.NewRunner(tracing.NewRunner(runner.New(cfg.Runner)))
+	ffmpegService := service.NewFFmpegService(commandRunner)
+	whisperPool := procpool.New(cfg.Whisper.Pool)
+	if err := metrics.RegisterPool("whisper", whisperPool); err != nil {
+		stdlog.Fatalf("Failed to register whisper pool metrics: %v", err)
+	}
+	whisperService := service.NewWhisperService(commandRunner, whisperPool, ffmpegService)
+	ytDLPService := service.NewYTDLPService(commandRunner)
 	videoRepository := repository.NewVideoRepository(db)
 	segmentRepository := repository.NewSegmentRepository(db)
 	languageRepository := repository.NewLanguageRepository(db)
 	favoriteRepository := repository.NewFavoriteRepository(db)
 	wordRepository := repository.NewWordRepository(db)
 	sentenceRepository := repository.NewSentenceRepository(db)
+	practiceRepository := repository.NewPracticeRepository(db)
+	watchProgressRepository := repository.NewWatchProgressRepository(db)
+	viewRepository := repository.NewViewRepository(db)
+	vocabularyRepository := repository.NewVocabularyRepository(db)
+	dictionaryRepository := repository.NewDictionaryRepository(db)
+
+	rateLimitStore, err := ratelimit.NewStore(cfg.RateLimit, db)
+	if err != nil {
+		stdlog.Fatalf("Failed to create rate limit store: %v", err)
+	}
+	if store, ok := rateLimitStore.(*ratelimit.PostgresStore); ok {
+		background.Go(func(context.Context) {
+			store.RunJanitor(ctx)
+		})
+	}
+	quota := ratelimit.NewQuota(cfg.RateLimit, rateLimitStore)
+
+	viewCounter := views.NewCounter(cfg.Views, viewRepository)
+	background.Go(func(context.Context) {
+		viewCounter.Run(ctx)
+	})
+
+	searchIndex, err := search.New(cfg.Search, videoRepository, segmentRepository)
+	if err != nil {
+		stdlog.Fatalf("Failed to create search index: %v", err)
+	}
 
 	// Initialize services
-	videoService := service.NewVideoService(videoRepository, segmentRepository, whisperService, ytDLPService)
-	segmentService := service.NewSegmentService(segmentRepository)
-	translatorService := service.NewTranslatorService(cfg.Azure.Translator)
-	sttService := service.NewSTTService(whisperService, translatorService)
+	cefrService := service.NewCEFRService(cfg.CEFR)
+	vocabularyService := service.NewVocabularyService(vocabularyRepository)
+	videoService := service.NewVideoService(videoRepository, segmentRepository, searchIndex, workspaceManager, whisperService, ytDLPService, cefrService, viewCounter, vocabularyService)
+	segmentService := service.NewSegmentService(segmentRepository, searchIndex)
+	translatorService := service.NewTranslatorService(cfg.Azure.Translator, quota)
+	sttService := service.NewSTTService(cfg.STT, workspaceManager, whisperService, ffmpegService, translatorService, cefrService, quota, practiceRepository)
 	favoriteService := service.NewFavoriteService(favoriteRepository)
-	wordService := service.NewWordService(wordRepository, translatorService)
+	watchProgressService := service.NewWatchProgressService(watchProgressRepository, videoRepository)
+	recommendationService := service.NewRecommendationService(videoRepository, practiceRepository, favoriteRepository)
+	dictionaryService := service.NewDictionaryService(dictionaryRepository)
+	wordService := service.NewWordService(wordRepository, translatorService, vocabularyService, dictionaryService)
 	sentenceService := service.NewSentenceService(sentenceRepository, translatorService)
+	healthService := service.NewHealthService(cfg.Health, db, whisperPool, workspaceManager, whisperService, ffmpegService, ytDLPService, cefrService, translatorService, searchIndex)
 
 	// Setup handlers
-	videoHandler := handler.NewVideoHandler(videoService)
+	videoHandler := handler.NewVideoHandler(videoService, recommendationService)
 	segmentHandler := handler.NewSegmentHandler(segmentService)
 	languageService := service.NewLanguageService(languageRepository)
 	languageHandler := handler.NewLanguageHandler(languageService)
-	sttHandler := handler.NewSTTHandler(sttService)
+	sttHandler := handler.NewSTTHandler(sttService, cfg.HTTP.AllowOrigins)
 	translatorHandler := handler.NewTranslatorHandler(translatorService)
 	favoriteHandler := handler.NewFavoriteHandler(favoriteService)
+	watchProgressHandler := handler.NewWatchProgressHandler(watchProgressService)
+	vocabularyHandler := handler.NewVocabularyHandler(vocabularyService)
+	dictionaryHandler := handler.NewDictionaryHandler(dictionaryService)
 	wordHandler := handler.NewWordHandler(wordService)
 	sentenceHandler := handler.NewSentenceHandler(sentenceService)
+	healthHandler := handler.NewHealthHandler(healthService)
 
 	deviceMiddleware := middleware.NewDevice()
+	adminMiddleware := middleware.NewAdmin(cfg.Health.AdminToken)
+	rateLimitMiddleware := middleware.NewRateLimit(ratelimit.NewLimiter(cfg.RateLimit, rateLimitStore))
 
 	e := echo.New()
-	e.Use(deviceMiddleware.Authenticate)
-	e.Use(_echomiddleware.CORS())
+	e.Validator = validation.New(cfg.STT.MaxUploadSize)
+	// First, so that a panic anywhere below becomes a 500 the other
+	// middlewares record
 	e.Use(_echomiddleware.Recover())
-	videoHandler.RegisterRoutes(e, deviceMiddleware)
+	e.Use(otelecho.Middleware(tracing.ServiceName(cfg.Tracing), otelecho.WithSkipper(func(c echo.Context) bool {
+		// Probes and scrapes would otherwise dominate the traces
+		switch c.Path() {
+		case "/healthz", "/readyz", "/metrics":
+			return true
+		}
+		return false
+	})))
+	e.Use(middleware.RequestId)
+	e.Use(middleware.Metrics)
+	e.Use(deviceMiddleware.Authenticate)
+	e.Use(middleware.Locale)
+	e.Use(_echomiddleware.CORSWithConfig(_echomiddleware.CORSConfig{
+		AllowOrigins:  cfg.HTTP.AllowOrigins,
+		ExposeHeaders: []string{echo.HeaderXRequestID, echo.HeaderRetryAfter},
+	}))
+	healthHandler.RegisterRoutes(e, adminMiddleware)
+	e.GET("/metrics", echo.WrapHandler(metrics.Handler()), adminMiddleware.Authenticate)
+	videoHandler.RegisterRoutes(e, deviceMiddleware, rateLimitMiddleware)
 	segmentHandler.RegisterRoutes(e)
 	languageHandler.RegisterRoutes(e)
-	sttHandler.RegisterRoutes(e)
-	translatorHandler.RegisterRoutes(e)
+	sttHandler.RegisterRoutes(e, rateLimitMiddleware)
+	translatorHandler.RegisterRoutes(e, rateLimitMiddleware)
 	favoriteHandler.RegisterRoutes(e, deviceMiddleware)
-	wordHandler.RegisterRoutes(e, deviceMiddleware)
-	sentenceHandler.RegisterRoutes(e, deviceMiddleware)
+	watchProgressHandler.RegisterRoutes(e, deviceMiddleware)
+	vocabularyHandler.RegisterRoutes(e, deviceMiddleware)
+	dictionaryHandler.RegisterRoutes(e)
+	wordHandler.RegisterRoutes(e, deviceMiddleware, rateLimitMiddleware)
+	sentenceHandler.RegisterRoutes(e, deviceMiddleware, rateLimitMiddleware)
 
-	e.Start(":" + cfg.HTTP.Port)
+	// Start HTTP server in the background and wait for a signal or a startup failure
+	serverErr := make(chan error, 1)
+	go func() {
+		if err := e.Start(":" + cfg.HTTP.Port); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+	}()
 
-	// Start HTTP server using the new server package
-	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
-	defer stop()
-	<-ctx.Done()
+	exitCode := 0
+	select {
+	case err := <-serverErr:
+		logger.WithFields(logger.Fields{"error": err.Error()}).Error("Failed to start server")
+		exitCode = 1
+		// The workers run until ctx is done, which no signal will do now
+		stop()
+	case <-ctx.Done():
+	}
 	logger.Info("Shutting down server...")
 
+	shutdownTimeout := cfg.HTTP.ShutdownTimeout
+	if shutdownTimeout <= 0 {
+		shutdownTimeout = defaultShutdownTimeout
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	// Stop accepting connections and drain in-flight requests
+	if err := e.Shutdown(shutdownCtx); err != nil {
+		logger.WithFields(logger.Fields{"error": err.Error()}).Error("Failed to drain HTTP server")
+		exitCode = 1
+	}
+	// Write the views counted by the drained requests
+	if err := viewCounter.Flush(shutdownCtx); err != nil {
+		logger.WithFields(logger.Fields{"error": err.Error()}).Error("Failed to flush views")
+		exitCode = 1
+	}
+	// Wait for background work such as the view counter
+	if err := background.Shutdown(shutdownCtx); err != nil {
+		logger.WithFields(logger.Fields{"error": err.Error()}).Error("Failed to wait for background work")
+		exitCode = 1
+	}
+	if err := database.Close(db); err != nil {
+		logger.WithFields(logger.Fields{"error": err.Error()}).Error("Failed to close database")
+		exitCode = 1
+	}
+	// Flush the spans of the last requests
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		logger.WithFields(logger.Fields{"error": err.Error()}).Error("Failed to flush traces")
+		exitCode = 1
+	}
+
+	logger.Info("Server stopped")
+	if exitCode != 0 {
+		stop()
+		cancel()
+		os.Exit(exitCode)
+	}
 }
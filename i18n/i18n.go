@@ -0,0 +1,185 @@
+// Package i18n holds the message catalogues and localizes the messages of
+// errors and other texts returned to clients.
+//
+// Catalogues are flat JSON files named after their locale and keyed by error
+// code or message key. Messages may reference the error params and field as
+// {name}.
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"shadowify/internal/apperr"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// DefaultLocale is used when the client has no supported preference. It is
+// also the language of the messages written in code.
+const DefaultLocale = "en"
+
+//go:embed *.json
+var files embed.FS
+
+var catalog = mustLoad()
+
+// Catalog holds the messages of every supported locale.
+type Catalog struct {
+	locales  []string
+	messages map[string]map[string]string
+	matcher  language.Matcher
+}
+
+// Load parses every catalogue in fsys. The default locale must be present.
+func Load(fsys fs.FS) (*Catalog, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Catalog{
+		locales:  []string{DefaultLocale},
+		messages: make(map[string]map[string]string),
+	}
+	for _, entry := range entries {
+		locale, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		var messages map[string]string
+		if err := json.Unmarshal(data, &messages); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", entry.Name(), err)
+		}
+		c.messages[locale] = messages
+		if locale != DefaultLocale {
+			c.locales = append(c.locales, locale)
+		}
+	}
+	if _, ok := c.messages[DefaultLocale]; !ok {
+		return nil, fmt.Errorf("missing catalogue for default locale %q", DefaultLocale)
+	}
+
+	tags := make([]language.Tag, len(c.locales))
+	for i, locale := range c.locales {
+		tags[i] = language.Make(locale)
+	}
+	c.matcher = language.NewMatcher(tags)
+	return c, nil
+}
+
+func mustLoad() *Catalog {
+	c, err := Load(files)
+	if err != nil {
+		panic(fmt.Sprintf("i18n: %v", err))
+	}
+	return c
+}
+
+// Locales returns the supported locales, the default first.
+func (c *Catalog) Locales() []string {
+	return c.locales
+}
+
+// Negotiate returns the supported locale that best matches the first usable
+// preference. Preferences use the Accept-Language syntax, so a plain locale
+// such as "vi" works as well.
+func (c *Catalog) Negotiate(preferences ...string) string {
+	for _, preference := range preferences {
+		if preference == "" {
+			continue
+		}
+		tags, _, err := language.ParseAcceptLanguage(preference)
+		if err != nil || len(tags) == 0 {
+			continue
+		}
+		_, index, confidence := c.matcher.Match(tags...)
+		if confidence != language.No {
+			return c.locales[index]
+		}
+	}
+	return DefaultLocale
+}
+
+// Translate returns the message for key in locale with params interpolated.
+func (c *Catalog) Translate(locale, key string, params map[string]any) (string, bool) {
+	message, ok := c.messages[locale][key]
+	if !ok {
+		return "", false
+	}
+	if len(params) == 0 {
+		return message, true
+	}
+	replacements := make([]string, 0, len(params)*2)
+	for name, value := range params {
+		replacements = append(replacements, "{"+name+"}", fmt.Sprint(value))
+	}
+	return strings.NewReplacer(replacements...).Replace(message), true
+}
+
+// Localize returns a copy of err with its message in locale. Codes missing
+// from the catalogue fall back to a message for the error kind, except in the
+// default locale where the message written in code is kept.
+func (c *Catalog) Localize(locale string, err *apperr.AppErr) *apperr.AppErr {
+	params := make(map[string]any, len(err.Params)+1)
+	for name, value := range err.Params {
+		params[name] = value
+	}
+	if err.Field != "" {
+		params["field"] = err.Field
+	}
+
+	message, ok := c.Translate(locale, err.Code, params)
+	if !ok && locale != DefaultLocale {
+		message, ok = c.Translate(locale, "error."+string(err.Kind), params)
+	}
+	if !ok {
+		return err
+	}
+	localized := *err
+	localized.Message = message
+	return &localized
+}
+
+// Locales returns the locales of the embedded catalogues.
+func Locales() []string {
+	return catalog.Locales()
+}
+
+// Negotiate picks a locale from the embedded catalogues.
+func Negotiate(preferences ...string) string {
+	return catalog.Negotiate(preferences...)
+}
+
+// Translate looks up key in the embedded catalogues.
+func Translate(locale, key string, params map[string]any) (string, bool) {
+	return catalog.Translate(locale, key, params)
+}
+
+// Localize translates err using the locale stored in ctx.
+func Localize(ctx context.Context, err *apperr.AppErr) *apperr.AppErr {
+	return catalog.Localize(FromContext(ctx), err)
+}
+
+type localeContextKey struct{}
+
+// NewContext returns a copy of ctx carrying locale.
+func NewContext(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeContextKey{}, locale)
+}
+
+// FromContext returns the locale negotiated for the request, or the default
+// locale.
+func FromContext(ctx context.Context) string {
+	if locale, ok := ctx.Value(localeContextKey{}).(string); ok {
+		return locale
+	}
+	return DefaultLocale
+}
@@ -0,0 +1,112 @@
+package i18n
+
+import (
+	"context"
+	"shadowify/internal/apperr"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCataloguesHaveSameKeys(t *testing.T) {
+	defaults := catalog.messages[DefaultLocale]
+	for _, locale := range catalog.Locales() {
+		messages := catalog.messages[locale]
+		for key := range defaults {
+			assert.Contains(t, messages, key, "%s is missing from %s", key, locale)
+		}
+		for key := range messages {
+			assert.Contains(t, defaults, key, "%s is only in %s", key, locale)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	_, err := Load(fstest.MapFS{"vi.json": {Data: []byte(`{}`)}})
+	assert.Error(t, err)
+
+	_, err = Load(fstest.MapFS{"en.json": {Data: []byte(`{`)}})
+	assert.Error(t, err)
+
+	c, err := Load(fstest.MapFS{"en.json": {Data: []byte(`{}`)}, "README.md": {}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{DefaultLocale}, c.Locales())
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		name        string
+		preferences []string
+		want        string
+	}{
+		{name: "no preference", want: DefaultLocale},
+		{name: "accept language", preferences: []string{"", "vi-VN,vi;q=0.9,en;q=0.8"}, want: "vi"},
+		{name: "quality order", preferences: []string{"", "en;q=0.5,vi;q=0.9"}, want: "vi"},
+		{name: "user preference wins", preferences: []string{"en", "vi"}, want: "en"},
+		{name: "unsupported preference falls through", preferences: []string{"fr", "vi"}, want: "vi"},
+		{name: "unsupported", preferences: []string{"fr-FR,de"}, want: DefaultLocale},
+		{name: "malformed", preferences: []string{";;;"}, want: DefaultLocale},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Negotiate(tt.preferences...))
+		})
+	}
+}
+
+func TestLocalize(t *testing.T) {
+	vi := NewContext(context.Background(), "vi")
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		err  *apperr.AppErr
+		want string
+	}{
+		{
+			name: "default locale",
+			ctx:  context.Background(),
+			err:  apperr.NotFound("video.not_found", "Video not found"),
+			want: "Video not found",
+		},
+		{
+			name: "translated",
+			ctx:  vi,
+			err:  apperr.NotFound("video.not_found", "Video not found"),
+			want: "Không tìm thấy video",
+		},
+		{
+			name: "field and params",
+			ctx:  vi,
+			err:  apperr.Validation("validation.max", "name must be at most 100").WithField("name").WithParam("param", "100"),
+			want: "name chỉ được tối đa là 100",
+		},
+		{
+			name: "kind fallback",
+			ctx:  vi,
+			err:  apperr.Internal("video.list.error", "Failed to count videos"),
+			want: "Đã xảy ra lỗi không mong muốn",
+		},
+		{
+			name: "code message kept in default locale",
+			ctx:  context.Background(),
+			err:  apperr.Internal("video.list.error", "Failed to count videos"),
+			want: "Failed to count videos",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			localized := Localize(tt.ctx, tt.err)
+			assert.Equal(t, tt.want, localized.Message)
+			assert.Equal(t, tt.err.Code, localized.Code)
+		})
+	}
+
+	err := apperr.NotFound("video.not_found", "Video not found")
+	Localize(vi, err)
+	assert.Equal(t, "Video not found", err.Message, "the original error is left untouched")
+}
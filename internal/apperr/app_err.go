@@ -1,8 +1,12 @@
 package apperr
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
 
 type AppErr struct {
+	Kind    Kind           `json:"kind"`
 	Code    string         `json:"code"`
 	Message string         `json:"message,omitempty"`
 	Field   string         `json:"field,omitempty"`
@@ -10,8 +14,11 @@ type AppErr struct {
 	cause   error          `json:"-"`
 }
 
+// NewAppErr creates an error of KindInternal. Use the constructors in kind.go
+// or WithKind for errors caused by the client or an unavailable dependency.
 func NewAppErr(code string, message ...string) *AppErr {
 	e := &AppErr{
+		Kind: KindInternal,
 		Code: code,
 	}
 	if len(message) > 0 {
@@ -20,6 +27,11 @@ func NewAppErr(code string, message ...string) *AppErr {
 	return e
 }
 
+func (e *AppErr) WithKind(kind Kind) *AppErr {
+	e.Kind = kind
+	return e
+}
+
 func (e *AppErr) WithParam(key string, value any) *AppErr {
 	if e.Params == nil {
 		e.Params = make(map[string]any)
@@ -45,13 +57,26 @@ func (e *AppErr) WithField(field string) *AppErr {
 
 func (e *AppErr) Error() string {
 	if e.cause != nil {
-		return fmt.Sprintf("code: %s, message: %s, field: %s, params: %v, cause: %v",
-			e.Code, e.Message, e.Field, e.Params, e.cause)
+		return fmt.Sprintf("kind: %s, code: %s, message: %s, field: %s, params: %v, cause: %v",
+			e.Kind, e.Code, e.Message, e.Field, e.Params, e.cause)
 	}
-	return fmt.Sprintf("code: %s, message: %s, field: %s, params: %v",
-		e.Code, e.Message, e.Field, e.Params)
+	return fmt.Sprintf("kind: %s, code: %s, message: %s, field: %s, params: %v",
+		e.Kind, e.Code, e.Message, e.Field, e.Params)
 }
 
 func (e *AppErr) Unwrap() error {
 	return e.cause
 }
+
+// From returns the outermost AppErr in the chain of err. Errors that carry no
+// AppErr are reported as internal errors wrapping err.
+func From(err error) *AppErr {
+	if err == nil {
+		return nil
+	}
+	var appErr *AppErr
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	return Internal("internal_error", "An unexpected error occurred").WithCause(err)
+}
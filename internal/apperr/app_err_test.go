@@ -0,0 +1,44 @@
+package apperr
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKindOf(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want Kind
+	}{
+		{name: "plain error", err: errors.New("boom"), want: KindInternal},
+		{name: "default kind", err: NewAppErr("video.create.error"), want: KindInternal},
+		{name: "not found", err: NotFound("video.not_found"), want: KindNotFound},
+		{name: "wrapped with fmt", err: fmt.Errorf("failed: %w", Conflict("word.already_exists")), want: KindConflict},
+		{name: "outermost wins", err: Validation("stt.audio.empty").WithCause(Unavailable("procpool.queue_timeout")), want: KindValidation},
+		{name: "wrap keeps kind", err: Wrap(Unavailable("procpool.queue_timeout"), "stt.transcribe.error"), want: KindUnavailable},
+		{name: "wrap plain error", err: Wrap(errors.New("boom"), "stt.transcribe.error"), want: KindInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, KindOf(tt.err))
+		})
+	}
+}
+
+func TestFrom(t *testing.T) {
+	assert.Nil(t, From(nil))
+
+	notFound := NotFound("video.not_found", "Video not found")
+	assert.Same(t, notFound, From(fmt.Errorf("get video: %w", notFound)))
+
+	cause := errors.New("boom")
+	appErr := From(cause)
+	assert.Equal(t, "internal_error", appErr.Code)
+	assert.Equal(t, KindInternal, appErr.Kind)
+	assert.ErrorIs(t, appErr, cause)
+}
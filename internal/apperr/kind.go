@@ -0,0 +1,85 @@
+package apperr
+
+import "errors"
+
+// Kind classifies an error independently of its code. Clients can rely on
+// the kind while codes stay specific to the failing operation.
+type Kind string
+
+const (
+	KindBadRequest   Kind = "bad_request"
+	KindValidation   Kind = "validation"
+	KindUnauthorized Kind = "unauthorized"
+	KindForbidden    Kind = "forbidden"
+	KindNotFound     Kind = "not_found"
+	KindConflict     Kind = "conflict"
+	KindTooMany      Kind = "too_many_requests"
+	KindUnavailable  Kind = "unavailable"
+	KindInternal     Kind = "internal"
+)
+
+// BadRequest reports a request that could not be parsed.
+func BadRequest(code string, message ...string) *AppErr {
+	return NewAppErr(code, message...).WithKind(KindBadRequest)
+}
+
+// Validation reports a well-formed request with invalid values.
+func Validation(code string, message ...string) *AppErr {
+	return NewAppErr(code, message...).WithKind(KindValidation)
+}
+
+func Unauthorized(code string, message ...string) *AppErr {
+	return NewAppErr(code, message...).WithKind(KindUnauthorized)
+}
+
+func Forbidden(code string, message ...string) *AppErr {
+	return NewAppErr(code, message...).WithKind(KindForbidden)
+}
+
+func NotFound(code string, message ...string) *AppErr {
+	return NewAppErr(code, message...).WithKind(KindNotFound)
+}
+
+// Conflict reports a request that clashes with the current state, such as
+// creating a resource that already exists.
+func Conflict(code string, message ...string) *AppErr {
+	return NewAppErr(code, message...).WithKind(KindConflict)
+}
+
+// TooMany reports a client that exceeded a rate limit or quota. Set the
+// "retry_after" param to the number of seconds the client should wait.
+func TooMany(code string, message ...string) *AppErr {
+	return NewAppErr(code, message...).WithKind(KindTooMany)
+}
+
+// Unavailable reports a dependency that is down or overloaded. The request
+// may succeed when retried later.
+func Unavailable(code string, message ...string) *AppErr {
+	return NewAppErr(code, message...).WithKind(KindUnavailable)
+}
+
+func Internal(code string, message ...string) *AppErr {
+	return NewAppErr(code, message...).WithKind(KindInternal)
+}
+
+// KindOf returns the kind of the outermost AppErr in the chain of err, or
+// KindInternal when there is none.
+func KindOf(err error) Kind {
+	var appErr *AppErr
+	if errors.As(err, &appErr) && appErr.Kind != "" {
+		return appErr.Kind
+	}
+	return KindInternal
+}
+
+// Is reports whether err carries an AppErr of the given kind.
+func Is(err error, kind Kind) bool {
+	return KindOf(err) == kind
+}
+
+// Wrap returns an error with code and message caused by err. The kind of an
+// AppErr carried by err is kept, so that an unavailable dependency is still
+// reported as unavailable. Otherwise the error is internal.
+func Wrap(err error, code string, message ...string) *AppErr {
+	return NewAppErr(code, message...).WithKind(KindOf(err)).WithCause(err)
+}
@@ -0,0 +1,40 @@
+package apperr
+
+import (
+	"errors"
+	"strings"
+)
+
+// List reports several errors at once, such as every invalid field of a
+// request. It unwraps to its elements so that From and errors.As find the
+// first one.
+type List []*AppErr
+
+func (l List) Error() string {
+	messages := make([]string, len(l))
+	for i, err := range l {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (l List) Unwrap() []error {
+	errs := make([]error, len(l))
+	for i, err := range l {
+		errs[i] = err
+	}
+	return errs
+}
+
+// ListFrom returns every AppErr reported by err. A List is returned as is,
+// anything else is reported as a single error using From.
+func ListFrom(err error) List {
+	if err == nil {
+		return nil
+	}
+	var list List
+	if errors.As(err, &list) && len(list) > 0 {
+		return list
+	}
+	return List{From(err)}
+}
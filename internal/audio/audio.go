@@ -0,0 +1,196 @@
+// Package audio provides helpers to validate and persist uploaded audio.
+package audio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"mime"
+	"os"
+	"strings"
+	"time"
+)
+
+type Format string
+
+const (
+	FormatM4A  Format = "m4a"
+	FormatWAV  Format = "wav"
+	FormatWEBM Format = "webm"
+	FormatOGG  Format = "ogg"
+	FormatMP3  Format = "mp3"
+)
+
+// SniffLen is the number of leading bytes needed to detect a format.
+const SniffLen = 12
+
+var (
+	ErrEmpty             = errors.New("audio is empty")
+	ErrTooLarge          = errors.New("audio exceeds the maximum size")
+	ErrUnsupportedFormat = errors.New("audio format is not supported")
+)
+
+var contentTypeFormats = map[string]Format{
+	"audio/mp4":    FormatM4A,
+	"audio/m4a":    FormatM4A,
+	"audio/x-m4a":  FormatM4A,
+	"audio/aac":    FormatM4A,
+	"audio/wav":    FormatWAV,
+	"audio/wave":   FormatWAV,
+	"audio/x-wav":  FormatWAV,
+	"audio/webm":   FormatWEBM,
+	"audio/ogg":    FormatOGG,
+	"audio/opus":   FormatOGG,
+	"audio/mpeg":   FormatMP3,
+	"audio/mp3":    FormatMP3,
+	"audio/x-mpeg": FormatMP3,
+}
+
+// Ext returns the file extension for the format including the leading dot.
+func (f Format) Ext() string {
+	return "." + string(f)
+}
+
+// FormatFromContentType maps a declared audio content type to a format.
+func FormatFromContentType(contentType string) (Format, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", false
+	}
+	format, ok := contentTypeFormats[strings.ToLower(mediaType)]
+	return format, ok
+}
+
+// Sniff detects the audio format from the leading bytes of a file.
+func Sniff(header []byte) (Format, bool) {
+	switch {
+	case len(header) >= 12 && bytes.Equal(header[0:4], []byte("RIFF")) && bytes.Equal(header[8:12], []byte("WAVE")):
+		return FormatWAV, true
+	case len(header) >= 8 && bytes.Equal(header[4:8], []byte("ftyp")):
+		return FormatM4A, true
+	case len(header) >= 4 && bytes.Equal(header[0:4], []byte{0x1A, 0x45, 0xDF, 0xA3}):
+		return FormatWEBM, true
+	case len(header) >= 4 && bytes.Equal(header[0:4], []byte("OggS")):
+		return FormatOGG, true
+	case len(header) >= 3 && bytes.Equal(header[0:3], []byte("ID3")):
+		return FormatMP3, true
+	case len(header) >= 2 && header[0] == 0xFF && header[1]&0xE0 == 0xE0:
+		// MPEG audio frame sync
+		return FormatMP3, true
+	}
+	return "", false
+}
+
+// Save streams r into basePath plus the extension of the detected format,
+// rejecting content that is empty, larger than maxSize bytes or not one of
+// the supported formats. The file is removed when Save fails.
+func Save(r io.Reader, basePath string, maxSize int64) (string, Format, error) {
+	br := bufio.NewReader(r)
+	header, err := br.Peek(SniffLen)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", "", err
+	}
+	if len(header) == 0 {
+		return "", "", ErrEmpty
+	}
+	format, ok := Sniff(header)
+	if !ok {
+		return "", "", ErrUnsupportedFormat
+	}
+
+	path := basePath + format.Ext()
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return "", "", err
+	}
+
+	n, err := io.Copy(f, io.LimitReader(br, maxSize+1))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && n > maxSize {
+		err = ErrTooLarge
+	}
+	if err != nil {
+		os.Remove(path)
+		return "", "", err
+	}
+
+	return path, format, nil
+}
+
+// WriteWav writes mono 16-bit little-endian PCM samples as a WAV file.
+func WriteWav(path string, pcm []byte, sampleRate int) error {
+	const (
+		channels      = 1
+		bitsPerSample = 16
+	)
+	blockAlign := channels * bitsPerSample / 8
+
+	header := make([]byte, 44)
+	copy(header[0:4], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:8], uint32(36+len(pcm)))
+	copy(header[8:12], "WAVE")
+	copy(header[12:16], "fmt ")
+	binary.LittleEndian.PutUint32(header[16:20], 16)
+	binary.LittleEndian.PutUint16(header[20:22], 1) // PCM
+	binary.LittleEndian.PutUint16(header[22:24], channels)
+	binary.LittleEndian.PutUint32(header[24:28], uint32(sampleRate))
+	binary.LittleEndian.PutUint32(header[28:32], uint32(sampleRate*blockAlign))
+	binary.LittleEndian.PutUint16(header[32:34], uint16(blockAlign))
+	binary.LittleEndian.PutUint16(header[34:36], bitsPerSample)
+	copy(header[36:40], "data")
+	binary.LittleEndian.PutUint32(header[40:44], uint32(len(pcm)))
+
+	return os.WriteFile(path, append(header, pcm...), 0644)
+}
+
+// WavDuration returns the length of the audio in a PCM WAV file. It walks the
+// RIFF chunks since tools such as ffmpeg add metadata before the samples.
+func WavDuration(path string) (time.Duration, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	header := make([]byte, 12)
+	if _, err := io.ReadFull(f, header); err != nil {
+		return 0, err
+	}
+	if string(header[0:4]) != "RIFF" || string(header[8:12]) != "WAVE" {
+		return 0, ErrUnsupportedFormat
+	}
+
+	var byteRate uint32
+	chunk := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(f, chunk); err != nil {
+			return 0, err
+		}
+		id, size := string(chunk[0:4]), binary.LittleEndian.Uint32(chunk[4:8])
+		switch id {
+		case "fmt ":
+			format := make([]byte, size)
+			if _, err := io.ReadFull(f, format); err != nil {
+				return 0, err
+			}
+			if size < 12 {
+				return 0, ErrUnsupportedFormat
+			}
+			byteRate = binary.LittleEndian.Uint32(format[8:12])
+		case "data":
+			if byteRate == 0 {
+				return 0, ErrUnsupportedFormat
+			}
+			return time.Duration(float64(size) / float64(byteRate) * float64(time.Second)), nil
+		default:
+			// Chunks are padded to an even size
+			if _, err := f.Seek(int64(size+size&1), io.SeekCurrent); err != nil {
+				return 0, err
+			}
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package audio
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSniff(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   []byte
+		expected Format
+		ok       bool
+	}{
+		{name: "wav", header: []byte("RIFF\x24\x08\x00\x00WAVEfmt "), expected: FormatWAV, ok: true},
+		{name: "m4a", header: []byte("\x00\x00\x00\x20ftypM4A "), expected: FormatM4A, ok: true},
+		{name: "webm", header: []byte{0x1A, 0x45, 0xDF, 0xA3, 0x9F, 0x42}, expected: FormatWEBM, ok: true},
+		{name: "ogg", header: []byte("OggS\x00\x02"), expected: FormatOGG, ok: true},
+		{name: "mp3 id3", header: []byte("ID3\x04\x00"), expected: FormatMP3, ok: true},
+		{name: "mp3 frame", header: []byte{0xFF, 0xFB, 0x90, 0x64}, expected: FormatMP3, ok: true},
+		{name: "text", header: []byte("hello world!"), ok: false},
+		{name: "empty", header: nil, ok: false},
+	}
+
+	for _, test := range tests {
+		format, ok := Sniff(test.header)
+		assert.Equal(t, test.ok, ok, test.name)
+		assert.Equal(t, test.expected, format, test.name)
+	}
+}
+
+func TestFormatFromContentType(t *testing.T) {
+	format, ok := FormatFromContentType("audio/x-m4a")
+	assert.True(t, ok)
+	assert.Equal(t, FormatM4A, format)
+
+	format, ok = FormatFromContentType("audio/webm;codecs=opus")
+	assert.True(t, ok)
+	assert.Equal(t, FormatWEBM, format)
+
+	_, ok = FormatFromContentType("video/mp4")
+	assert.False(t, ok)
+}
+
+func TestSave(t *testing.T) {
+	dir := t.TempDir()
+	wav := append([]byte("RIFF\x24\x08\x00\x00WAVEfmt "), make([]byte, 100)...)
+
+	path, format, err := Save(bytes.NewReader(wav), filepath.Join(dir, "ok"), 1024)
+	assert.NoError(t, err)
+	assert.Equal(t, FormatWAV, format)
+	assert.Equal(t, filepath.Join(dir, "ok.wav"), path)
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, wav, data)
+
+	_, _, err = Save(bytes.NewReader(wav), filepath.Join(dir, "large"), 64)
+	assert.ErrorIs(t, err, ErrTooLarge)
+	assert.NoFileExists(t, filepath.Join(dir, "large.wav"))
+
+	_, _, err = Save(strings.NewReader(""), filepath.Join(dir, "empty"), 64)
+	assert.ErrorIs(t, err, ErrEmpty)
+
+	_, _, err = Save(strings.NewReader("not an audio file"), filepath.Join(dir, "text"), 64)
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+}
+
+func TestWriteWav(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.wav")
+	pcm := make([]byte, 3200)
+
+	assert.NoError(t, WriteWav(path, pcm, 16000))
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Len(t, data, 44+len(pcm))
+	format, ok := Sniff(data)
+	assert.True(t, ok)
+	assert.Equal(t, FormatWAV, format)
+}
+
+func TestWavDuration(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.wav")
+	assert.NoError(t, WriteWav(path, make([]byte, 48000), 16000))
+
+	duration, err := WavDuration(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 1500*time.Millisecond, duration)
+
+	// A LIST chunk before the samples as written by ffmpeg
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	list := append([]byte("LIST\x05\x00\x00\x00INFOx\x00"), data[36:]...)
+	assert.NoError(t, os.WriteFile(path, append(data[:36:36], list...), 0644))
+
+	duration, err = WavDuration(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 1500*time.Millisecond, duration)
+
+	assert.NoError(t, os.WriteFile(path, []byte("not a wav file"), 0644))
+	_, err = WavDuration(path)
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+}
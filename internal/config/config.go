@@ -1,15 +1,29 @@
 package config
 
-import "github.com/spf13/viper"
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
 
 type Config struct {
-	App      AppConfig      `mapstructure:"app"`
-	HTTP     HTTPConfig     `mapstructure:"http"`
-	Database DatabaseConfig `mapstructure:"database"`
-	Logger   LoggerConfig   `mapstructure:"logger"`
-	Youtube  YoutubeConfig  `mapstructure:"youtube"`
-	Azure    AzureConfig    `mapstructure:"azure"`
-	Keycloak KeycloakConfig `mapstructure:"keycloak"`
+	App       AppConfig       `mapstructure:"app"`
+	HTTP      HTTPConfig      `mapstructure:"http"`
+	Database  DatabaseConfig  `mapstructure:"database"`
+	Logger    LoggerConfig    `mapstructure:"logger"`
+	Youtube   YoutubeConfig   `mapstructure:"youtube"`
+	Azure     AzureConfig     `mapstructure:"azure"`
+	Keycloak  KeycloakConfig  `mapstructure:"keycloak"`
+	STT       STTConfig       `mapstructure:"stt"`
+	Workspace WorkspaceConfig `mapstructure:"workspace"`
+	Whisper   WhisperConfig   `mapstructure:"whisper"`
+	Runner    RunnerConfig    `mapstructure:"runner"`
+	CEFR      CEFRConfig      `mapstructure:"cefr"`
+	Health    HealthConfig    `mapstructure:"health"`
+	Tracing   TracingConfig   `mapstructure:"tracing"`
+	RateLimit RateLimitConfig `mapstructure:"rate_limit"`
+	Search    SearchConfig    `mapstructure:"search"`
+	Views     ViewsConfig     `mapstructure:"views"`
 }
 
 type AppConfig struct {
@@ -27,6 +41,13 @@ type AzureTranslatorConfig struct {
 
 type HTTPConfig struct {
 	Port string `mapstructure:"port"`
+	// ShutdownTimeout bounds how long in-flight requests and background
+	// work are drained on shutdown.
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
+	// AllowOrigins are the browser origins allowed to call the API and open
+	// WebSockets. When empty CORS allows any origin and WebSockets only the
+	// API's own.
+	AllowOrigins []string `mapstructure:"allow_origins"`
 }
 
 type KeycloakConfig struct {
@@ -66,6 +87,81 @@ type LoggerConsoleConfig struct {
 	Level   string `mapstructure:"level"`
 	Enabled bool   `mapstructure:"enabled"`
 }
+type STTConfig struct {
+	// MaxUploadSize is the maximum size of an uploaded recording in bytes.
+	MaxUploadSize int64 `mapstructure:"max_upload_size"`
+	// Stream configures real-time transcription over WebSocket.
+	Stream STTStreamConfig `mapstructure:"stream"`
+}
+
+type STTStreamConfig struct {
+	// Step is how much new audio triggers a partial hypothesis.
+	Step time.Duration `mapstructure:"step"`
+	// Window is the length of audio transcribed before it is finalized.
+	Window time.Duration `mapstructure:"window"`
+	// MaxDuration caps the total length of a streaming session.
+	MaxDuration time.Duration `mapstructure:"max_duration"`
+}
+
+type WorkspaceConfig struct {
+	// Root is the directory under which per-job workspaces are created.
+	Root string `mapstructure:"root"`
+	// Quota is the maximum number of bytes stored under Root.
+	Quota int64 `mapstructure:"quota"`
+	// OrphanMaxAge is how long an unowned entry is kept before the janitor removes it.
+	OrphanMaxAge time.Duration `mapstructure:"orphan_max_age"`
+	// JanitorInterval is how often the janitor runs.
+	JanitorInterval time.Duration `mapstructure:"janitor_interval"`
+}
+
+type WhisperConfig struct {
+	Pool ProcPoolConfig `mapstructure:"pool"`
+}
+
+type ProcPoolConfig struct {
+	// MaxConcurrent is the maximum number of processes running at once.
+	MaxConcurrent int `mapstructure:"max_concurrent"`
+	// Threads is the number of threads given to each process.
+	Threads int `mapstructure:"threads"`
+	// QueueTimeout is how long an interactive job waits for a free slot.
+	QueueTimeout time.Duration `mapstructure:"queue_timeout"`
+	// BatchQueueTimeout is how long a batch job waits for a free slot.
+	BatchQueueTimeout time.Duration `mapstructure:"batch_queue_timeout"`
+}
+
+type RunnerConfig struct {
+	// DefaultTimeout applies to tools without an entry in Timeouts.
+	DefaultTimeout time.Duration `mapstructure:"default_timeout"`
+	// Timeouts maps a tool name such as "ffmpeg" to its deadline.
+	Timeouts map[string]time.Duration `mapstructure:"timeouts"`
+}
+
+type CEFRConfig struct {
+	// URL is the base URL of the CEFR classifier.
+	URL string `mapstructure:"url"`
+}
+
+type HealthConfig struct {
+	// Timeout bounds each dependency check.
+	Timeout time.Duration `mapstructure:"timeout"`
+	// CacheTTL is how long the readiness report is reused.
+	CacheTTL time.Duration `mapstructure:"cache_ttl"`
+	// AdminToken protects the diagnostics endpoint. It is disabled when empty.
+	AdminToken string `mapstructure:"admin_token"`
+}
+
+type TracingConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Exporter is either "otlp" or "stdout".
+	Exporter string `mapstructure:"exporter"`
+	// Endpoint is the OTLP/HTTP collector URL, e.g. http://localhost:4318.
+	// The standard OTEL_EXPORTER_OTLP_* variables apply when empty.
+	Endpoint    string `mapstructure:"endpoint"`
+	ServiceName string `mapstructure:"service_name"`
+	// SampleRatio is the fraction of new traces that are recorded.
+	SampleRatio float64 `mapstructure:"sample_ratio"`
+}
+
 type YoutubeConfig struct {
 	APIKey string `mapstructure:"api_key"`
 }
@@ -87,3 +183,67 @@ func LoadConfig(path string) (*Config, error) {
 
 	return cfg, nil
 }
+
+type RateLimitConfig struct {
+	// Enabled turns on rate limiting and quotas for expensive endpoints.
+	Enabled bool `mapstructure:"enabled"`
+	// Store is "memory" for a single instance or "postgres" to share limits
+	// between instances.
+	Store string `mapstructure:"store"`
+	// Policies maps a policy name such as "stt" to its token bucket. Missing
+	// policies use built-in defaults.
+	Policies map[string]RateLimitPolicy `mapstructure:"policies"`
+	// Quotas are daily limits per user.
+	Quotas QuotaConfig `mapstructure:"quotas"`
+}
+
+type RateLimitPolicy struct {
+	// RequestsPerMinute is the rate at which the bucket refills.
+	RequestsPerMinute float64 `mapstructure:"requests_per_minute"`
+	// Burst is the capacity of the bucket.
+	Burst int `mapstructure:"burst"`
+}
+
+type QuotaConfig struct {
+	// TranslationChars is the number of characters a user may translate per
+	// day. Zero disables the quota.
+	TranslationChars int64 `mapstructure:"translation_chars"`
+	// TranscriptionSeconds is the length of audio a user may transcribe per
+	// day. Zero disables the quota.
+	TranscriptionSeconds int64 `mapstructure:"transcription_seconds"`
+}
+
+type SearchConfig struct {
+	// Backend is "postgres", "elasticsearch" or "memory". Postgres, the
+	// default, searches the tables directly and needs no syncing.
+	Backend       string              `mapstructure:"backend"`
+	Elasticsearch ElasticsearchConfig `mapstructure:"elasticsearch"`
+}
+
+type ElasticsearchConfig struct {
+	// URL is the base URL of the Elasticsearch or OpenSearch cluster, e.g.
+	// http://localhost:9200.
+	URL      string `mapstructure:"url"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	// IndexPrefix is prepended to the index names so that environments can
+	// share a cluster.
+	IndexPrefix string `mapstructure:"index_prefix"`
+	// Timeout bounds each request to the cluster.
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+type ViewsConfig struct {
+	// DedupWindow is the period in which repeated views of a video from the
+	// same device, or from the same address when no device id is sent,
+	// count once.
+	DedupWindow time.Duration `mapstructure:"dedup_window"`
+	// MaxDevicesPerIP is the number of distinct devices whose views of a
+	// video are counted per address within DedupWindow.
+	MaxDevicesPerIP int `mapstructure:"max_devices_per_ip"`
+	// FlushInterval is how often buffered views are written.
+	FlushInterval time.Duration `mapstructure:"flush_interval"`
+	// BatchSize is the number of views written at once. A full batch is
+	// written before FlushInterval has passed.
+	BatchSize int `mapstructure:"batch_size"`
+}
@@ -0,0 +1,249 @@
+// Package dictionary reads the datasets the dictionary is imported from: the
+// JSON lines dumps of Wiktionary extracted by wiktextract, as published on
+// kaikki.org, and CSV word lists with the CEFR level of each word, such as
+// the English Vocabulary Profile or the CEFR-J wordlist.
+package dictionary
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"shadowify/internal/database"
+	"shadowify/internal/model"
+	"shadowify/internal/nlp"
+	"slices"
+	"strings"
+)
+
+const (
+	// SourceWiktionary is the source of the entries read by ReadWiktionary.
+	SourceWiktionary = "wiktionary"
+	// maxSenses and maxExamples keep the entries of very common words small.
+	maxSenses   = 8
+	maxExamples = 3
+	// maxLineSize is the longest line of a dump, some entries exceed 1 MiB.
+	maxLineSize = 16 << 20
+)
+
+// partsOfSpeech maps the parts of speech of wiktextract to those stored.
+// Others, such as affixes, symbols and proper names, are not imported.
+var partsOfSpeech = map[string]string{
+	"noun":        "noun",
+	"verb":        "verb",
+	"adj":         "adjective",
+	"adv":         "adverb",
+	"pron":        "pronoun",
+	"prep":        "preposition",
+	"conj":        "conjunction",
+	"det":         "determiner",
+	"article":     "determiner",
+	"intj":        "interjection",
+	"num":         "number",
+	"phrase":      "phrase",
+	"prep_phrase": "phrase",
+}
+
+// wiktextractEntry is the part of a wiktextract line that is imported.
+type wiktextractEntry struct {
+	Word     string `json:"word"`
+	Pos      string `json:"pos"`
+	LangCode string `json:"lang_code"`
+	Sounds   []struct {
+		Ipa string `json:"ipa"`
+	} `json:"sounds"`
+	Senses []struct {
+		Glosses  []string `json:"glosses"`
+		Tags     []string `json:"tags"`
+		Examples []struct {
+			Text string `json:"text"`
+		} `json:"examples"`
+	} `json:"senses"`
+}
+
+// ReadWiktionary calls fn with the English entries of a wiktextract dump.
+// The senses of consecutive lines with the same word and part of speech, one
+// per etymology in the dump, are merged into one entry. Senses that only
+// point to another form of a word, like "plural of child", are left out.
+func ReadWiktionary(r io.Reader, fn func(*model.DictionaryEntry) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	var pending *model.DictionaryEntry
+	for line := 1; scanner.Scan(); line++ {
+		var raw wiktextractEntry
+		if err := json.Unmarshal(scanner.Bytes(), &raw); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		entry := toEntry(&raw)
+		if entry == nil {
+			continue
+		}
+		if pending != nil && pending.Word == entry.Word && pending.PartOfSpeech == entry.PartOfSpeech {
+			merge(pending, entry)
+			continue
+		}
+		if pending != nil {
+			if err := fn(pending); err != nil {
+				return err
+			}
+		}
+		pending = entry
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if pending != nil {
+		return fn(pending)
+	}
+	return nil
+}
+
+func toEntry(raw *wiktextractEntry) *model.DictionaryEntry {
+	pos, ok := partsOfSpeech[raw.Pos]
+	if !ok || raw.LangCode != "" && raw.LangCode != "en" {
+		return nil
+	}
+	lemma := nlp.Normalize(raw.Word)
+	if lemma == "" {
+		return nil
+	}
+	var senses []*model.DictionarySense
+	for _, sense := range raw.Senses {
+		if len(sense.Glosses) == 0 || slices.Contains(sense.Tags, "form-of") {
+			continue
+		}
+		// Nested senses repeat the glosses of their parents
+		definition := sense.Glosses[len(sense.Glosses)-1]
+		var examples []string
+		for _, example := range sense.Examples {
+			if text := strings.TrimSpace(example.Text); text != "" && len(examples) < maxExamples {
+				examples = append(examples, text)
+			}
+		}
+		senses = append(senses, &model.DictionarySense{Definition: definition, Examples: examples})
+	}
+	if len(senses) == 0 {
+		return nil
+	}
+	entry := &model.DictionaryEntry{
+		Word:         raw.Word,
+		Lemma:        lemma,
+		PartOfSpeech: pos,
+		Source:       SourceWiktionary,
+	}
+	for _, sound := range raw.Sounds {
+		if sound.Ipa != "" {
+			entry.Ipa = sound.Ipa
+			break
+		}
+	}
+	entry.Senses.Data = senses[:min(len(senses), maxSenses)]
+	return entry
+}
+
+func merge(entry, other *model.DictionaryEntry) {
+	if entry.Ipa == "" {
+		entry.Ipa = other.Ipa
+	}
+	senses := append(entry.Senses.Data, other.Senses.Data...)
+	entry.Senses = database.JSONType[[]*model.DictionarySense]{Data: senses[:min(len(senses), maxSenses)]}
+}
+
+// CefrLevels maps words to the CEFR level learners meet them at.
+type CefrLevels struct {
+	byPos  map[string]string
+	byWord map[string]string
+}
+
+// ReadCefr reads a CSV word list with a header row. The words are in a
+// "headword" or "word" column and the levels in a "cefr" or "level" column.
+// An optional "pos" column gives the part of speech when a word has levels
+// for several.
+func ReadCefr(r io.Reader) (*CefrLevels, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	wordColumn, posColumn, levelColumn := -1, -1, -1
+	for i, name := range header {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "headword", "word":
+			wordColumn = i
+		case "pos", "part_of_speech":
+			posColumn = i
+		case "cefr", "level":
+			levelColumn = i
+		}
+	}
+	if wordColumn < 0 || levelColumn < 0 {
+		return nil, errors.New("the header has no word or CEFR column")
+	}
+
+	levels := &CefrLevels{byPos: make(map[string]string), byWord: make(map[string]string)}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return levels, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if wordColumn >= len(record) || levelColumn >= len(record) {
+			continue
+		}
+		level := strings.ToUpper(strings.TrimSpace(record[levelColumn]))
+		if !slices.Contains(model.CefrLevels, level) {
+			continue
+		}
+		// Some lists give alternatives, as in "color/colour"
+		for _, word := range strings.Split(record[wordColumn], "/") {
+			word = strings.ToLower(strings.TrimSpace(word))
+			if word == "" {
+				continue
+			}
+			if posColumn >= 0 && posColumn < len(record) {
+				key := word + "\x00" + normalizePos(record[posColumn])
+				levels.byPos[key] = easier(levels.byPos[key], level)
+			}
+			levels.byWord[word] = easier(levels.byWord[word], level)
+		}
+	}
+}
+
+// Level returns the level of a word as a part of speech, falling back to the
+// easiest level of the word, or an empty string when the list lacks it.
+func (l *CefrLevels) Level(word, pos string) string {
+	word = strings.ToLower(word)
+	if level, ok := l.byPos[word+"\x00"+pos]; ok {
+		return level
+	}
+	return l.byWord[word]
+}
+
+// normalizePos maps the parts of speech of word lists, such as "adj" or
+// "adjective", to those of the entries.
+func normalizePos(pos string) string {
+	pos = strings.ToLower(strings.TrimSpace(pos))
+	switch pos {
+	case "exclamation":
+		return "interjection"
+	case "modal verb", "auxiliary verb":
+		return "verb"
+	}
+	if mapped, ok := partsOfSpeech[pos]; ok {
+		return mapped
+	}
+	return pos
+}
+
+// easier returns the easier of two levels, either of which may be empty.
+func easier(a, b string) string {
+	if a == "" || b != "" && slices.Index(model.CefrLevels, b) < slices.Index(model.CefrLevels, a) {
+		return b
+	}
+	return a
+}
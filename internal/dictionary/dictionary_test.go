@@ -0,0 +1,52 @@
+package dictionary
+
+import (
+	"shadowify/internal/model"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const dump = `{"word": "run", "pos": "verb", "lang_code": "en", "sounds": [{"tags": ["UK"]}, {"ipa": "/ɹʌn/"}], "senses": [{"glosses": ["To move swiftly."], "examples": [{"text": "She ran to the shop."}]}]}
+{"word": "run", "pos": "verb", "lang_code": "en", "senses": [{"glosses": ["To manage.", "To be in charge of."]}]}
+{"word": "run", "pos": "noun", "lang_code": "en", "senses": [{"glosses": ["An act of running."]}]}
+{"word": "ran", "pos": "verb", "lang_code": "en", "senses": [{"glosses": ["simple past of run"], "tags": ["form-of"]}]}
+{"word": "-ing", "pos": "suffix", "lang_code": "en", "senses": [{"glosses": ["Forms gerunds."]}]}
+{"word": "correr", "pos": "verb", "lang_code": "es", "senses": [{"glosses": ["to run"]}]}
+`
+
+func TestReadWiktionary(t *testing.T) {
+	var entries []*model.DictionaryEntry
+	err := ReadWiktionary(strings.NewReader(dump), func(entry *model.DictionaryEntry) error {
+		entries = append(entries, entry)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	verb := entries[0]
+	assert.Equal(t, "run", verb.Lemma)
+	assert.Equal(t, "verb", verb.PartOfSpeech)
+	assert.Equal(t, "/ɹʌn/", verb.Ipa)
+	assert.Equal(t, []*model.DictionarySense{
+		{Definition: "To move swiftly.", Examples: []string{"She ran to the shop."}},
+		{Definition: "To be in charge of."},
+	}, verb.Senses.Data)
+	assert.Equal(t, "noun", entries[1].PartOfSpeech)
+}
+
+func TestReadCefr(t *testing.T) {
+	levels, err := ReadCefr(strings.NewReader("headword,pos,CEFR\nrun,verb,A1\nrun,noun,B1\ncolor/colour,noun,A1\nrun,verb,A2\n"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "A1", levels.Level("run", "verb"))
+	assert.Equal(t, "B1", levels.Level("run", "noun"))
+	assert.Equal(t, "A1", levels.Level("Run", "adverb"))
+	assert.Equal(t, "A1", levels.Level("colour", "noun"))
+	assert.Equal(t, "", levels.Level("walk", "verb"))
+
+	_, err = ReadCefr(strings.NewReader("a,b\n"))
+	assert.Error(t, err)
+}
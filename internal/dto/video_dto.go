@@ -1,7 +1,7 @@
 package dto
 
 type CreateVideoRequest struct {
-	YoutubeRawInput string `json:"youtubeRawInput"`
+	YoutubeRawInput string `json:"youtubeRawInput" validate:"required,youtube_id"`
 }
 
 type UpdateVideoRequest struct {
@@ -0,0 +1,66 @@
+// Package frequency reads English word frequency lists, such as the lists
+// built from OpenSubtitles by the FrequencyWords project, into lemma ranks.
+package frequency
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"io"
+	"shadowify/internal/model"
+	"shadowify/internal/nlp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Read reads a frequency list with a word and its count on each line, most
+// frequent first. The counts of the forms of a lemma are added up and the
+// lemmas ranked by them, leaving out stopwords like the lemmas of videos.
+// Lines without a count take the count of the line before, so a plain list of
+// words keeps its order. Blank lines and lines starting with # are skipped.
+func Read(r io.Reader) ([]*model.WordFrequency, error) {
+	counts := make(map[string]*model.WordFrequency)
+	var frequencies []*model.WordFrequency
+	var last int64
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		count := last
+		if len(fields) > 1 {
+			var err error
+			if count, err = strconv.ParseInt(fields[1], 10, 64); err != nil {
+				return nil, fmt.Errorf("line %d: invalid count %q", line, fields[1])
+			}
+		}
+		last = count
+
+		lemmas := nlp.Lemmas(fields[0])
+		if len(lemmas) != 1 || nlp.IsStopword(lemmas[0]) {
+			continue
+		}
+		frequency, ok := counts[lemmas[0]]
+		if !ok {
+			frequency = &model.WordFrequency{Lemma: lemmas[0]}
+			counts[lemmas[0]] = frequency
+			frequencies = append(frequencies, frequency)
+		}
+		frequency.Count += count
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	// Stable, so that equal counts keep the order of the list
+	slices.SortStableFunc(frequencies, func(a, b *model.WordFrequency) int {
+		return cmp.Compare(b.Count, a.Count)
+	})
+	for i, frequency := range frequencies {
+		frequency.Rank = i + 1
+	}
+	return frequencies, nil
+}
@@ -0,0 +1,47 @@
+package frequency
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRead(t *testing.T) {
+	list := `# en 50k
+the 2000
+know 900
+went 500
+go 450
+knows 300
+children 200
+child 150
+don't 120
+`
+	frequencies, err := Read(strings.NewReader(list))
+	require.NoError(t, err)
+
+	var lemmas []string
+	for i, frequency := range frequencies {
+		assert.Equal(t, i+1, frequency.Rank)
+		lemmas = append(lemmas, frequency.Lemma)
+	}
+	assert.Equal(t, []string{"know", "go", "child"}, lemmas, "stopwords and phrases are left out")
+	assert.EqualValues(t, 1200, frequencies[0].Count, "the counts of the forms add up")
+	assert.EqualValues(t, 950, frequencies[1].Count)
+}
+
+func TestRead_WordsOnly(t *testing.T) {
+	frequencies, err := Read(strings.NewReader("time\nperson\nyear\n"))
+	require.NoError(t, err)
+	require.Len(t, frequencies, 3)
+	assert.Equal(t, "time", frequencies[0].Lemma)
+	assert.Equal(t, "year", frequencies[2].Lemma)
+	assert.Equal(t, 3, frequencies[2].Rank)
+}
+
+func TestRead_InvalidCount(t *testing.T) {
+	_, err := Read(strings.NewReader("time 10\nperson many\n"))
+	assert.EqualError(t, err, `line 2: invalid count "many"`)
+}
@@ -1,8 +1,15 @@
+// Package ftsearch turns user search input into Postgres tsquery expressions.
+//
+// Input follows websearch_to_tsquery conventions: words are combined with
+// the default operator, "quoted text" matches a phrase, -word excludes a word
+// and the keyword or combines the terms around it. Characters with a meaning
+// in tsquery syntax are stripped so that any input yields a valid query.
 package ftsearch
 
 import (
 	"fmt"
 	"strings"
+	"unicode"
 )
 
 type Operator string
@@ -12,8 +19,21 @@ const (
 	AND Operator = "&"
 	OR  Operator = "|"
 	NOT Operator = "!"
+
+	// PHRASE joins the words of a phrase.
+	PHRASE Operator = "<->"
 )
 
+// Config is the text search configuration of the stored search vectors and
+// of the queries built against them. It stems English words, so "running"
+// matches "run".
+const Config = "english"
+
+// TranslationConfig is the text search configuration of the Vietnamese
+// meanings. It only lowercases words, English stemming and stop words would
+// mangle them.
+const TranslationConfig = "simple"
+
 func BuildTsqueryExpression(input string, options ...Options) string {
 	f := New(options...)
 	return f.buildTsqueryExpression(input)
@@ -49,15 +69,115 @@ func New(options ...Options) *ftsearch {
 	return f
 }
 
-func (f *ftsearch) buildTsqueryExpression(input string) string {
-	lexemes := strings.Fields(input)
+// Term is a word or quoted phrase of the search input.
+type Term struct {
+	// Lexemes are the words of the term. Punctuation splits a word into a
+	// phrase, the same way the tsvector parser splits "don't".
+	Lexemes []string
+	Quoted  bool
+	Negated bool
+	// Or reports that the term is combined with the previous one by OR
+	// instead of the default operator.
+	Or bool
+}
+
+// IsPhrase reports whether the words of the term must appear in sequence.
+func (t Term) IsPhrase() bool {
+	return t.Quoted || len(t.Lexemes) > 1
+}
+
+// Parse splits input into terms. Terms without any letter or digit are
+// dropped.
+func Parse(input string) []Term {
+	var terms []Term
+	or := false
+	for _, token := range tokenize(input) {
+		if !token.quoted && strings.EqualFold(token.text, "or") && !token.negated {
+			or = len(terms) > 0
+			continue
+		}
+
+		lexemes := strings.FieldsFunc(token.text, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		if len(lexemes) == 0 {
+			continue
+		}
+		terms = append(terms, Term{Lexemes: lexemes, Quoted: token.quoted, Negated: token.negated, Or: or})
+		or = false
+	}
+	return terms
+}
 
-	if f.isPrefixMatching {
-		for i, lexeme := range lexemes {
-			lexemes[i] = lexeme + ":*"
+func (f *ftsearch) buildTsqueryExpression(input string) string {
+	var b strings.Builder
+	for i, t := range Parse(input) {
+		if i > 0 {
+			operator := f.operator
+			if t.Or {
+				operator = OR
+			}
+			fmt.Fprintf(&b, " %s ", operator)
+		}
+		if t.Negated {
+			b.WriteString(string(NOT))
 		}
+		b.WriteString(f.termExpression(t))
 	}
+	return b.String()
+}
 
-	joinOperator := fmt.Sprintf(" %s ", f.operator)
-	return strings.Join(lexemes, joinOperator)
+// termExpression returns the tsquery of a single term.
+func (f *ftsearch) termExpression(t Term) string {
+	switch {
+	case len(t.Lexemes) > 1:
+		return "(" + strings.Join(t.Lexemes, fmt.Sprintf(" %s ", PHRASE)) + ")"
+	case !t.Quoted && f.isPrefixMatching:
+		return t.Lexemes[0] + ":*"
+	default:
+		return t.Lexemes[0]
+	}
+}
+
+type token struct {
+	text    string
+	quoted  bool
+	negated bool
+}
+
+// tokenize splits input into words and quoted phrases. An unterminated quote
+// runs to the end of the input.
+func tokenize(input string) []token {
+	var tokens []token
+	runes := []rune(input)
+	for i := 0; i < len(runes); {
+		if unicode.IsSpace(runes[i]) {
+			i++
+			continue
+		}
+
+		var t token
+		if runes[i] == '-' {
+			t.negated = true
+			i++
+		}
+		if i < len(runes) && runes[i] == '"' {
+			t.quoted = true
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			t.text = string(runes[i+1 : end])
+			i = end + 1
+		} else {
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && runes[end] != '"' {
+				end++
+			}
+			t.text = string(runes[i:end])
+			i = end
+		}
+		tokens = append(tokens, t)
+	}
+	return tokens
 }
@@ -44,3 +44,48 @@ func TestBuildTsqueryExpression(t *testing.T) {
 		assert.Equal(t, test.expected, result)
 	}
 }
+
+func TestBuildTsqueryExpression_Syntax(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		options  []Options
+		expected string
+	}{
+		{name: "tsquery characters are stripped", input: "it's a & b | c: (d) !e <f>", expected: "(it <-> s) & a & b & c & d & e & f"},
+		{name: "only punctuation", input: "' : & | ! *", expected: ""},
+		{name: "empty", input: "   ", expected: ""},
+		{name: "phrase", input: `"hello world" foo`, expected: "(hello <-> world) & foo"},
+		{name: "phrase without prefix", input: `"hello world" foo`, options: []Options{WithPrefixMatching()}, expected: "(hello <-> world) & foo:*"},
+		{name: "single word phrase", input: `"hello" foo`, options: []Options{WithPrefixMatching()}, expected: "hello & foo:*"},
+		{name: "unterminated phrase", input: `foo "hello world`, expected: "foo & (hello <-> world)"},
+		{name: "exclusion", input: "hello -world", expected: "hello & !world"},
+		{name: "excluded phrase", input: `hello -"big world"`, expected: "hello & !(big <-> world)"},
+		{name: "or", input: "cats or dogs birds", expected: "cats | dogs & birds"},
+		{name: "or is case insensitive", input: "cats OR dogs", expected: "cats | dogs"},
+		{name: "leading and trailing or", input: "or cats or", expected: "cats"},
+		{name: "quoted or is a word", input: `cats "or" dogs`, expected: "cats & or & dogs"},
+		{name: "lone dash", input: "hello - world", expected: "hello & world"},
+		{name: "unicode", input: "xin chào", expected: "xin & chào"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, BuildTsqueryExpression(test.input, test.options...))
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	terms := Parse(`cats or "big dog" -don't`)
+	assert.Equal(t, []Term{
+		{Lexemes: []string{"cats"}},
+		{Lexemes: []string{"big", "dog"}, Quoted: true, Or: true},
+		{Lexemes: []string{"don", "t"}, Negated: true},
+	}, terms)
+	assert.False(t, terms[0].IsPhrase())
+	assert.True(t, terms[1].IsPhrase())
+	assert.True(t, terms[2].IsPhrase())
+
+	assert.Empty(t, Parse(`or !!! ""`))
+}
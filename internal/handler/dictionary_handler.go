@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"shadowify/internal/response"
+	"shadowify/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type DictionaryHandler struct {
+	dictionaryService *service.DictionaryService
+}
+
+func NewDictionaryHandler(dictionaryService *service.DictionaryService) *DictionaryHandler {
+	return &DictionaryHandler{dictionaryService: dictionaryService}
+}
+
+func (h *DictionaryHandler) RegisterRoutes(e *echo.Echo) {
+	dictionary := e.Group("/dictionary")
+	dictionary.GET("/:word", h.Lookup)
+}
+
+// Lookup returns the parts of speech, definitions, pronunciation, examples and
+// CEFR level of a word.
+func (h *DictionaryHandler) Lookup(c echo.Context) error {
+	entries, err := h.dictionaryService.Lookup(c.Request().Context(), c.Param("word"))
+	if err != nil {
+		return response.WriteError(c, err)
+	}
+	return response.Success(c, entries)
+}
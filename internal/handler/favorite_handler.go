@@ -31,13 +31,13 @@ func (h *FavoriteHandler) Create(c echo.Context) error {
 	ctx := c.Request().Context()
 	userId, ok := model.FromContext(ctx)
 	if !ok {
-		return response.WriteError(c, apperr.NewAppErr("unauthorized", "User not authenticated"))
+		return response.WriteError(c, apperr.Unauthorized("unauthorized", "User not authenticated"))
 	}
 	videoId := c.Param("video_id")
 
 	err := h.favoriteService.Create(ctx, userId.Id, videoId)
 	if err != nil {
-		return response.WriteError(c, apperr.NewAppErr("internal_error", "Failed to create favorite").WithCause(err))
+		return response.WriteError(c, err)
 	}
 	return response.Success(c, "Favorite created successfully")
 }
@@ -46,13 +46,13 @@ func (h *FavoriteHandler) Delete(c echo.Context) error {
 	ctx := c.Request().Context()
 	userId, ok := model.FromContext(ctx)
 	if !ok {
-		return response.WriteError(c, apperr.NewAppErr("unauthorized", "User not authenticated"))
+		return response.WriteError(c, apperr.Unauthorized("unauthorized", "User not authenticated"))
 	}
 	videoId := c.Param("video_id")
 
 	err := h.favoriteService.Delete(ctx, userId.Id, videoId)
 	if err != nil {
-		return response.WriteError(c, apperr.NewAppErr("internal_error", "Failed to delete favorite").WithCause(err))
+		return response.WriteError(c, err)
 	}
 	return response.Success(c, "Favorite deleted successfully")
 }
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"net/http"
+	"shadowify/internal/apperr"
+	"shadowify/internal/middleware"
+	"shadowify/internal/model"
+	"shadowify/internal/response"
+	"shadowify/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type HealthHandler struct {
+	healthService *service.HealthService
+}
+
+func NewHealthHandler(healthService *service.HealthService) *HealthHandler {
+	return &HealthHandler{
+		healthService: healthService,
+	}
+}
+
+func (h *HealthHandler) RegisterRoutes(e *echo.Echo, adminMiddleware *middleware.Admin) {
+	e.GET("/healthz", h.Live)
+	e.GET("/readyz", h.Ready)
+	e.GET("/admin/diagnostics", h.Diagnostics, adminMiddleware.Authenticate)
+}
+
+// Live reports that the process is running. It does not touch dependencies.
+func (h *HealthHandler) Live(c echo.Context) error {
+	return response.Success(c, map[string]model.HealthStatus{"status": model.HealthStatusUp})
+}
+
+// Ready checks the database, the search index and the workspace and responds
+// with 503 when one is down. Tools and external services are only checked by
+// Diagnostics.
+func (h *HealthHandler) Ready(c echo.Context) error {
+	report := h.healthService.Ready(c.Request().Context())
+	if report.Status != model.HealthStatusUp {
+		res := response.NewErrorResponse(apperr.Unavailable("service_unavailable", "One or more dependencies are unavailable"))
+		res.Data = report.Summary()
+		res.RequestId = c.Response().Header().Get(echo.HeaderXRequestID)
+		return c.JSON(http.StatusServiceUnavailable, res)
+	}
+	return response.Success(c, report.Summary())
+}
+
+// Diagnostics returns versions, latencies and errors of every dependency
+// along with resource usage.
+func (h *HealthHandler) Diagnostics(c echo.Context) error {
+	return response.Success(c, h.healthService.Diagnostics(c.Request().Context()))
+}
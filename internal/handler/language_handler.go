@@ -45,16 +45,10 @@ func (h *LanguageHandler) CreateLanguage(c echo.Context) error {
 
 	var req model.CreateLanguageRequest
 	if err := c.Bind(&req); err != nil {
-		return response.WriteError(c, apperr.NewAppErr("bad_request", "Invalid request format"))
+		return response.WriteError(c, apperr.BadRequest("bad_request", "Invalid request format"))
 	}
-
-	// Validate request
-	if req.Code == "" {
-		return response.WriteError(c, apperr.NewAppErr("bad_request", "Code is required").WithField("code"))
-	}
-
-	if req.Name == "" {
-		return response.WriteError(c, apperr.NewAppErr("bad_request", "Name is required").WithField("name"))
+	if err := c.Validate(&req); err != nil {
+		return response.WriteError(c, err)
 	}
 
 	language, err := h.languageService.Create(ctx, &req)
@@ -125,7 +119,10 @@ func (h *LanguageHandler) UpdateLanguage(c echo.Context) error {
 
 	var req model.UpdateLanguageRequest
 	if err := c.Bind(&req); err != nil {
-		return response.WriteError(c, apperr.NewAppErr("bad_request", "Invalid request format"))
+		return response.WriteError(c, apperr.BadRequest("bad_request", "Invalid request format"))
+	}
+	if err := c.Validate(&req); err != nil {
+		return response.WriteError(c, err)
 	}
 
 	language, err := h.languageService.Update(ctx, id, &req)
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"shadowify/internal/apperr"
+	"shadowify/internal/model"
 	"shadowify/internal/response"
 	"shadowify/internal/service"
 
@@ -32,7 +33,7 @@ func NewSegmentHandler(segmentService *service.SegmentService) *SegmentHandler {
 func (h *SegmentHandler) GetSegmentsByVideoID(c echo.Context) error {
 	videoID := c.Param("video_id")
 	if videoID == "" {
-		return response.WriteError(c, apperr.NewAppErr("segment.invalid_video_id", "video_id is required"))
+		return response.WriteError(c, apperr.Validation("validation.required", "video_id is required").WithField("video_id"))
 	}
 
 	segments, err := h.segmentService.GetSegmentsByVideoID(c.Request().Context(), videoID)
@@ -46,22 +47,48 @@ func (h *SegmentHandler) GetSegmentsByVideoID(c echo.Context) error {
 // RegisterRoutes registers the segment routes to the provided Echo instance
 func (h *SegmentHandler) RegisterRoutes(e *echo.Echo) {
 	e.GET("/videos/:video_id/segments", h.GetSegmentsByVideoID)
+	e.GET("/segments/search", h.SearchTranscripts)
 	e.GET("/segments/:segment_id", h.GetSegmentByID)
 }
 
 func (h *SegmentHandler) GetSegmentByID(c echo.Context) error {
 	segmentID := c.Param("segment_id")
 	if segmentID == "" {
-		return response.WriteError(c, apperr.NewAppErr("segment.invalid_segment_id", "segment_id is required"))
+		return response.WriteError(c, apperr.Validation("validation.required", "segment_id is required").WithField("segment_id"))
 	}
 
 	segment, err := h.segmentService.GetSegmentByID(c.Request().Context(), segmentID)
 	if err != nil {
 		return response.WriteError(c, err)
 	}
-	if segment == nil {
-		return response.WriteError(c, apperr.NewAppErr("segment.not_found", "Segment not found"))
+	return response.Success(c, segment)
+}
+
+// SearchTranscripts godoc
+// @Summary Search transcripts
+// @Description Find the moments a phrase is spoken across all videos. Matches are grouped by video, best match first, with highlighted snippets.
+// @Tags segments
+// @Produce json
+// @Param q query string true "Search query"
+// @Param cefr query string false "CEFR level of the video"
+// @Param category query string false "Video category"
+// @Success 200 {array} model.TranscriptMatch
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /segments/search [get]
+func (h *SegmentHandler) SearchTranscripts(c echo.Context) error {
+	ctx := c.Request().Context()
+	var filter model.TranscriptFilter
+	if err := c.Bind(&filter); err != nil {
+		return response.WriteError(c, apperr.BadRequest("bad_request", "invalid filter parameters"))
+	}
+	if err := c.Validate(&filter); err != nil {
+		return response.WriteError(c, err)
 	}
 
-	return response.Success(c, segment)
+	matches, total, err := h.segmentService.SearchTranscripts(ctx, &filter)
+	if err != nil {
+		return response.WriteError(c, err)
+	}
+	return response.SuccessWithPagination(c, matches, filter.Pagination.WithTotal(total))
 }
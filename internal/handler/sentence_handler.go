@@ -4,6 +4,7 @@ import (
 	"shadowify/internal/apperr"
 	"shadowify/internal/middleware"
 	"shadowify/internal/model"
+	"shadowify/internal/ratelimit"
 	"shadowify/internal/response"
 	"shadowify/internal/service"
 
@@ -18,11 +19,11 @@ func NewSentenceHandler(sentenceService *service.SentenceService) *SentenceHandl
 	return &SentenceHandler{sentenceService: sentenceService}
 }
 
-func (h *SentenceHandler) RegisterRoutes(e *echo.Echo, device *middleware.Device) {
+func (h *SentenceHandler) RegisterRoutes(e *echo.Echo, device *middleware.Device, rateLimit *middleware.RateLimit) {
 	sentences := e.Group("/sentences")
 	sentences.GET("/segments/:segmentId", h.GetBySegmentId, device.Authenticate)
 	sentences.DELETE("/segments/:segmentId", h.DeleteBySegmentId, device.Authenticate)
-	sentences.POST("", h.Create, device.Authenticate)
+	sentences.POST("", h.Create, device.Authenticate, rateLimit.Limit(ratelimit.PolicyTranslate))
 	sentences.GET("", h.List, device.Authenticate)
 }
 
@@ -30,7 +31,7 @@ func (h *SentenceHandler) GetBySegmentId(c echo.Context) error {
 	ctx := c.Request().Context()
 	user, ok := model.FromContext(ctx)
 	if !ok {
-		return response.WriteError(c, apperr.NewAppErr("unauthorized", "User not authenticated"))
+		return response.WriteError(c, apperr.Unauthorized("unauthorized", "User not authenticated"))
 	}
 
 	segmentId := c.Param("segmentId")
@@ -38,10 +39,6 @@ func (h *SentenceHandler) GetBySegmentId(c echo.Context) error {
 	if err != nil {
 		return response.WriteError(c, err)
 	}
-	if sentence == nil {
-		return response.WriteError(c, apperr.NewAppErr("not_found", "Sentence not found"))
-	}
-
 	return response.Success(c, sentence)
 }
 
@@ -49,12 +46,15 @@ func (h *SentenceHandler) Create(c echo.Context) error {
 	ctx := c.Request().Context()
 	user, ok := model.FromContext(ctx)
 	if !ok {
-		return response.WriteError(c, apperr.NewAppErr("unauthorized", "User not authenticated"))
+		return response.WriteError(c, apperr.Unauthorized("unauthorized", "User not authenticated"))
 	}
 
 	var req model.SentenceCreateRequest
 	if err := c.Bind(&req); err != nil {
-		return response.WriteError(c, apperr.NewAppErr("bad_request", "Invalid request format"))
+		return response.WriteError(c, apperr.BadRequest("bad_request", "Invalid request format"))
+	}
+	if err := c.Validate(&req); err != nil {
+		return response.WriteError(c, err)
 	}
 
 	sentence := &model.Sentence{
@@ -63,7 +63,7 @@ func (h *SentenceHandler) Create(c echo.Context) error {
 		MeaningEN: req.MeaningEN,
 	}
 
-	if err := h.sentenceService.Create(sentence); err != nil {
+	if err := h.sentenceService.Create(ctx, sentence); err != nil {
 		return response.WriteError(c, err)
 	}
 
@@ -74,7 +74,7 @@ func (h *SentenceHandler) DeleteBySegmentId(c echo.Context) error {
 	ctx := c.Request().Context()
 	user, ok := model.FromContext(ctx)
 	if !ok {
-		return response.WriteError(c, apperr.NewAppErr("unauthorized", "User not authenticated"))
+		return response.WriteError(c, apperr.Unauthorized("unauthorized", "User not authenticated"))
 	}
 
 	segmentId := c.Param("segmentId")
@@ -90,10 +90,13 @@ func (h *SentenceHandler) List(c echo.Context) error {
 	var filter model.SentenceFilter
 	user, ok := model.FromContext(ctx)
 	if !ok {
-		return response.WriteError(c, apperr.NewAppErr("unauthorized", "User not authenticated"))
+		return response.WriteError(c, apperr.Unauthorized("unauthorized", "User not authenticated"))
 	}
 	if err := c.Bind(&filter); err != nil {
-		return response.WriteError(c, apperr.NewAppErr("bad_request", "invalid filter parameters"))
+		return response.WriteError(c, apperr.BadRequest("bad_request", "invalid filter parameters"))
+	}
+	if err := c.Validate(&filter); err != nil {
+		return response.WriteError(c, err)
 	}
 	filter.UserId = user.Id
 
@@ -1,39 +1,84 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"shadowify/i18n"
 	"shadowify/internal/apperr"
+	"shadowify/internal/middleware"
 	"shadowify/internal/model"
+	"shadowify/internal/ratelimit"
 	"shadowify/internal/response"
 	"shadowify/internal/service"
+	"slices"
+	"strings"
 
+	"github.com/gorilla/websocket"
 	"github.com/labstack/echo/v4"
 )
 
+const (
+	// audioFormField is the multipart field that holds the recording.
+	audioFormField = "audio"
+	// maxStreamMessageSize limits a single WebSocket frame.
+	maxStreamMessageSize = 1 << 20
+)
+
 type STTHandler struct {
 	sttService *service.STTService
+	upgrader   websocket.Upgrader
 }
 
-func NewSTTHandler(sttService *service.STTService) *STTHandler {
+func NewSTTHandler(sttService *service.STTService, allowOrigins []string) *STTHandler {
 	return &STTHandler{
 		sttService: sttService,
+		upgrader:   websocket.Upgrader{CheckOrigin: originChecker(allowOrigins)},
+	}
+}
+
+// originChecker allows WebSockets from the origins allowed by CORS. Browsers
+// do not apply CORS to WebSockets, so without the check any site could
+// stream audio on behalf of its visitors. Without allowed origins only the
+// API's own origin is accepted.
+func originChecker(allowOrigins []string) func(r *http.Request) bool {
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			// Not a browser
+			return true
+		}
+		if slices.Contains(allowOrigins, "*") || slices.Contains(allowOrigins, origin) {
+			return true
+		}
+		u, err := url.Parse(origin)
+		return err == nil && strings.EqualFold(u.Host, r.Host)
 	}
 }
 
-func (h *STTHandler) RegisterRoutes(e *echo.Echo) {
-	stt := e.Group("/stt")
+func (h *STTHandler) RegisterRoutes(e *echo.Echo, rateLimit *middleware.RateLimit) {
+	stt := e.Group("/stt", rateLimit.Limit(ratelimit.PolicySTT))
 	stt.POST("/transcribe", h.TranscribeAudio)
 	stt.POST("/evaluate", h.EvaluateAudio)
+	stt.GET("/stream", h.StreamAudio)
 }
 
 func (h *STTHandler) EvaluateAudio(c echo.Context) error {
 	var request model.EvaluateInput
-	if err := c.Bind(&request); err != nil {
-		return response.WriteError(c, apperr.NewAppErr("bad_request", "invalid filter parameters"))
+	if err := bindAudio(c, &request.AudioInput); err != nil {
+		return response.WriteError(c, err)
+	}
+	request.SegmentId = c.QueryParam("segment_id")
+	if err := c.Validate(&request.PracticeTarget); err != nil {
+		return response.WriteError(c, err)
 	}
 
 	output, err := h.sttService.EvaluateAudio(c.Request().Context(), &request)
 	if err != nil {
-		return response.WriteError(c, apperr.NewAppErr("internal_error", "failed to evaluate audio").WithCause(err))
+		return response.WriteError(c, err)
 	}
 
 	return response.Success(c, output)
@@ -41,14 +86,121 @@ func (h *STTHandler) EvaluateAudio(c echo.Context) error {
 
 func (h *STTHandler) TranscribeAudio(c echo.Context) error {
 	var request model.TranscribeInput
-	if err := c.Bind(&request); err != nil {
-		response.WriteError(c, apperr.NewAppErr("bad_request", "invalid filter parameters"))
+	if err := bindAudio(c, &request.AudioInput); err != nil {
+		return response.WriteError(c, err)
 	}
 
 	output, err := h.sttService.Transcribe(c.Request().Context(), &request)
 	if err != nil {
-		return response.WriteError(c, apperr.NewAppErr("internal_error", "failed to transcribe audio").WithCause(err))
+		return response.WriteError(c, err)
 	}
 
 	return response.Success(c, output)
 }
+
+// StreamAudio transcribes audio pushed over a WebSocket. The client sends a
+// "start" text frame, binary audio chunks and a "stop" text frame; the server
+// replies with partial and final hypotheses and finally the evaluation result.
+func (h *STTHandler) StreamAudio(c echo.Context) error {
+	ws, err := h.upgrader.Upgrade(c.Response(), c.Request(), nil)
+	if err != nil {
+		// The upgrader has already written the HTTP error.
+		return nil
+	}
+	defer ws.Close()
+	ws.SetReadLimit(maxStreamMessageSize)
+
+	ctx := c.Request().Context()
+	var stream *service.STTStream
+	for {
+		messageType, data, err := ws.ReadMessage()
+		if err != nil {
+			return nil
+		}
+
+		var events []*model.STTStreamEvent
+		done := false
+		switch messageType {
+		case websocket.TextMessage:
+			var msg model.STTStreamMessage
+			if err = json.Unmarshal(data, &msg); err != nil {
+				err = apperr.BadRequest("bad_request", "invalid stream message").WithCause(err)
+				break
+			}
+			switch {
+			case msg.Type == model.STTStreamStart && stream == nil:
+				stream, err = h.sttService.NewStream(ctx, &msg)
+				if err == nil {
+					defer stream.Close()
+				}
+			case msg.Type == model.STTStreamStop && stream != nil:
+				events, err = stream.Finish(ctx)
+				done = true
+			default:
+				err = apperr.BadRequest("stt.stream.unexpected_message", "Unexpected stream message").WithParam("type", msg.Type)
+			}
+		case websocket.BinaryMessage:
+			if stream == nil {
+				err = apperr.BadRequest("stt.stream.not_started", "Stream has not been started")
+				break
+			}
+			events, err = stream.Write(ctx, data)
+		}
+
+		if err != nil {
+			events = append(events, &model.STTStreamEvent{Type: model.STTStreamError, Error: i18n.Localize(ctx, apperr.From(err))})
+			done = true
+		}
+		for _, event := range events {
+			if err := ws.WriteJSON(event); err != nil {
+				return nil
+			}
+		}
+		if done {
+			return nil
+		}
+	}
+}
+
+// bindAudio reads the recording from a multipart "audio" part, a raw audio/*
+// body or a JSON body with audio_base64. Multipart and raw bodies are streamed
+// rather than buffered in memory.
+func bindAudio(c echo.Context, input *model.AudioInput) error {
+	req := c.Request()
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get(echo.HeaderContentType))
+
+	switch {
+	case mediaType == echo.MIMEMultipartForm:
+		reader, err := req.MultipartReader()
+		if err != nil {
+			return apperr.BadRequest("bad_request", "invalid multipart body").WithCause(err)
+		}
+		for {
+			part, err := reader.NextPart()
+			if errors.Is(err, io.EOF) {
+				return apperr.Validation("validation.required", "audio is required").WithField(audioFormField)
+			}
+			if err != nil {
+				// Truncated or malformed body, or the client went away
+				return apperr.BadRequest("bad_request", "invalid multipart body").WithCause(err)
+			}
+			if part.FormName() != audioFormField {
+				continue
+			}
+			input.Audio = part
+			if ct := part.Header.Get(echo.HeaderContentType); strings.HasPrefix(ct, "audio/") {
+				input.ContentType = ct
+			}
+			return nil
+		}
+	case strings.HasPrefix(mediaType, "audio/"):
+		input.Audio = req.Body
+		input.ContentType = mediaType
+		return nil
+	default:
+		if err := c.Bind(input); err != nil {
+			return apperr.BadRequest("bad_request", "invalid request body").WithCause(err)
+		}
+		return c.Validate(input)
+	}
+}
@@ -2,7 +2,9 @@ package handler
 
 import (
 	"shadowify/internal/apperr"
+	"shadowify/internal/middleware"
 	"shadowify/internal/model"
+	"shadowify/internal/ratelimit"
 	"shadowify/internal/response"
 	"shadowify/internal/service"
 
@@ -19,20 +21,23 @@ func NewTranslatorHandler(translatorService *service.TranslatorService) *Transla
 	}
 }
 
-func (h *TranslatorHandler) RegisterRoutes(e *echo.Echo) {
-	e.POST("/translate", h.Translate)
+func (h *TranslatorHandler) RegisterRoutes(e *echo.Echo, rateLimit *middleware.RateLimit) {
+	e.POST("/translate", h.Translate, rateLimit.Limit(ratelimit.PolicyTranslate))
 }
 
 func (h *TranslatorHandler) Translate(c echo.Context) error {
 	var input model.TranslateInput
 
 	if err := c.Bind(&input); err != nil {
-		return response.WriteError(c, apperr.NewAppErr("bad_request", "invalid input parameters").WithCause(err))
+		return response.WriteError(c, apperr.BadRequest("bad_request", "invalid input parameters").WithCause(err))
+	}
+	if err := c.Validate(&input); err != nil {
+		return response.WriteError(c, err)
 	}
 
 	output, err := h.translatorService.Translate(c.Request().Context(), &input)
 	if err != nil {
-		return response.WriteError(c, apperr.NewAppErr("internal_error", "failed to translate text").WithCause(err))
+		return response.WriteError(c, err)
 	}
 
 	return response.Success(c, output)
@@ -1,10 +1,12 @@
 package handler
 
 import (
+	"net/http"
 	"shadowify/internal/apperr"
 	"shadowify/internal/dto"
 	"shadowify/internal/middleware"
 	"shadowify/internal/model"
+	"shadowify/internal/ratelimit"
 	"shadowify/internal/response"
 	"shadowify/internal/service"
 
@@ -12,18 +14,19 @@ import (
 )
 
 type VideoHandler struct {
-	service *service.VideoService
+	service               *service.VideoService
+	recommendationService *service.RecommendationService
 }
 
-func NewVideoHandler(s *service.VideoService) *VideoHandler {
-	return &VideoHandler{service: s}
+func NewVideoHandler(s *service.VideoService, recommendationService *service.RecommendationService) *VideoHandler {
+	return &VideoHandler{service: s, recommendationService: recommendationService}
 }
 
-func (h *VideoHandler) RegisterRoutes(e *echo.Echo, device *middleware.Device) {
+func (h *VideoHandler) RegisterRoutes(e *echo.Echo, device *middleware.Device, rateLimit *middleware.RateLimit) {
 	v := e.Group("/videos")
-	v.POST("", h.Create)
+	v.POST("", h.Create, rateLimit.Limit(ratelimit.PolicyVideoCreate))
 	v.GET("/:id", h.GetByID, device.Authenticate)
-	v.GET("", h.List)
+	v.GET("", h.List, device.Authenticate)
 	v.GET("/categories", h.Categories)
 	v.GET("/favorites", h.GetFavoriteVideos, device.Authenticate)
 }
@@ -32,11 +35,14 @@ func (h *VideoHandler) GetFavoriteVideos(c echo.Context) error {
 	ctx := c.Request().Context()
 	user, ok := model.FromContext(ctx)
 	if !ok {
-		return response.WriteError(c, apperr.NewAppErr("unauthorized", "User not authenticated"))
+		return response.WriteError(c, apperr.Unauthorized("unauthorized", "User not authenticated"))
 	}
 	var filter model.FavoriteVideoFilter
 	if err := c.Bind(&filter); err != nil {
-		return response.WriteError(c, apperr.NewAppErr("bad_request", "invalid filter parameters"))
+		return response.WriteError(c, apperr.BadRequest("bad_request", "invalid filter parameters"))
+	}
+	if err := c.Validate(&filter); err != nil {
+		return response.WriteError(c, err)
 	}
 
 	videos, total, err := h.service.GetFavoriteVideos(ctx, user.Id, &filter)
@@ -50,7 +56,10 @@ func (h *VideoHandler) Create(c echo.Context) error {
 	ctx := c.Request().Context()
 	var req dto.CreateVideoRequest
 	if err := c.Bind(&req); err != nil {
-		return response.WriteError(c, apperr.NewAppErr("bad_request", "invalid request"))
+		return response.WriteError(c, apperr.BadRequest("bad_request", "invalid request"))
+	}
+	if err := c.Validate(&req); err != nil {
+		return response.WriteError(c, err)
 	}
 	video, err := h.service.Create(ctx, &req)
 	if err != nil {
@@ -64,12 +73,15 @@ func (h *VideoHandler) GetByID(c echo.Context) error {
 	id := c.Param("id")
 	user, ok := model.FromContext(ctx)
 	if !ok {
-		return response.WriteError(c, apperr.NewAppErr("unauthorized", "User not authenticated"))
+		return response.WriteError(c, apperr.Unauthorized("unauthorized", "User not authenticated"))
 	}
 	video, err := h.service.GetById(ctx, id, user.Id)
 	if err != nil {
 		return response.WriteError(c, err)
 	}
+
+	// Users behind the same address are told apart by device
+	h.service.RecordView(video.Id, user.Id, c.RealIP())
 	return response.Success(c, video)
 }
 
@@ -77,14 +89,40 @@ func (h *VideoHandler) List(c echo.Context) error {
 	ctx := c.Request().Context()
 	var filter model.VideoFilter
 	if err := c.Bind(&filter); err != nil {
-		return response.WriteError(c, apperr.NewAppErr("bad_request", "invalid filter parameters"))
+		return response.WriteError(c, apperr.BadRequest("bad_request", "invalid filter parameters"))
+	}
+	if err := c.Validate(&filter); err != nil {
+		return response.WriteError(c, err)
+	}
+
+	user, ok := model.FromContext(ctx)
+	if !ok {
+		return response.WriteError(c, apperr.Unauthorized("unauthorized", "User not authenticated"))
 	}
+	filter.UserId = user.Id
 
-	videos, total, err := h.service.List(ctx, &filter)
+	var videos any
+	var total int64
+	var err error
+	if filter.Type == model.VideoRecommended {
+		videos, total, err = h.recommendationService.Recommend(ctx, user.Id, &filter)
+	} else {
+		videos, total, err = h.service.List(ctx, &filter)
+	}
 	if err != nil {
 		return response.WriteError(c, err)
 	}
-	return response.SuccessWithPagination(c, videos, filter.Pagination.WithTotal(total))
+	if !filter.Facets {
+		return response.SuccessWithPagination(c, videos, filter.Pagination.WithTotal(total))
+	}
+
+	facets, err := h.service.Facets(ctx, &filter)
+	if err != nil {
+		return response.WriteError(c, err)
+	}
+	return c.JSON(http.StatusOK, response.NewSuccessResponse(videos).
+		WithPagination(filter.Pagination.WithTotal(total)).
+		WithMetadata(map[string]any{"facets": facets}))
 }
 
 func (h *VideoHandler) Categories(c echo.Context) error {
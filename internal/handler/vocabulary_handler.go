@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"shadowify/internal/apperr"
+	"shadowify/internal/middleware"
+	"shadowify/internal/model"
+	"shadowify/internal/response"
+	"shadowify/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type VocabularyHandler struct {
+	vocabularyService *service.VocabularyService
+}
+
+func NewVocabularyHandler(vocabularyService *service.VocabularyService) *VocabularyHandler {
+	return &VocabularyHandler{vocabularyService: vocabularyService}
+}
+
+func (h *VocabularyHandler) RegisterRoutes(e *echo.Echo, device *middleware.Device) {
+	vocabulary := e.Group("/vocabulary")
+	vocabulary.GET("", h.List, device.Authenticate)
+	vocabulary.PUT("/:word", h.SetStatus, device.Authenticate)
+	vocabulary.POST("/seed", h.Seed, device.Authenticate)
+	vocabulary.GET("/quiz", h.Quiz, device.Authenticate)
+	vocabulary.POST("/quiz", h.SubmitQuiz, device.Authenticate)
+}
+
+func (h *VocabularyHandler) List(c echo.Context) error {
+	ctx := c.Request().Context()
+	user, ok := model.FromContext(ctx)
+	if !ok {
+		return response.WriteError(c, apperr.Unauthorized("unauthorized", "User not authenticated"))
+	}
+	var filter model.VocabularyFilter
+	if err := c.Bind(&filter); err != nil {
+		return response.WriteError(c, apperr.BadRequest("bad_request", "invalid filter parameters"))
+	}
+	if err := c.Validate(&filter); err != nil {
+		return response.WriteError(c, err)
+	}
+	filter.UserId = user.Id
+
+	words, total, err := h.vocabularyService.List(ctx, &filter)
+	if err != nil {
+		return response.WriteError(c, err)
+	}
+	return response.SuccessWithPagination(c, words, filter.Pagination.WithTotal(total))
+}
+
+func (h *VocabularyHandler) SetStatus(c echo.Context) error {
+	ctx := c.Request().Context()
+	user, ok := model.FromContext(ctx)
+	if !ok {
+		return response.WriteError(c, apperr.Unauthorized("unauthorized", "User not authenticated"))
+	}
+	var req model.VocabularyUpdateRequest
+	if err := c.Bind(&req); err != nil {
+		return response.WriteError(c, apperr.BadRequest("bad_request", "Invalid request format"))
+	}
+	if err := c.Validate(&req); err != nil {
+		return response.WriteError(c, err)
+	}
+
+	word, err := h.vocabularyService.SetStatus(ctx, user.Id, c.Param("word"), req.Status)
+	if err != nil {
+		return response.WriteError(c, err)
+	}
+	return response.Success(c, word)
+}
+
+func (h *VocabularyHandler) Seed(c echo.Context) error {
+	ctx := c.Request().Context()
+	user, ok := model.FromContext(ctx)
+	if !ok {
+		return response.WriteError(c, apperr.Unauthorized("unauthorized", "User not authenticated"))
+	}
+	var req model.VocabularySeedRequest
+	if err := c.Bind(&req); err != nil {
+		return response.WriteError(c, apperr.BadRequest("bad_request", "Invalid request format"))
+	}
+	if err := c.Validate(&req); err != nil {
+		return response.WriteError(c, err)
+	}
+
+	result, err := h.vocabularyService.Seed(ctx, user.Id, &req)
+	if err != nil {
+		return response.WriteError(c, err)
+	}
+	return response.Success(c, result)
+}
+
+func (h *VocabularyHandler) Quiz(c echo.Context) error {
+	words, err := h.vocabularyService.Quiz(c.Request().Context())
+	if err != nil {
+		return response.WriteError(c, err)
+	}
+	return response.Success(c, words)
+}
+
+func (h *VocabularyHandler) SubmitQuiz(c echo.Context) error {
+	ctx := c.Request().Context()
+	user, ok := model.FromContext(ctx)
+	if !ok {
+		return response.WriteError(c, apperr.Unauthorized("unauthorized", "User not authenticated"))
+	}
+	var req model.VocabularyQuizRequest
+	if err := c.Bind(&req); err != nil {
+		return response.WriteError(c, apperr.BadRequest("bad_request", "Invalid request format"))
+	}
+	if err := c.Validate(&req); err != nil {
+		return response.WriteError(c, err)
+	}
+
+	result, err := h.vocabularyService.SubmitQuiz(ctx, user.Id, &req)
+	if err != nil {
+		return response.WriteError(c, err)
+	}
+	return response.Success(c, result)
+}
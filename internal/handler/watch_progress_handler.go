@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"shadowify/internal/apperr"
+	"shadowify/internal/middleware"
+	"shadowify/internal/model"
+	"shadowify/internal/response"
+	"shadowify/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type WatchProgressHandler struct {
+	watchProgressService *service.WatchProgressService
+}
+
+func NewWatchProgressHandler(watchProgressService *service.WatchProgressService) *WatchProgressHandler {
+	return &WatchProgressHandler{watchProgressService: watchProgressService}
+}
+
+func (h *WatchProgressHandler) RegisterRoutes(e *echo.Echo, device *middleware.Device) {
+	v := e.Group("/videos")
+	v.GET("/continue-watching", h.ContinueWatching, device.Authenticate)
+	v.GET("/:id/progress", h.Get, device.Authenticate)
+	v.PUT("/:id/progress", h.Save, device.Authenticate)
+}
+
+func (h *WatchProgressHandler) Save(c echo.Context) error {
+	ctx := c.Request().Context()
+	user, ok := model.FromContext(ctx)
+	if !ok {
+		return response.WriteError(c, apperr.Unauthorized("unauthorized", "User not authenticated"))
+	}
+	var req model.WatchProgressRequest
+	if err := c.Bind(&req); err != nil {
+		return response.WriteError(c, apperr.BadRequest("bad_request", "Invalid request format"))
+	}
+	if err := c.Validate(&req); err != nil {
+		return response.WriteError(c, err)
+	}
+
+	progress, err := h.watchProgressService.Save(ctx, user.Id, c.Param("id"), &req)
+	if err != nil {
+		return response.WriteError(c, err)
+	}
+	return response.Success(c, progress)
+}
+
+func (h *WatchProgressHandler) Get(c echo.Context) error {
+	ctx := c.Request().Context()
+	user, ok := model.FromContext(ctx)
+	if !ok {
+		return response.WriteError(c, apperr.Unauthorized("unauthorized", "User not authenticated"))
+	}
+
+	progress, err := h.watchProgressService.Get(ctx, user.Id, c.Param("id"))
+	if err != nil {
+		return response.WriteError(c, err)
+	}
+	return response.Success(c, progress)
+}
+
+func (h *WatchProgressHandler) ContinueWatching(c echo.Context) error {
+	ctx := c.Request().Context()
+	user, ok := model.FromContext(ctx)
+	if !ok {
+		return response.WriteError(c, apperr.Unauthorized("unauthorized", "User not authenticated"))
+	}
+	var filter model.ContinueWatchingFilter
+	if err := c.Bind(&filter); err != nil {
+		return response.WriteError(c, apperr.BadRequest("bad_request", "invalid filter parameters"))
+	}
+	if err := c.Validate(&filter); err != nil {
+		return response.WriteError(c, err)
+	}
+	filter.UserId = user.Id
+
+	videos, total, err := h.watchProgressService.ContinueWatching(ctx, &filter)
+	if err != nil {
+		return response.WriteError(c, err)
+	}
+	return response.SuccessWithPagination(c, videos, filter.Pagination.WithTotal(total))
+}
@@ -4,6 +4,7 @@ import (
 	"shadowify/internal/apperr"
 	"shadowify/internal/middleware"
 	"shadowify/internal/model"
+	"shadowify/internal/ratelimit"
 	"shadowify/internal/response"
 	"shadowify/internal/service"
 
@@ -18,9 +19,9 @@ func NewWordHandler(wordService *service.WordService) *WordHandler {
 	return &WordHandler{wordService: wordService}
 }
 
-func (h *WordHandler) RegisterRoutes(e *echo.Echo, device *middleware.Device) {
+func (h *WordHandler) RegisterRoutes(e *echo.Echo, device *middleware.Device, rateLimit *middleware.RateLimit) {
 	words := e.Group("/words")
-	words.POST("", h.Create, device.Authenticate)
+	words.POST("", h.Create, device.Authenticate, rateLimit.Limit(ratelimit.PolicyTranslate))
 	words.GET("", h.List, device.Authenticate)
 	words.DELETE("/:word", h.Delete, device.Authenticate)
 	words.GET("/:word", h.GetByWord, device.Authenticate)
@@ -30,7 +31,7 @@ func (h *WordHandler) GetByWord(c echo.Context) error {
 	ctx := c.Request().Context()
 	user, ok := model.FromContext(ctx)
 	if !ok {
-		return response.WriteError(c, apperr.NewAppErr("unauthorized", "User not authenticated"))
+		return response.WriteError(c, apperr.Unauthorized("unauthorized", "User not authenticated"))
 	}
 	word := c.Param("word")
 
@@ -38,10 +39,6 @@ func (h *WordHandler) GetByWord(c echo.Context) error {
 	if err != nil {
 		return response.WriteError(c, err)
 	}
-	if result == nil {
-		return response.WriteError(c, apperr.NewAppErr("not_found", "Word not found"))
-	}
-
 	return response.Success(c, result)
 }
 
@@ -49,11 +46,14 @@ func (h *WordHandler) Create(c echo.Context) error {
 	ctx := c.Request().Context()
 	user, ok := model.FromContext(ctx)
 	if !ok {
-		return response.WriteError(c, apperr.NewAppErr("unauthorized", "User not authenticated"))
+		return response.WriteError(c, apperr.Unauthorized("unauthorized", "User not authenticated"))
 	}
 	var req model.WordCreateRequest
 	if err := c.Bind(&req); err != nil {
-		return response.WriteError(c, apperr.NewAppErr("bad_request", "Invalid request format"))
+		return response.WriteError(c, apperr.BadRequest("bad_request", "Invalid request format"))
+	}
+	if err := c.Validate(&req); err != nil {
+		return response.WriteError(c, err)
 	}
 	word := &model.Word{
 		MeaningEN: req.MeaningEN,
@@ -72,10 +72,13 @@ func (h *WordHandler) List(c echo.Context) error {
 	var filter model.WordFilter
 	user, ok := model.FromContext(ctx)
 	if !ok {
-		return response.WriteError(c, apperr.NewAppErr("unauthorized", "User not authenticated"))
+		return response.WriteError(c, apperr.Unauthorized("unauthorized", "User not authenticated"))
 	}
 	if err := c.Bind(&filter); err != nil {
-		return response.WriteError(c, apperr.NewAppErr("bad_request", "invalid filter parameters"))
+		return response.WriteError(c, apperr.BadRequest("bad_request", "invalid filter parameters"))
+	}
+	if err := c.Validate(&filter); err != nil {
+		return response.WriteError(c, err)
 	}
 	filter.UserId = user.Id
 
@@ -90,7 +93,7 @@ func (h *WordHandler) Delete(c echo.Context) error {
 	ctx := c.Request().Context()
 	user, ok := model.FromContext(ctx)
 	if !ok {
-		return response.WriteError(c, apperr.NewAppErr("unauthorized", "User not authenticated"))
+		return response.WriteError(c, apperr.Unauthorized("unauthorized", "User not authenticated"))
 	}
 	word := c.Param("word")
 
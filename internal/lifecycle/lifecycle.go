@@ -0,0 +1,63 @@
+// Package lifecycle tracks background goroutines so the server can wait for
+// them before exiting.
+package lifecycle
+
+import (
+	"context"
+	"sync"
+)
+
+// Group runs background work that must finish before shutdown completes.
+type Group struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu     sync.Mutex
+	closed bool
+	wg     sync.WaitGroup
+}
+
+func New() *Group {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Group{ctx: ctx, cancel: cancel}
+}
+
+// Go runs fn in a new goroutine. The context given to fn is only canceled
+// when Shutdown gives up waiting, so in-flight writes are allowed to finish.
+// Go returns false without running fn once Shutdown has been called.
+func (g *Group) Go(fn func(ctx context.Context)) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.closed {
+		return false
+	}
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		fn(g.ctx)
+	}()
+	return true
+}
+
+// Shutdown stops accepting new work and waits for running goroutines. If ctx
+// expires first their context is canceled and ctx's error is returned.
+func (g *Group) Shutdown(ctx context.Context) error {
+	g.mu.Lock()
+	g.closed = true
+	g.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		g.cancel()
+		return nil
+	case <-ctx.Done():
+		g.cancel()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,41 @@
+package lifecycle
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroup_ShutdownWaits(t *testing.T) {
+	g := New()
+	finished := false
+	assert.True(t, g.Go(func(ctx context.Context) {
+		time.Sleep(20 * time.Millisecond)
+		finished = ctx.Err() == nil
+	}))
+
+	assert.NoError(t, g.Shutdown(context.Background()))
+	assert.True(t, finished)
+	assert.False(t, g.Go(func(ctx context.Context) {}))
+}
+
+func TestGroup_ShutdownDeadline(t *testing.T) {
+	g := New()
+	canceled := make(chan struct{})
+	g.Go(func(ctx context.Context) {
+		<-ctx.Done()
+		close(canceled)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, g.Shutdown(ctx), context.DeadlineExceeded)
+
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("background goroutine was not canceled")
+	}
+}
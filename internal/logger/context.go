@@ -0,0 +1,31 @@
+package logger
+
+import (
+	"context"
+	"maps"
+)
+
+// Fields are structured key/value pairs attached to log entries.
+type Fields map[string]any
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying fields in addition to the ones
+// already stored. Loggers obtained with WithContext include them.
+func NewContext(ctx context.Context, fields Fields) context.Context {
+	merged := maps.Clone(FieldsFromContext(ctx))
+	if merged == nil {
+		merged = Fields{}
+	}
+	maps.Copy(merged, fields)
+	return context.WithValue(ctx, contextKey{}, merged)
+}
+
+// FieldsFromContext returns the fields stored in ctx.
+func FieldsFromContext(ctx context.Context) Fields {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(contextKey{}).(Fields)
+	return fields
+}
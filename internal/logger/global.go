@@ -51,3 +51,7 @@ func Fatalf(format string, v ...any) {
 func WithContext(ctx context.Context) Logger {
 	return defaultLogger.WithContext(ctx)
 }
+
+func WithFields(fields Fields) Logger {
+	return defaultLogger.WithFields(fields)
+}
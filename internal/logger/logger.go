@@ -14,4 +14,5 @@ type Logger interface {
 	Fatal(msg string)
 	Fatalf(format string, v ...any)
 	WithContext(ctx context.Context) Logger
+	WithFields(fields Fields) Logger
 }
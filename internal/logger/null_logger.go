@@ -15,3 +15,4 @@ func (l *nullLogger) Errorf(format string, v ...any)         {}
 func (l *nullLogger) Fatal(msg string)                       {}
 func (l *nullLogger) Fatalf(format string, v ...any)         {}
 func (l *nullLogger) WithContext(ctx context.Context) Logger { return l }
+func (l *nullLogger) WithFields(fields Fields) Logger        { return l }
@@ -3,12 +3,14 @@ package logger
 import (
 	"context"
 	"io"
+	"maps"
 	"os"
 	"path/filepath"
 	"shadowify/internal/config"
 	"strings"
 
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/trace"
 	"gopkg.in/natefinch/lumberjack.v2"
 )
 
@@ -23,6 +25,9 @@ var mapZerologLevel = map[string]zerolog.Level{
 type zerologAdapter struct {
 	log *zerolog.Logger
 	ctx context.Context
+	// skipFrame adjusts the reported caller. The base logger is called through
+	// the package level functions, derived loggers are called directly.
+	skipFrame int
 }
 
 func NewZerologAdapter(cfg config.LoggerConfig) Logger {
@@ -68,45 +73,70 @@ func NewZerologAdapter(cfg config.LoggerConfig) Logger {
 }
 
 func (l *zerologAdapter) Debug(msg string) {
-	l.log.Debug().Ctx(l.ctx).Msg(msg)
+	l.event(l.log.Debug()).Msg(msg)
 }
 
 func (l *zerologAdapter) Debugf(format string, v ...any) {
-	l.log.Debug().Ctx(l.ctx).Msgf(format, v...)
+	l.event(l.log.Debug()).Msgf(format, v...)
 }
 
 func (l *zerologAdapter) Info(msg string) {
-	l.log.Info().Ctx(l.ctx).Msg(msg)
+	l.event(l.log.Info()).Msg(msg)
 }
 
 func (l *zerologAdapter) Infof(format string, v ...any) {
-	l.log.Info().Ctx(l.ctx).Msgf(format, v...)
+	l.event(l.log.Info()).Msgf(format, v...)
 }
 
 func (l *zerologAdapter) Warn(msg string) {
-	l.log.Warn().Ctx(l.ctx).Msg(msg)
+	l.event(l.log.Warn()).Msg(msg)
 }
 
 func (l *zerologAdapter) Warnf(format string, v ...any) {
-	l.log.Warn().Ctx(l.ctx).Msgf(format, v...)
+	l.event(l.log.Warn()).Msgf(format, v...)
 }
 
 func (l *zerologAdapter) Error(msg string) {
-	l.log.Error().Ctx(l.ctx).Msg(msg)
+	l.event(l.log.Error()).Msg(msg)
 }
 
 func (l *zerologAdapter) Errorf(format string, v ...any) {
-	l.log.Error().Ctx(l.ctx).Msgf(format, v...)
+	l.event(l.log.Error()).Msgf(format, v...)
 }
 
 func (l *zerologAdapter) Fatal(msg string) {
-	l.log.Fatal().Ctx(l.ctx).Msg(msg)
+	l.event(l.log.Fatal()).Msg(msg)
 }
 
 func (l *zerologAdapter) Fatalf(format string, v ...any) {
-	l.log.Fatal().Ctx(l.ctx).Msgf(format, v...)
+	l.event(l.log.Fatal()).Msgf(format, v...)
 }
 
+func (l *zerologAdapter) event(e *zerolog.Event) *zerolog.Event {
+	return e.Ctx(l.ctx).CallerSkipFrame(l.skipFrame)
+}
+
+// WithContext returns a logger that adds the fields stored in ctx by
+// NewContext and the ids of the active trace span to every entry.
 func (l *zerologAdapter) WithContext(ctx context.Context) Logger {
-	return &zerologAdapter{log: l.log, ctx: ctx}
+	log := l.log
+	fields := FieldsFromContext(ctx)
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		fields = maps.Clone(fields)
+		if fields == nil {
+			fields = Fields{}
+		}
+		fields["trace_id"] = spanContext.TraceID().String()
+		fields["span_id"] = spanContext.SpanID().String()
+	}
+	if len(fields) > 0 {
+		withFields := l.log.With().Fields(map[string]any(fields)).Logger()
+		log = &withFields
+	}
+	return &zerologAdapter{log: log, ctx: ctx, skipFrame: -1}
+}
+
+func (l *zerologAdapter) WithFields(fields Fields) Logger {
+	log := l.log.With().Fields(map[string]any(fields)).Logger()
+	return &zerologAdapter{log: &log, ctx: l.ctx, skipFrame: -1}
 }
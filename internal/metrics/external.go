@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"shadowify/internal/runner"
+	"time"
+)
+
+// Runner records the latency and failures of every command run by next,
+// labelled with the tool name.
+type Runner struct {
+	next runner.Runner
+}
+
+func NewRunner(next runner.Runner) *Runner {
+	return &Runner{next: next}
+}
+
+func (r *Runner) Run(ctx context.Context, cmd runner.Command) (*runner.Result, error) {
+	start := time.Now()
+	result, err := r.next.Run(ctx, cmd)
+	observeExternalCall(cmd.Tool(), start, err != nil)
+	return result, err
+}
+
+// Transport records the latency and failures of HTTP calls to target.
+// Transport errors and 5xx responses count as failures.
+type Transport struct {
+	target string
+	next   http.RoundTripper
+}
+
+func NewTransport(target string, next http.RoundTripper) *Transport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &Transport{target: target, next: next}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	res, err := t.next.RoundTrip(req)
+	observeExternalCall(t.target, start, err != nil || res.StatusCode >= http.StatusInternalServerError)
+	return res, err
+}
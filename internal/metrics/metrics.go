@@ -0,0 +1,122 @@
+// Package metrics defines the Prometheus metrics exported on /metrics and
+// helpers to record them from HTTP handlers, the ingestion pipeline, external
+// tools and services, the database, the whisper process pool and the view
+// counter.
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "shadowify"
+
+// Registry holds every metric of the application. A dedicated registry keeps
+// metrics of imported libraries out unless they are registered explicitly.
+var Registry = prometheus.NewRegistry()
+
+var (
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Duration of HTTP requests by route and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	IngestionStageDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "ingestion",
+		Name:      "stage_duration_seconds",
+		Help:      "Duration of video ingestion stages.",
+		Buckets:   []float64{0.1, 0.5, 1, 5, 10, 30, 60, 120, 300, 600, 1200},
+	}, []string{"stage"})
+
+	IngestionStageFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "ingestion",
+		Name:      "stage_failures_total",
+		Help:      "Number of failed video ingestion stages.",
+	}, []string{"stage"})
+
+	ExternalCallDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "external",
+		Name:      "call_duration_seconds",
+		Help:      "Duration of calls to external tools and services.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 300, 900},
+	}, []string{"target"})
+
+	ExternalCallErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "external",
+		Name:      "call_errors_total",
+		Help:      "Number of failed calls to external tools and services.",
+	}, []string{"target"})
+
+	DBQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "query_duration_seconds",
+		Help:      "Duration of database queries by operation and table.",
+		Buckets:   []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5},
+	}, []string{"operation", "table"})
+
+	DBQueryErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "query_errors_total",
+		Help:      "Number of failed database queries by operation and table.",
+	}, []string{"operation", "table"})
+
+	ViewsRecorded = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "views",
+		Name:      "recorded_total",
+		Help:      "Number of video views by result: counted, duplicate, capped or dropped.",
+	}, []string{"result"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequestDuration,
+		IngestionStageDuration,
+		IngestionStageFailures,
+		ExternalCallDuration,
+		ExternalCallErrors,
+		DBQueryDuration,
+		DBQueryErrors,
+		ViewsRecorded,
+	)
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// TrackStage starts timing an ingestion stage. The returned function records
+// the duration and counts a failure when err is not nil.
+func TrackStage(stage string) func(err error) {
+	start := time.Now()
+	return func(err error) {
+		IngestionStageDuration.WithLabelValues(stage).Observe(time.Since(start).Seconds())
+		if err != nil {
+			IngestionStageFailures.WithLabelValues(stage).Inc()
+		}
+	}
+}
+
+// observeExternalCall records the outcome of a call to target.
+func observeExternalCall(target string, start time.Time, failed bool) {
+	ExternalCallDuration.WithLabelValues(target).Observe(time.Since(start).Seconds())
+	if failed {
+		ExternalCallErrors.WithLabelValues(target).Inc()
+	}
+}
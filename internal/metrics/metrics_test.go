@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"shadowify/internal/config"
+	"shadowify/internal/procpool"
+	"shadowify/internal/runner"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrackStage(t *testing.T) {
+	before := testutil.ToFloat64(IngestionStageFailures.WithLabelValues("test"))
+	TrackStage("test")(nil)
+	TrackStage("test")(errors.New("failed"))
+	assert.Equal(t, before+1, testutil.ToFloat64(IngestionStageFailures.WithLabelValues("test")))
+}
+
+func TestRunner(t *testing.T) {
+	fake := &runner.Fake{
+		Handler: func(ctx context.Context, cmd runner.Command) (*runner.Result, error) {
+			if cmd.Args[0] == "fail" {
+				return nil, &runner.Error{Tool: cmd.Tool(), ExitCode: 1}
+			}
+			return &runner.Result{}, nil
+		},
+	}
+	r := NewRunner(fake)
+	before := testutil.ToFloat64(ExternalCallErrors.WithLabelValues("metrics-tool"))
+
+	_, err := r.Run(context.Background(), runner.Command{Name: "/usr/bin/metrics-tool", Args: []string{"ok"}})
+	assert.NoError(t, err)
+	_, err = r.Run(context.Background(), runner.Command{Name: "/usr/bin/metrics-tool", Args: []string{"fail"}})
+	assert.Error(t, err)
+
+	assert.Equal(t, before+1, testutil.ToFloat64(ExternalCallErrors.WithLabelValues("metrics-tool")))
+	assert.Len(t, fake.Calls(), 2)
+}
+
+func TestTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewTransport("metrics-service", nil)}
+	before := testutil.ToFloat64(ExternalCallErrors.WithLabelValues("metrics-service"))
+	for _, path := range []string{"/ok", "/fail"} {
+		res, err := client.Get(server.URL + path)
+		require.NoError(t, err)
+		res.Body.Close()
+	}
+	assert.Equal(t, before+1, testutil.ToFloat64(ExternalCallErrors.WithLabelValues("metrics-service")))
+}
+
+func TestRegisterPool(t *testing.T) {
+	require.NoError(t, RegisterPool("test", procpool.New(config.ProcPoolConfig{MaxConcurrent: 3})))
+
+	expected := `
+# HELP shadowify_procpool_slots Maximum number of processes running at once.
+# TYPE shadowify_procpool_slots gauge
+shadowify_procpool_slots{pool="test"} 3
+`
+	assert.NoError(t, testutil.GatherAndCompare(Registry, strings.NewReader(expected), "shadowify_procpool_slots"))
+}
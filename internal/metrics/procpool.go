@@ -0,0 +1,43 @@
+package metrics
+
+import (
+	"shadowify/internal/procpool"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// poolCollector exports a snapshot of a process pool on every scrape.
+type poolCollector struct {
+	pool    *procpool.Pool
+	running *prometheus.Desc
+	queued  *prometheus.Desc
+	slots   *prometheus.Desc
+}
+
+// RegisterPool exports the usage and queue depth of pool under the given name.
+func RegisterPool(name string, pool *procpool.Pool) error {
+	labels := prometheus.Labels{"pool": name}
+	return Registry.Register(&poolCollector{
+		pool: pool,
+		running: prometheus.NewDesc(prometheus.BuildFQName(namespace, "procpool", "running"),
+			"Number of processes currently running.", nil, labels),
+		queued: prometheus.NewDesc(prometheus.BuildFQName(namespace, "procpool", "queued"),
+			"Number of jobs waiting for a free slot by priority.", []string{"priority"}, labels),
+		slots: prometheus.NewDesc(prometheus.BuildFQName(namespace, "procpool", "slots"),
+			"Maximum number of processes running at once.", nil, labels),
+	})
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.running
+	ch <- c.queued
+	ch <- c.slots
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.pool.Stats()
+	ch <- prometheus.MustNewConstMetric(c.running, prometheus.GaugeValue, float64(stats.Running))
+	ch <- prometheus.MustNewConstMetric(c.queued, prometheus.GaugeValue, float64(stats.QueuedInteractive), "interactive")
+	ch <- prometheus.MustNewConstMetric(c.queued, prometheus.GaugeValue, float64(stats.QueuedBatch), "batch")
+	ch <- prometheus.MustNewConstMetric(c.slots, prometheus.GaugeValue, float64(stats.MaxConcurrent))
+}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"shadowify/internal/apperr"
+	"shadowify/internal/response"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Admin guards operational endpoints with a shared token sent in the
+// X-Admin-Token header, or as a bearer token for scrapers such as Prometheus
+// that cannot set custom headers. Every request is rejected when no token is
+// configured.
+type Admin struct {
+	token string
+}
+
+func NewAdmin(token string) *Admin {
+	return &Admin{token: token}
+}
+
+func (a *Admin) Authenticate(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if a.token == "" {
+			return response.WriteError(c, apperr.Forbidden("forbidden", "Admin endpoints are disabled"))
+		}
+		token := c.Request().Header.Get("X-Admin-Token")
+		if bearer, ok := strings.CutPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer "); ok && token == "" {
+			token = bearer
+		}
+		if subtle.ConstantTimeCompare([]byte(token), []byte(a.token)) != 1 {
+			return response.WriteError(c, apperr.Unauthorized("unauthorized", "Invalid admin token"))
+		}
+		return next(c)
+	}
+}
@@ -16,13 +16,15 @@ func NewDevice() *Device {
 
 func (d *Device) Authenticate(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
-		c.Request().Header.Get("X-Device-ID") // Example header to identify the device
+		deviceId := c.Request().Header.Get("X-Device-ID") // Example header to identify the device
 		// Here you would typically check for a device token or similar
 		// For now, we will just set a dummy device ID in the context
 		ctx := model.NewContext(c.Request().Context(), &model.User{
-			Id: c.Request().Header.Get("X-Device-ID"), // Replace with actual device ID logic
+			Id:     deviceId, // Replace with actual device ID logic
+			Locale: c.Request().Header.Get("X-Device-Locale"),
 		})
-		logger.Infof("Device ID: %s", c.Request().Header.Get("X-Device-ID"))
+		ctx = logger.NewContext(ctx, logger.Fields{"user_id": deviceId})
+		logger.WithContext(ctx).Debug("Device authenticated")
 		c.SetRequest(c.Request().WithContext(ctx))
 		return next(c)
 	}
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"shadowify/i18n"
+	"shadowify/internal/model"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	headerAcceptLanguage  = "Accept-Language"
+	headerContentLanguage = "Content-Language"
+)
+
+// Locale negotiates the language of the response from the user preference
+// and the Accept-Language header, and stores it in the request context for
+// response.WriteError. It must run after Device so that the user is known.
+func Locale(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+		var preferred string
+		if user, ok := model.FromContext(req.Context()); ok {
+			preferred = user.Locale
+		}
+		locale := i18n.Negotiate(preferred, req.Header.Get(headerAcceptLanguage))
+
+		c.Response().Header().Add(echo.HeaderVary, headerAcceptLanguage)
+		c.Response().Header().Set(headerContentLanguage, locale)
+		c.SetRequest(req.WithContext(i18n.NewContext(req.Context(), locale)))
+		return next(c)
+	}
+}
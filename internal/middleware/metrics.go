@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"shadowify/internal/metrics"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Metrics records the duration of every request labelled by route template
+// rather than raw path to keep the number of series bounded.
+// A request that panics is recorded as a 500, the response the Recover
+// middleware registered before it writes.
+func Metrics(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		start := time.Now()
+		panicked := true
+		defer func() {
+			if panicked {
+				observeRequest(c, start, http.StatusInternalServerError)
+			}
+		}()
+		err := next(c)
+		panicked = false
+
+		status := c.Response().Status
+		var httpErr *echo.HTTPError
+		if err != nil && errors.As(err, &httpErr) {
+			status = httpErr.Code
+		} else if err != nil && !c.Response().Committed {
+			status = http.StatusInternalServerError
+		}
+		observeRequest(c, start, status)
+		return err
+	}
+}
+
+func observeRequest(c echo.Context, start time.Time, status int) {
+	route := c.Path()
+	if route == "" {
+		route = "unmatched"
+	}
+	metrics.HTTPRequestDuration.
+		WithLabelValues(c.Request().Method, route, strconv.Itoa(status)).
+		Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"shadowify/internal/model"
+	"shadowify/internal/ratelimit"
+	"shadowify/internal/response"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RateLimit throttles routes by client. Every request counts against its IP,
+// and also against its user id when the device header is sent. The header is
+// chosen by the client, so the IP limit is what a new device id cannot reset.
+type RateLimit struct {
+	limiter *ratelimit.Limiter
+}
+
+func NewRateLimit(limiter *ratelimit.Limiter) *RateLimit {
+	return &RateLimit{limiter: limiter}
+}
+
+// Limit applies policy to the route. The client keys are stored in the
+// request context so that services can count daily quotas against them.
+func (r *RateLimit) Limit(policy string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ctx := c.Request().Context()
+			keys := []string{"ip:" + c.RealIP()}
+			if user, ok := model.FromContext(ctx); ok && user.Id != "" {
+				keys = append(keys, "user:"+user.Id)
+			}
+			ctx = ratelimit.NewContext(ctx, keys...)
+			c.SetRequest(c.Request().WithContext(ctx))
+
+			for _, key := range keys {
+				if err := r.limiter.Allow(ctx, policy, key); err != nil {
+					return response.WriteError(c, err)
+				}
+			}
+			return next(c)
+		}
+	}
+}
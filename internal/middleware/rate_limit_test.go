@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"shadowify/internal/config"
+	"shadowify/internal/ratelimit"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimit(t *testing.T) {
+	cfg := config.RateLimitConfig{
+		Enabled:  true,
+		Policies: map[string]config.RateLimitPolicy{"test": {RequestsPerMinute: 1, Burst: 1}},
+	}
+	rateLimit := NewRateLimit(ratelimit.NewLimiter(cfg, ratelimit.NewMemoryStore()))
+
+	e := echo.New()
+	var keys []string
+	e.GET("/", func(c echo.Context) error {
+		keys = ratelimit.KeysFromContext(c.Request().Context())
+		return c.NoContent(http.StatusOK)
+	}, NewDevice().Authenticate, rateLimit.Limit("test"))
+
+	request := func(remoteAddr, deviceId string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-Device-ID", deviceId)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := request("10.0.0.1:1234", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, []string{"ip:10.0.0.1"}, keys)
+
+	rec = request("10.0.0.1:1234", "")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "60", rec.Header().Get(echo.HeaderRetryAfter))
+	assert.Contains(t, rec.Body.String(), `"code":"ratelimit.exceeded"`)
+
+	rec = request("10.0.0.1:1234", "device-1")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code, "a device id does not reset the IP limit")
+
+	rec = request("10.0.0.2:1234", "device-1")
+	assert.Equal(t, http.StatusOK, rec.Code, "clients are limited separately")
+	assert.Equal(t, []string{"ip:10.0.0.2", "user:device-1"}, keys)
+
+	rec = request("10.0.0.3:1234", "device-1")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code, "users are limited across IPs")
+}
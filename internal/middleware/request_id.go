@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"context"
+	"shadowify/internal/logger"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxRequestIdLength bounds client supplied ids so they cannot bloat logs.
+const maxRequestIdLength = 128
+
+type requestIdContextKey struct{}
+
+// RequestIdFromContext returns the correlation id of the current request.
+func RequestIdFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIdContextKey{}).(string)
+	return id
+}
+
+// RequestId assigns a correlation id to every request. An X-Request-ID sent
+// by the client or a proxy is kept, otherwise a new one is generated. The id
+// is echoed in the response header and attached to loggers built from the
+// request context.
+func RequestId(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		id := c.Request().Header.Get(echo.HeaderXRequestID)
+		if !validRequestId(id) {
+			id = uuid.NewString()
+		}
+		c.Response().Header().Set(echo.HeaderXRequestID, id)
+		trace.SpanFromContext(c.Request().Context()).SetAttributes(attribute.String("http.request_id", id))
+
+		ctx := context.WithValue(c.Request().Context(), requestIdContextKey{}, id)
+		ctx = logger.NewContext(ctx, logger.Fields{"request_id": id})
+		c.SetRequest(c.Request().WithContext(ctx))
+		return next(c)
+	}
+}
+
+func validRequestId(id string) bool {
+	if id == "" || len(id) > maxRequestIdLength {
+		return false
+	}
+	for _, r := range id {
+		if r < 0x21 || r > 0x7e {
+			return false
+		}
+	}
+	return true
+}
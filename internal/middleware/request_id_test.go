@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"shadowify/internal/logger"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestId(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		keep   bool
+	}{
+		{name: "generated", header: "", keep: false},
+		{name: "forwarded", header: "abc-123", keep: true},
+		{name: "too long", header: strings.Repeat("a", maxRequestIdLength+1), keep: false},
+		{name: "control characters", header: "abc\x01", keep: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set(echo.HeaderXRequestID, tt.header)
+			}
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			var fromContext string
+			var fields logger.Fields
+			err := RequestId(func(c echo.Context) error {
+				fromContext = RequestIdFromContext(c.Request().Context())
+				fields = logger.FieldsFromContext(c.Request().Context())
+				return nil
+			})(c)
+
+			assert.NoError(t, err)
+			id := rec.Header().Get(echo.HeaderXRequestID)
+			assert.NotEmpty(t, id)
+			assert.Equal(t, id, fromContext)
+			assert.Equal(t, id, fields["request_id"])
+			if tt.keep {
+				assert.Equal(t, tt.header, id)
+			} else {
+				assert.NotEqual(t, tt.header, id)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package model
+
+import "slices"
+
+// CefrLevels are the CEFR levels from easiest to hardest.
+var CefrLevels = []string{"A1", "A2", "B1", "B2", "C1", "C2"}
+
+// CefrRange returns the levels from min to max, both included. An empty bound
+// is open.
+func CefrRange(min, max string) []string {
+	from, to := 0, len(CefrLevels)-1
+	if i := slices.Index(CefrLevels, min); i >= 0 {
+		from = i
+	}
+	if i := slices.Index(CefrLevels, max); i >= 0 {
+		to = i
+	}
+	if from > to {
+		from, to = to, from
+	}
+	return CefrLevels[from : to+1]
+}
+
+// EstimateLevel returns the median of the levels counted in counts, or an
+// empty string when there are none.
+func EstimateLevel(counts map[string]int64) string {
+	var total int64
+	for _, level := range CefrLevels {
+		total += counts[level]
+	}
+	if total == 0 {
+		return ""
+	}
+	var seen int64
+	for _, level := range CefrLevels {
+		seen += counts[level]
+		if seen*2 >= total {
+			return level
+		}
+	}
+	return CefrLevels[len(CefrLevels)-1]
+}
+
+// SegmentsLevel returns the level of a video, the median level of its
+// segments.
+func SegmentsLevel(segments []*Segment) string {
+	counts := make(map[string]int64, len(CefrLevels))
+	for _, segment := range segments {
+		counts[segment.Cefr]++
+	}
+	return EstimateLevel(counts)
+}
+
+// SegmentsCefrShares returns, for each of CefrLevels, the share of segments
+// at that level or below. Segments of unknown level count as harder than any.
+func SegmentsCefrShares(segments []*Segment) []float64 {
+	if len(segments) == 0 {
+		return nil
+	}
+	shares := make([]float64, len(CefrLevels))
+	for _, segment := range segments {
+		if i := slices.Index(CefrLevels, segment.Cefr); i >= 0 {
+			for j := i; j < len(shares); j++ {
+				shares[j]++
+			}
+		}
+	}
+	for i := range shares {
+		shares[i] /= float64(len(segments))
+	}
+	return shares
+}
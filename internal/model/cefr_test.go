@@ -0,0 +1,45 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCefrRange(t *testing.T) {
+	assert.Equal(t, CefrLevels, CefrRange("", ""))
+	assert.Equal(t, []string{"B1", "B2", "C1", "C2"}, CefrRange("B1", ""))
+	assert.Equal(t, []string{"A1", "A2"}, CefrRange("", "A2"))
+	assert.Equal(t, []string{"A2", "B1"}, CefrRange("B1", "A2"))
+}
+
+func TestEstimateLevel(t *testing.T) {
+	tests := []struct {
+		name   string
+		counts map[string]int64
+		want   string
+	}{
+		{name: "unknown", counts: nil, want: ""},
+		{name: "single level", counts: map[string]int64{"B2": 3}, want: "B2"},
+		{name: "median", counts: map[string]int64{"A1": 1, "B1": 2, "C2": 1}, want: "B1"},
+		{name: "even split takes the lower level", counts: map[string]int64{"A2": 2, "B2": 2}, want: "A2"},
+		{name: "ignores other values", counts: map[string]int64{"": 10, "B1": 1}, want: "B1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, EstimateLevel(tt.counts))
+		})
+	}
+}
+
+func TestSegmentsLevel(t *testing.T) {
+	segments := []*Segment{{Cefr: "A1"}, {Cefr: "B2"}, {Cefr: "B1"}, {Cefr: ""}, {Cefr: "B1"}}
+	assert.Equal(t, "B1", SegmentsLevel(segments))
+	assert.Equal(t, "", SegmentsLevel(nil))
+}
+
+func TestSegmentsCefrShares(t *testing.T) {
+	segments := []*Segment{{Cefr: "A1"}, {Cefr: "B1"}, {Cefr: "B1"}, {Cefr: ""}}
+	assert.Equal(t, []float64{0.25, 0.25, 0.75, 0.75, 0.75, 0.75}, SegmentsCefrShares(segments))
+	assert.Nil(t, SegmentsCefrShares(nil))
+}
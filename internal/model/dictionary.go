@@ -0,0 +1,25 @@
+package model
+
+import "shadowify/internal/database"
+
+// DictionaryEntry is a word as one part of speech, imported from a dictionary
+// dataset with cmd/dictionary.
+type DictionaryEntry struct {
+	Base
+	Word string `db:"word" json:"word"`
+	// Lemma is Word normalized with nlp.Normalize, under which entries are
+	// looked up.
+	Lemma        string `db:"lemma" json:"lemma"`
+	PartOfSpeech string `db:"part_of_speech" json:"part_of_speech"`
+	// Ipa is the pronunciation in the International Phonetic Alphabet.
+	Ipa    string                                `db:"ipa" json:"ipa"`
+	Cefr   string                                `db:"cefr" json:"cefr"`
+	Senses database.JSONType[[]*DictionarySense] `db:"senses" json:"senses"`
+	// Source names the dataset the entry was imported from.
+	Source string `db:"source" json:"source"`
+}
+
+type DictionarySense struct {
+	Definition string   `json:"definition"`
+	Examples   []string `json:"examples,omitempty"`
+}
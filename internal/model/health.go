@@ -0,0 +1,56 @@
+package model
+
+import (
+	"shadowify/internal/procpool"
+	"time"
+)
+
+type HealthStatus string
+
+const (
+	HealthStatusUp   HealthStatus = "up"
+	HealthStatusDown HealthStatus = "down"
+)
+
+// HealthCheck is the outcome of probing a single dependency.
+type HealthCheck struct {
+	Name      string       `json:"name"`
+	Status    HealthStatus `json:"status"`
+	Version   string       `json:"version,omitempty"`
+	LatencyMs int64        `json:"latency_ms"`
+	Error     string       `json:"error,omitempty"`
+}
+
+type HealthReport struct {
+	Status    HealthStatus   `json:"status"`
+	CheckedAt time.Time      `json:"checked_at"`
+	Checks    []*HealthCheck `json:"checks"`
+}
+
+// Summary returns a copy of the report without versions and error details,
+// which is safe to expose without authentication.
+func (r *HealthReport) Summary() *HealthReport {
+	summary := &HealthReport{
+		Status:    r.Status,
+		CheckedAt: r.CheckedAt,
+		Checks:    make([]*HealthCheck, len(r.Checks)),
+	}
+	for i, check := range r.Checks {
+		summary.Checks[i] = &HealthCheck{
+			Name:      check.Name,
+			Status:    check.Status,
+			LatencyMs: check.LatencyMs,
+		}
+	}
+	return summary
+}
+
+// Diagnostics is the detailed view shown to administrators.
+type Diagnostics struct {
+	*HealthReport
+	Uptime         string         `json:"uptime"`
+	GoVersion      string         `json:"go_version"`
+	Goroutines     int            `json:"goroutines"`
+	WhisperPool    procpool.Stats `json:"whisper_pool"`
+	WorkspaceUsage int64          `json:"workspace_usage"`
+}
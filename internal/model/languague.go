@@ -9,14 +9,14 @@ type Language struct {
 
 // CreateLanguageRequest represents the request body for creating a language
 type CreateLanguageRequest struct {
-	Code    string `json:"code" validate:"required"`
-	Name    string `json:"name" validate:"required"`
-	FlagURL string `json:"flag_url"`
+	Code    string `json:"code" validate:"required,language_code"`
+	Name    string `json:"name" validate:"required,max=100"`
+	FlagURL string `json:"flag_url" validate:"omitempty,url"`
 }
 
 // UpdateLanguageRequest represents the request body for updating a language
 type UpdateLanguageRequest struct {
-	Code    string `json:"code"`
-	Name    string `json:"name"`
-	FlagURL string `json:"flag_url"`
+	Code    string `json:"code" validate:"omitempty,language_code"`
+	Name    string `json:"name" validate:"max=100"`
+	FlagURL string `json:"flag_url" validate:"omitempty,url"`
 }
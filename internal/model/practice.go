@@ -0,0 +1,10 @@
+package model
+
+// PracticeAttempt is a recording the user made while shadowing, with the
+// CEFR level predicted for what they said.
+type PracticeAttempt struct {
+	Base
+	UserId    string  `db:"user_id" json:"user_id"`
+	SegmentId *string `db:"segment_id" json:"segment_id"`
+	Cefr      string  `db:"cefr" json:"cefr"`
+}
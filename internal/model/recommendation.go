@@ -0,0 +1,28 @@
+package model
+
+// LearnerProfile is what recommendations know about a user.
+type LearnerProfile struct {
+	// Level is the estimated CEFR level of the user, empty when unknown.
+	Level string
+	// Categories are the categories of the videos the user favorited, most
+	// frequent first.
+	Categories []string
+}
+
+// RecommendationReason explains why a video was recommended. It is also the
+// catalogue key of its message.
+type RecommendationReason string
+
+const (
+	ReasonLevel     RecommendationReason = "recommendation.level"
+	ReasonFavorites RecommendationReason = "recommendation.favorites"
+	ReasonPopular   RecommendationReason = "recommendation.popular"
+)
+
+type RecommendedVideo struct {
+	Video
+	Score   float64                `json:"score"`
+	Reasons []RecommendationReason `json:"reasons"`
+	// Explanation is the message of the first reason in the user's locale.
+	Explanation string `json:"explanation"`
+}
@@ -1,5 +1,7 @@
 package model
 
+import "shadowify/internal/pagination"
+
 type Segment struct {
 	Base
 	VideoId  string  `db:"video_id" json:"video_id"`
@@ -8,3 +10,30 @@ type Segment struct {
 	Content  string  `db:"content" json:"content"`
 	Cefr     string  `db:"cefr" json:"cefr"`
 }
+
+// TranscriptFilter searches the segments of all videos. Results are paged by
+// video. Cefr only matches the segments of that level.
+type TranscriptFilter struct {
+	pagination.Pagination
+
+	Q        string  `json:"q" query:"q" validate:"required,max=200"`
+	Cefr     string  `json:"cefr" query:"cefr" validate:"omitempty,cefr"`
+	Category *string `json:"category" query:"category"`
+}
+
+// SegmentMatch is a segment matching a transcript search.
+type SegmentMatch struct {
+	Segment
+	// Snippet is the content with the matched words wrapped in <mark> tags.
+	Snippet string `json:"snippet"`
+}
+
+// TranscriptMatch groups the best matching segments of a video, in the order
+// they are spoken.
+type TranscriptMatch struct {
+	Video    *Video          `json:"video"`
+	Segments []*SegmentMatch `json:"segments"`
+	// MatchCount is the number of matching segments, which can be more than
+	// the segments returned.
+	MatchCount int64 `json:"match_count"`
+}
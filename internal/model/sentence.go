@@ -11,8 +11,8 @@ type Sentence struct {
 }
 
 type SentenceCreateRequest struct {
-	SegmentId string `json:"segment_id" binding:"required"`
-	MeaningEN string `json:"meaning_en" binding:"required"`
+	SegmentId string `json:"segment_id" validate:"required,uuid"`
+	MeaningEN string `json:"meaning_en" validate:"required,max=500"`
 }
 
 type SentenceFilter struct {
@@ -1,7 +1,20 @@
 package model
 
+import (
+	"io"
+	"shadowify/internal/apperr"
+)
+
+// AudioInput carries an uploaded recording either as base64 in JSON or as a
+// stream read from a multipart part or a raw audio/* request body.
+type AudioInput struct {
+	AudioBase64 string    `json:"audio_base64" validate:"required,base64,audio_size"`
+	Audio       io.Reader `json:"-"`
+	ContentType string    `json:"-"`
+}
+
 type TranscribeInput struct {
-	AudioBase64 string `json:"audio_base64"`
+	AudioInput
 }
 
 type TranscribeOutput struct {
@@ -9,7 +22,14 @@ type TranscribeOutput struct {
 }
 
 type EvaluateInput struct {
-	AudioBase64 string `json:"audio_base64"`
+	AudioInput
+	PracticeTarget
+}
+
+// PracticeTarget is the segment a recording shadows, if any. It is sent as a
+// query parameter so that it goes along with every kind of audio body.
+type PracticeTarget struct {
+	SegmentId string `json:"segment_id" query:"segment_id" validate:"omitempty,uuid"`
 }
 
 type EvaluateOutput struct {
@@ -17,3 +37,46 @@ type EvaluateOutput struct {
 	MeaningEN string `json:"meaning_en"`
 	MeaningVI string `json:"meaning_vi"`
 }
+
+type STTStreamEncoding string
+
+const (
+	// STTStreamPCM is raw mono 16-bit little-endian PCM.
+	STTStreamPCM STTStreamEncoding = "pcm_s16le"
+	// STTStreamOpus is Opus in a WebM or Ogg container as produced by MediaRecorder.
+	STTStreamOpus STTStreamEncoding = "opus"
+)
+
+type STTStreamMessageType string
+
+const (
+	STTStreamStart STTStreamMessageType = "start"
+	STTStreamStop  STTStreamMessageType = "stop"
+)
+
+// STTStreamMessage is a control message sent by the client as a text frame.
+// Audio chunks are sent as binary frames between start and stop.
+type STTStreamMessage struct {
+	Type       STTStreamMessageType `json:"type"`
+	Encoding   STTStreamEncoding    `json:"encoding"`
+	SampleRate int                  `json:"sample_rate"`
+	// SegmentId is the segment being shadowed, if any.
+	SegmentId string `json:"segment_id"`
+}
+
+type STTStreamEventType string
+
+const (
+	STTStreamPartial STTStreamEventType = "partial"
+	STTStreamFinal   STTStreamEventType = "final"
+	STTStreamResult  STTStreamEventType = "result"
+	STTStreamError   STTStreamEventType = "error"
+)
+
+// STTStreamEvent is pushed to the client as a text frame.
+type STTStreamEvent struct {
+	Type   STTStreamEventType `json:"type"`
+	Text   string             `json:"text,omitempty"`
+	Result *EvaluateOutput    `json:"result,omitempty"`
+	Error  *apperr.AppErr     `json:"error,omitempty"`
+}
@@ -1,7 +1,7 @@
 package model
 
 type TranslateInput struct {
-	Text string `json:"text"`
+	Text string `json:"text" validate:"required,max=5000"`
 }
 
 type TranslateOutput struct {
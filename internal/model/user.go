@@ -15,4 +15,7 @@ func NewContext(ctx context.Context, user *User) context.Context {
 
 type User struct {
 	Id string `json:"id"`
+	// Locale is the language the user chose in the app, if any. It takes
+	// precedence over Accept-Language when negotiating the response locale.
+	Locale string `json:"locale,omitempty"`
 }
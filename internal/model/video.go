@@ -3,22 +3,152 @@ package model
 import (
 	"shadowify/internal/database"
 	"shadowify/internal/pagination"
+	"slices"
 )
 
 type VideoType string
 
 const (
-	VideoPopular  VideoType = "popular"
-	VideoRecent   VideoType = "recent"
+	VideoPopular VideoType = "popular"
+	// VideoTrending orders videos by their views in the last TrendingDays.
+	VideoTrending VideoType = "trending"
+	// VideoRecent lists the videos the user watched, last watched first.
+	VideoRecent VideoType = "recent"
+	// VideoNewest orders videos by when they were added. It is the default
+	// when browsing.
+	VideoNewest   VideoType = "newest"
 	VideoFavorite VideoType = "favorite"
+	// VideoRelevance orders search results by rank. It is the default when
+	// searching.
+	VideoRelevance VideoType = "relevance"
+	// VideoRecommended orders videos by how well they suit the user, see
+	// LearnerProfile.
+	VideoRecommended VideoType = "recommended"
 )
 
 type VideoFilter struct {
 	pagination.Pagination
 
-	Q        *string   `json:"q" query:"q"`
-	Type     VideoType `json:"type" query:"type"` // "popular", "recent", "favorite"
-	Category *string   `json:"category" query:"category"`
+	Q    *string   `json:"q" query:"q"`
+	Type VideoType `json:"type" query:"type" validate:"omitempty,oneof=popular trending recent newest favorite relevance recommended"`
+	// Category is a single category, kept for older clients. Values of a
+	// multi-value filter such as Categories are sent as repeated parameters
+	// and match videos with any of them.
+	Category       *string          `json:"category" query:"category"`
+	Categories     []string         `json:"categories" query:"categories" validate:"max=20"`
+	Tags           []string         `json:"tags" query:"tags" validate:"max=20"`
+	Duration       []DurationBucket `json:"duration" query:"duration" validate:"max=3,dive,oneof=short medium long"`
+	Cefr           string           `json:"cefr" query:"cefr" validate:"omitempty,cefr"`
+	CefrMin        string           `json:"cefr_min" query:"cefr_min" validate:"omitempty,cefr"`
+	CefrMax        string           `json:"cefr_max" query:"cefr_max" validate:"omitempty,cefr"`
+	ChannelId      string           `json:"channel_id" query:"channel_id"`
+	HasTranslation *bool            `json:"has_translation" query:"has_translation"`
+	// UserId is the user whose history VideoRecent lists.
+	UserId string
+	// Facets requests the facet counts of the matching videos.
+	Facets bool `json:"facets" query:"facets"`
+}
+
+// CategoryValues returns the categories to filter by.
+func (f *VideoFilter) CategoryValues() []string {
+	if f.Category != nil && *f.Category != "" && !slices.Contains(f.Categories, *f.Category) {
+		return append(slices.Clone(f.Categories), *f.Category)
+	}
+	return f.Categories
+}
+
+// CefrValues returns the levels to filter by, or nil for any level.
+func (f *VideoFilter) CefrValues() []string {
+	switch {
+	case f.Cefr != "":
+		return []string{f.Cefr}
+	case f.CefrMin != "" || f.CefrMax != "":
+		return CefrRange(f.CefrMin, f.CefrMax)
+	default:
+		return nil
+	}
+}
+
+// Facet fields, also used to leave the filter of a facet out of its own
+// counts so that the other values of a field stay selectable.
+const (
+	FacetCategories     = "categories"
+	FacetTags           = "tags"
+	FacetDuration       = "duration"
+	FacetCefr           = "cefr"
+	FacetChannels       = "channels"
+	FacetHasTranslation = "has_translation"
+)
+
+// DurationBucket groups videos by length like YouTube's own filter.
+type DurationBucket string
+
+const (
+	DurationShort  DurationBucket = "short"
+	DurationMedium DurationBucket = "medium"
+	DurationLong   DurationBucket = "long"
+)
+
+// DurationBuckets are the buckets from shortest to longest.
+var DurationBuckets = []DurationBucket{DurationShort, DurationMedium, DurationLong}
+
+// Range returns the bounds of the bucket in seconds. The minimum is included,
+// the maximum excluded and zero when unbounded.
+func (b DurationBucket) Range() (int32, int32) {
+	switch b {
+	case DurationShort:
+		return 0, 4 * 60
+	case DurationMedium:
+		return 4 * 60, 20 * 60
+	default:
+		return 20 * 60, 0
+	}
+}
+
+// BucketOf returns the bucket of a video lasting duration seconds.
+func BucketOf(duration int32) DurationBucket {
+	for _, bucket := range DurationBuckets {
+		if _, max := bucket.Range(); max == 0 || duration < max {
+			return bucket
+		}
+	}
+	return DurationLong
+}
+
+// TranslationLanguage is the language learners read translations in. A video
+// has a translation when YouTube has subtitles in it.
+const TranslationLanguage = "vi"
+
+// SortOrder returns the order of the list: Type when it is an order,
+// otherwise relevance when searching and newest when browsing.
+func (f *VideoFilter) SortOrder(searching bool) VideoType {
+	switch f.Type {
+	case VideoPopular, VideoTrending, VideoRecent, VideoNewest:
+		return f.Type
+	case VideoRelevance, "":
+		if searching {
+			return VideoRelevance
+		}
+	}
+	return VideoNewest
+}
+
+// VideoFacets counts the videos matching a filter per value of a field. The
+// counts of a field ignore the filter on that field.
+type VideoFacets struct {
+	Categories     []*FacetCount `json:"categories"`
+	Tags           []*FacetCount `json:"tags"`
+	Duration       []*FacetCount `json:"duration"`
+	Cefr           []*FacetCount `json:"cefr"`
+	Channels       []*FacetCount `json:"channels"`
+	HasTranslation []*FacetCount `json:"has_translation"`
+}
+
+type FacetCount struct {
+	Value string `json:"value"`
+	// Label is the display name of the value when it is an id.
+	Label string `json:"label,omitempty"`
+	Count int64  `json:"count"`
 }
 
 type FavoriteVideoFilter struct {
@@ -40,9 +170,26 @@ type Video struct {
 	Thumbnail      string                      `db:"thumbnail" json:"thumbnail"`
 	Tags           database.JSONType[[]string] `db:"tags" json:"tags"`
 	Categories     database.JSONType[[]string] `db:"categories" json:"categories"`
+	Channel        string                      `db:"channel" json:"channel"`
+	ChannelId      string                      `db:"channel_id" json:"channel_id"`
+	// SubtitleLanguages are the languages of the subtitles uploaded to YouTube.
+	SubtitleLanguages database.JSONType[[]string] `db:"subtitle_languages" json:"subtitle_languages"`
+	// CefrShares are the shares of the segments at or below each of
+	// CefrLevels, see SegmentsCefrShares. Recommendations rate the difficulty
+	// of the video for a learner with them.
+	CefrShares database.JSONType[[]float64] `db:"cefr_shares" json:"-"`
+}
+
+// HasTranslation reports whether the video has subtitles in
+// TranslationLanguage.
+func (v *Video) HasTranslation() bool {
+	return slices.Contains(v.SubtitleLanguages.Data, TranslationLanguage)
 }
 
 type VideoDetail struct {
 	Video
 	IsFavorite bool `json:"is_favorite"`
+	// Comprehension is the share of the words of the video the user knows.
+	// It is missing when it cannot be computed.
+	Comprehension *Comprehension `json:"comprehension,omitempty" gorm:"-"`
 }
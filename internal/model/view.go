@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// VideoView is a view of a video by a viewer, a device or an address when
+// no device id is sent. Views by the same viewer are counted once per window.
+type VideoView struct {
+	VideoId  string    `json:"video_id"`
+	ViewerId string    `json:"viewer_id"`
+	Window   time.Time `json:"window_start"`
+}
+
+// TrendingDays is the number of days, today included, whose views rank the
+// trending videos.
+const TrendingDays = 7
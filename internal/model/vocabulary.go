@@ -0,0 +1,152 @@
+package model
+
+import "shadowify/internal/pagination"
+
+type WordStatus string
+
+const (
+	WordKnown    WordStatus = "known"
+	WordLearning WordStatus = "learning"
+	WordUnknown  WordStatus = "unknown"
+)
+
+// VocabularySource tells how the status of a word was set.
+type VocabularySource string
+
+const (
+	SourceManual    VocabularySource = "manual"
+	SourceSaved     VocabularySource = "saved"
+	SourceQuiz      VocabularySource = "quiz"
+	SourceFrequency VocabularySource = "frequency"
+)
+
+// VocabularyWord is what a user knows of a lemma.
+type VocabularyWord struct {
+	Base
+	UserId string           `db:"user_id" json:"user_id"`
+	Lemma  string           `db:"lemma" json:"lemma"`
+	Status WordStatus       `db:"status" json:"status"`
+	Source VocabularySource `db:"source" json:"source"`
+}
+
+func (VocabularyWord) TableName() string {
+	return "vocabulary"
+}
+
+type VocabularyUpdateRequest struct {
+	Status WordStatus `json:"status" validate:"required,oneof=known learning unknown"`
+}
+
+type VocabularyFilter struct {
+	pagination.Pagination
+
+	UserId string
+	Status WordStatus `json:"status" query:"status" validate:"omitempty,oneof=known learning unknown"`
+	Q      *string    `json:"q" query:"q" validate:"omitempty,max=100"`
+}
+
+// VocabularySeedRequest marks the most frequent lemmas as known, either the
+// number a learner of Level typically knows or Size.
+type VocabularySeedRequest struct {
+	Level string `json:"level" validate:"omitempty,cefr"`
+	Size  int    `json:"size" validate:"omitempty,min=1,max=20000"`
+}
+
+type VocabularySeedResult struct {
+	// Size is the number of most frequent lemmas considered known.
+	Size int `json:"size"`
+	// Level is the CEFR level matching Size.
+	Level string `json:"level"`
+	// Seeded is the number of lemmas added to the vocabulary. Lemmas that
+	// already had a status keep it.
+	Seeded int64 `json:"seeded"`
+}
+
+// QuizWord is a lemma the learner is asked about in the placement quiz.
+type QuizWord struct {
+	Lemma string `json:"lemma"`
+}
+
+type QuizAnswer struct {
+	Lemma string `json:"lemma" validate:"required,max=100"`
+	Known bool   `json:"known"`
+}
+
+type VocabularyQuizRequest struct {
+	Answers []QuizAnswer `json:"answers" validate:"required,min=1,max=100,dive"`
+}
+
+// Comprehension is the share of the unique lemmas of a video the user knows.
+type Comprehension struct {
+	Known   int64   `json:"known"`
+	Total   int64   `json:"total"`
+	Percent float64 `json:"percent"`
+}
+
+// ComprehensionTarget is the share of known words that makes a video
+// comfortable to follow.
+const ComprehensionTarget = 95
+
+// FrequencyBands are the upper frequency ranks of the bands the placement quiz
+// samples words from.
+var FrequencyBands = []int{500, 1000, 2000, 4000, 8000, 16000}
+
+// vocabularySizes is the number of most frequent lemmas a learner typically
+// knows at each CEFR level.
+var vocabularySizes = map[string]int{
+	"A1": 500,
+	"A2": 1000,
+	"B1": 2000,
+	"B2": 4000,
+	"C1": 8000,
+	"C2": 16000,
+}
+
+// VocabularySize returns the number of most frequent lemmas a learner of
+// level typically knows.
+func VocabularySize(level string) int {
+	return vocabularySizes[level]
+}
+
+// VocabularyLevel returns the highest CEFR level whose vocabulary size is
+// covered by size, or an empty string below A1.
+func VocabularyLevel(size int) string {
+	level := ""
+	for _, l := range CefrLevels {
+		if size >= vocabularySizes[l] {
+			level = l
+		}
+	}
+	return level
+}
+
+// EstimateVocabularySize estimates how many of the most frequent lemmas a
+// learner knows from the quiz answers given for each band of FrequencyBands.
+// The known share of a band applies to all its lemmas; bands without answers
+// count as unknown.
+func EstimateVocabularySize(known, answered []int) int {
+	size, from := 0.0, 0
+	for i, to := range FrequencyBands {
+		if i < len(answered) && answered[i] > 0 {
+			size += float64(known[i]) / float64(answered[i]) * float64(to-from)
+		}
+		from = to
+	}
+	return int(size + 0.5)
+}
+
+// VideoLemma counts the occurrences of a lemma in the transcript of a video.
+type VideoLemma struct {
+	VideoId     string `db:"video_id" json:"video_id"`
+	Lemma       string `db:"lemma" json:"lemma"`
+	Occurrences int    `db:"occurrences" json:"occurrences"`
+}
+
+// WordFrequency is the rank of a lemma in a corpus frequency list, 1 being
+// the most frequent. The frequency bands and vocabulary sizes are ranks in
+// this list.
+type WordFrequency struct {
+	Lemma string `db:"lemma" json:"lemma"`
+	Rank  int    `db:"rank" json:"rank"`
+	Count int64  `db:"count" json:"count"`
+}
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVocabularyLevel(t *testing.T) {
+	assert.Equal(t, "", VocabularyLevel(100))
+	assert.Equal(t, "A1", VocabularyLevel(VocabularySize("A1")))
+	assert.Equal(t, "B1", VocabularyLevel(3000))
+	assert.Equal(t, "C2", VocabularyLevel(50000))
+}
+
+func TestEstimateVocabularySize(t *testing.T) {
+	tests := []struct {
+		name     string
+		known    []int
+		answered []int
+		want     int
+	}{
+		{name: "no answers", known: nil, answered: nil, want: 0},
+		{name: "knows the first bands", known: []int{5, 5, 0, 0, 0, 0}, answered: []int{5, 5, 5, 5, 5, 5}, want: 1000},
+		{name: "partial band", known: []int{5, 5, 2}, answered: []int{5, 5, 4}, want: 1500},
+		{name: "unanswered band", known: []int{5, 0, 5}, answered: []int{5, 0, 5}, want: 1500},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, EstimateVocabularySize(tt.known, tt.answered))
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package model
+
+import "shadowify/internal/pagination"
+
+// WatchProgress is where a user stopped in a video.
+type WatchProgress struct {
+	Base
+	UserId      string  `db:"user_id" json:"user_id"`
+	VideoId     string  `db:"video_id" json:"video_id"`
+	SegmentId   *string `db:"segment_id" json:"segment_id"`
+	PositionSec float32 `db:"position_sec" json:"position_sec"`
+	Completed   bool    `db:"completed" json:"completed"`
+}
+
+func (WatchProgress) TableName() string {
+	return "watch_progress"
+}
+
+type WatchProgressRequest struct {
+	SegmentId   string  `json:"segment_id" validate:"omitempty,uuid"`
+	PositionSec float32 `json:"position_sec" validate:"min=0"`
+	Completed   bool    `json:"completed"`
+}
+
+// WatchedVideo is a video with the progress of the user in it.
+type WatchedVideo struct {
+	Video
+	Progress *WatchProgress `json:"progress"`
+}
+
+type ContinueWatchingFilter struct {
+	pagination.Pagination
+
+	UserId string
+}
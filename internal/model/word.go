@@ -6,13 +6,19 @@ type Word struct {
 	Base
 	MeaningVI string `db:"meaning_vi" json:"meaning_vi"`
 	MeaningEN string `db:"meaning_en" json:"meaning_en"`
+	// Lemma is MeaningEN normalized with nlp.Normalize, under which the
+	// inflected forms of a word are the same saved word.
+	Lemma     string `db:"lemma" json:"lemma"`
 	UserId    string `db:"user_id" json:"user_id"`
 	SegmentId string `db:"segment_id" json:"segment_id"`
+	// Dictionary holds the dictionary entries of the lemma. It is missing when
+	// the dictionary has none or cannot be read.
+	Dictionary []*DictionaryEntry `json:"dictionary,omitempty" gorm:"-"`
 }
 
 type WordCreateRequest struct {
-	MeaningEN string `json:"meaning_en" binding:"required"`
-	SegmentId string `json:"segment_id"`
+	MeaningEN string `json:"meaning_en" validate:"required,max=200"`
+	SegmentId string `json:"segment_id" validate:"omitempty,uuid"`
 }
 
 type WordFilter struct {
@@ -1,5 +1,10 @@
 package model
 
+import (
+	"encoding/json"
+	"slices"
+)
+
 type YoutubeMetadata struct {
 	Id             string   `json:"id"`
 	Description    string   `json:"description"`
@@ -10,4 +15,19 @@ type YoutubeMetadata struct {
 	Thumbnail      string   `json:"thumbnail"`
 	Tags           []string `json:"tags"`
 	Categories     []string `json:"categories"`
+	Channel        string   `json:"channel"`
+	ChannelId      string   `json:"channel_id"`
+	// Subtitles maps the language of each uploaded subtitle track to its
+	// formats, automatic captions are not included.
+	Subtitles map[string]json.RawMessage `json:"subtitles"`
+}
+
+// SubtitleLanguages returns the languages of the uploaded subtitles, sorted.
+func (m *YoutubeMetadata) SubtitleLanguages() []string {
+	languages := make([]string, 0, len(m.Subtitles))
+	for language := range m.Subtitles {
+		languages = append(languages, language)
+	}
+	slices.Sort(languages)
+	return languages
 }
@@ -0,0 +1,155 @@
+package nlp
+
+import (
+	"bufio"
+	_ "embed"
+	"strings"
+)
+
+//go:embed lemmas.txt
+var lemmaData string
+
+// dictionary maps irregular forms and known base words to their lemma.
+var dictionary = parseLemmas(lemmaData)
+
+func parseLemmas(data string) map[string]string {
+	lemmas := make(map[string]string)
+	forms := make(map[string]string)
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		lemmas[fields[0]] = fields[0]
+		for _, form := range fields[1:] {
+			forms[form] = fields[0]
+		}
+	}
+	// A lemma is its own lemma even when it is also a form of another, as "lay"
+	// is of "lie".
+	for form, lemma := range forms {
+		if _, ok := lemmas[form]; !ok {
+			lemmas[form] = lemma
+		}
+	}
+	return lemmas
+}
+
+// Lemmatize returns the lemma of a lowercase word, e.g. "run" for "running"
+// and "child" for "children". Words it does not recognize as inflected are
+// returned as is.
+func Lemmatize(word string) string {
+	if lemma, ok := dictionary[word]; ok {
+		return lemma
+	}
+	if len(word) <= 3 || !isASCIIWord(word) {
+		return word
+	}
+	switch {
+	case strings.HasSuffix(word, "ies") && len(word) > 4,
+		strings.HasSuffix(word, "ied") && len(word) > 4:
+		return word[:len(word)-3] + "y"
+	case strings.HasSuffix(word, "ing"):
+		if stem := word[:len(word)-3]; len(stem) >= 2 && hasVowel(stem) {
+			return restoreStem(stem)
+		}
+	case strings.HasSuffix(word, "eed"):
+		// agreed, but not need, which is in the dictionary
+		return word[:len(word)-1]
+	case strings.HasSuffix(word, "ed"):
+		stem := word[:len(word)-2]
+		if len(stem) < 2 || !hasVowel(stem) {
+			break
+		}
+		// No verb stem ends in a consonant followed by r, as in hatred,
+		// unless it dropped the e of centre
+		if n := len(stem); stem[n-1] == 'r' && !isVowel(stem[n-2]) && stem[n-2] != 'r' {
+			if _, ok := dictionary[stem+"e"]; !ok {
+				return word
+			}
+		}
+		return restoreStem(stem)
+	case strings.HasSuffix(word, "oes"):
+		// Only the nouns in the dictionary add "es" after o: heroes, but
+		// canoes and shoes
+		if _, ok := dictionary[word[:len(word)-2]]; ok {
+			return word[:len(word)-2]
+		}
+		return word[:len(word)-1]
+	case strings.HasSuffix(word, "sses"), strings.HasSuffix(word, "xes"),
+		strings.HasSuffix(word, "ches"), strings.HasSuffix(word, "shes"):
+		// aches, niches
+		if _, ok := dictionary[word[:len(word)-1]]; ok {
+			return word[:len(word)-1]
+		}
+		return word[:len(word)-2]
+	case strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") &&
+		!strings.HasSuffix(word, "us") && !strings.HasSuffix(word, "is"):
+		return word[:len(word)-1]
+	}
+	return word
+}
+
+// restoreStem returns the base form of what is left of a word after removing
+// "ing" or "ed": "stop" for "stopp", "make" for "mak".
+func restoreStem(stem string) string {
+	if _, ok := dictionary[stem]; ok {
+		return stem
+	}
+	if _, ok := dictionary[stem+"e"]; ok {
+		return stem + "e"
+	}
+	n := len(stem)
+	last, prev := stem[n-1], stem[n-2]
+	if last == prev {
+		// travelled, signalled
+		if _, ok := dictionary[stem[:n-1]]; ok {
+			return stem[:n-1]
+		}
+	}
+	switch {
+	case n > 3 && last == prev && !strings.ContainsRune("lsz", rune(last)) && !isVowel(last):
+		return stem[:n-1]
+	case n > 4 && strings.HasSuffix(stem, "ell") && strings.ContainsAny(stem[:n-3], "aeiou"):
+		// Doubled after an unstressed e in British spelling: quarrelled,
+		// but not spelled
+		return stem[:n-1]
+	case strings.HasSuffix(stem, "e"), strings.HasSuffix(stem, "y"):
+		return stem
+	case last == 'v' || last == 'c' || last == 'z' || last == 'u':
+		return stem + "e"
+	case last == 's' && isVowel(prev):
+		// use, cause, close
+		return stem + "e"
+	case n == 3 && !isVowel(stem[0]) && isVowel(prev) && !isVowel(last) && !strings.ContainsRune("wxy", rune(last)):
+		// make, hope, vote
+		return stem + "e"
+	case n >= 4 && isVowel(prev) && !isVowel(stem[n-3]) && prev != 'e' &&
+		(strings.ContainsRune("dkr", rune(last)) || last == 't' && prev != 'i' || last == 'n' && (prev == 'i' || prev == 'o')):
+		// decide, relate, compare, store, combine, but not visit, which is in
+		// the dictionary
+		return stem + "e"
+	case n >= 3 && !isVowel(prev) && (last == 'l' && strings.ContainsRune("bcdfgkpt", rune(prev)) || last == 'g' && (prev == 'r' || prev == 'd')):
+		// handle, charge, judge
+		return stem + "e"
+	}
+	return stem
+}
+
+func isVowel(c byte) bool {
+	return strings.IndexByte("aeiou", c) >= 0
+}
+
+func hasVowel(s string) bool {
+	return strings.ContainsAny(s, "aeiouy")
+}
+
+func isASCIIWord(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 'a' || s[i] > 'z' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,28 @@
+// Package nlp splits English transcripts into tokens and reduces words to
+// their lemmas, so that search, vocabulary and scoring agree on what a word
+// is.
+//
+// The lemmatizer has no part-of-speech tagger. It looks words up in an
+// embedded dictionary of irregular forms and base words and otherwise strips
+// inflection suffixes by rule, which covers the regular forms of most words.
+package nlp
+
+import "strings"
+
+// Lemmas returns the lemmas of the words of text in order, leaving out
+// numbers and punctuation.
+func Lemmas(text string) []string {
+	var lemmas []string
+	for _, token := range Tokenize(text) {
+		if token.Kind == Word {
+			lemmas = append(lemmas, Lemmatize(token.Norm))
+		}
+	}
+	return lemmas
+}
+
+// Normalize returns the lemmas of the words of text separated by spaces, a
+// key under which the inflected forms of a word or phrase are equal.
+func Normalize(text string) string {
+	return strings.Join(Lemmas(text), " ")
+}
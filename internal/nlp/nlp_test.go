@@ -0,0 +1,108 @@
+package nlp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text  string
+		norms []string
+		kinds []TokenKind
+	}{
+		{"I can't go.", []string{"i", "can", "not", "go", "."}, []TokenKind{Word, Word, Word, Word, Punct}},
+		{"She’s won’t", []string{"she", "'s", "will", "not"}, []TokenKind{Word, Word, Word, Word}},
+		{"We've got 1,250.5 dollars", []string{"we", "have", "got", "1,250.5", "dollars"}, []TokenKind{Word, Word, Word, Number, Word}},
+		{"the 2nd well-known 'quote'", []string{"the", "2nd", "well-known", "'", "quote", "'"}, []TokenKind{Word, Number, Word, Punct, Word, Punct}},
+		{"students' rock-", []string{"students", "'", "rock", "-"}, []TokenKind{Word, Punct, Word, Punct}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			tokens := Tokenize(tt.text)
+			var norms []string
+			var kinds []TokenKind
+			for _, token := range tokens {
+				norms = append(norms, token.Norm)
+				kinds = append(kinds, token.Kind)
+				assert.Equal(t, token.Text, tt.text[token.Start:token.End])
+			}
+			assert.Equal(t, tt.norms, norms)
+			assert.Equal(t, tt.kinds, kinds)
+		})
+	}
+}
+
+func TestLemmatize(t *testing.T) {
+	tests := map[string]string{
+		"running":    "run",
+		"stopped":    "stop",
+		"making":     "make",
+		"used":       "use",
+		"decided":    "decide",
+		"danced":     "dance",
+		"played":     "play",
+		"wanted":     "want",
+		"waited":     "wait",
+		"visited":    "visit",
+		"opened":     "open",
+		"created":    "create",
+		"handled":    "handle",
+		"agreed":     "agree",
+		"studies":    "study",
+		"boxes":      "box",
+		"classes":    "class",
+		"causes":     "cause",
+		"days":       "day",
+		"ideas":      "idea",
+		"went":       "go",
+		"children":   "child",
+		"better":     "good",
+		"was":        "be",
+		"is":         "be",
+		"lay":        "lay",
+		"morning":    "morning",
+		"news":       "news",
+		"bus":        "bus",
+		"analysis":   "analysis",
+		"thinking":   "think",
+		"adding":     "add",
+		"continued":  "continue",
+		"lens":       "lens",
+		"lenses":     "lens",
+		"canoes":     "canoe",
+		"shoes":      "shoe",
+		"heroes":     "hero",
+		"echoes":     "echo",
+		"aches":      "ache",
+		"watches":    "watch",
+		"hatred":     "hatred",
+		"kindred":    "kindred",
+		"centred":    "centre",
+		"entered":    "enter",
+		"stirred":    "stir",
+		"travelled":  "travel",
+		"travelling": "travel",
+		"signalled":  "signal",
+		"quarrelled": "quarrel",
+		"spelled":    "spell",
+		"yelling":    "yell",
+		"filled":     "fill",
+	}
+	for word, lemma := range tests {
+		assert.Equal(t, lemma, Lemmatize(word), word)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	assert.Equal(t, "look up", Normalize("Looking up"))
+	assert.Equal(t, "she be not run", Normalize("She isn't running!"))
+	assert.Equal(t, "", Normalize("42 ..."))
+}
+
+func TestIsStopword(t *testing.T) {
+	assert.True(t, IsStopword("the"))
+	assert.True(t, IsStopword("'s"))
+	assert.False(t, IsStopword("apple"))
+}
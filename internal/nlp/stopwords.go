@@ -0,0 +1,40 @@
+package nlp
+
+// stopwords are function words that carry no vocabulary on their own, from
+// the common English lists, with the lemmas and clitics the tokenizer and
+// lemmatizer produce for them.
+var stopwords = setOf(
+	"a", "about", "above", "after", "again", "against", "all", "also", "am", "an", "and", "any", "are", "as", "at",
+	"be", "because", "been", "before", "being", "below", "between", "both", "but", "by",
+	"can", "could",
+	"did", "do", "does", "doing", "down", "during",
+	"each",
+	"few", "for", "from", "further",
+	"had", "has", "have", "having", "he", "her", "here", "hers", "herself", "him", "himself", "his", "how",
+	"i", "if", "in", "into", "is", "it", "its", "itself",
+	"just",
+	"may", "me", "might", "more", "most", "must", "my", "myself",
+	"no", "nor", "not", "now",
+	"of", "off", "on", "once", "only", "or", "other", "our", "ours", "ourselves", "out", "over", "own",
+	"same", "shall", "she", "should", "so", "some", "such",
+	"than", "that", "the", "their", "theirs", "them", "themselves", "then", "there", "these", "they", "this", "those", "through", "to", "too",
+	"under", "until", "up", "us",
+	"very",
+	"was", "we", "were", "what", "when", "where", "which", "while", "who", "whom", "why", "will", "with", "would",
+	"you", "your", "yours", "yourself", "yourselves",
+	"'s", "oh", "okay", "ok", "yeah", "uh", "um",
+)
+
+func setOf(words ...string) map[string]struct{} {
+	set := make(map[string]struct{}, len(words))
+	for _, word := range words {
+		set[word] = struct{}{}
+	}
+	return set
+}
+
+// IsStopword reports whether a lowercase word or lemma is a stopword.
+func IsStopword(word string) bool {
+	_, ok := stopwords[word]
+	return ok
+}
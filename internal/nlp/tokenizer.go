@@ -0,0 +1,159 @@
+package nlp
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type TokenKind int
+
+const (
+	Word TokenKind = iota
+	Number
+	Punct
+)
+
+type Token struct {
+	// Text is the token as written.
+	Text string
+	// Norm is the lowercase form of a word with straight apostrophes and
+	// contractions spelled out, e.g. "not" for "n't".
+	Norm string
+	Kind TokenKind
+	// Start and End are the byte offsets of the token in the text.
+	Start, End int
+}
+
+// contractions maps the clitics split off words to their full form. The
+// possessive and contracted "'s" cannot be told apart and stays as is.
+var contractions = map[string]string{
+	"n't": "not",
+	"'m":  "am",
+	"'re": "are",
+	"'ve": "have",
+	"'ll": "will",
+	"'d":  "would",
+	"'s":  "'s",
+}
+
+// negatedStems maps the first part of negations whose stem changes, as in
+// "won't", to their full form.
+var negatedStems = map[string]string{
+	"ca":  "can",
+	"wo":  "will",
+	"sha": "shall",
+	"ai":  "be",
+}
+
+// Tokenize splits text into words, numbers and punctuation marks. Words keep
+// inner apostrophes and hyphens, except that contractions are split off as in
+// "do" + "n't". Numbers keep their decimal and thousands separators and
+// ordinal suffixes such as "2nd".
+func Tokenize(text string) []Token {
+	var tokens []Token
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+		case unicode.IsLetter(r):
+			end := scanWord(text, i)
+			tokens = appendWord(tokens, text, i, end)
+			i = end
+		case unicode.IsDigit(r):
+			end := scanNumber(text, i)
+			tokens = append(tokens, Token{Text: text[i:end], Norm: strings.ToLower(text[i:end]), Kind: Number, Start: i, End: end})
+			i = end
+		default:
+			tokens = append(tokens, Token{Text: text[i : i+size], Norm: normalizeApostrophes(text[i : i+size]), Kind: Punct, Start: i, End: i + size})
+			i += size
+		}
+	}
+	return tokens
+}
+
+// scanWord returns the end of the word starting at start. Apostrophes and
+// hyphens belong to the word when a letter follows them.
+func scanWord(text string, start int) int {
+	i := start
+	for i < len(text) {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r) {
+			i += size
+			continue
+		}
+		if isApostrophe(r) || r == '-' {
+			if next, _ := utf8.DecodeRuneInString(text[i+size:]); unicode.IsLetter(next) {
+				i += size
+				continue
+			}
+		}
+		break
+	}
+	return i
+}
+
+// scanNumber returns the end of the number starting at start.
+func scanNumber(text string, start int) int {
+	i := start
+	for i < len(text) {
+		c := text[i]
+		switch {
+		case c >= '0' && c <= '9':
+			i++
+		case (c == '.' || c == ',') && i+1 < len(text) && text[i+1] >= '0' && text[i+1] <= '9':
+			i++
+		default:
+			// Ordinals and decades such as 2nd or 1990s
+			end := i
+			for end < len(text) && (text[end] >= 'a' && text[end] <= 'z' || text[end] >= 'A' && text[end] <= 'Z') {
+				end++
+			}
+			switch strings.ToLower(text[i:end]) {
+			case "st", "nd", "rd", "th", "s":
+				return end
+			}
+			return i
+		}
+	}
+	return i
+}
+
+// appendWord appends the word text[start:end], split from its contraction.
+func appendWord(tokens []Token, text string, start, end int) []Token {
+	word := text[start:end]
+	norm := normalizeApostrophes(strings.ToLower(word))
+	for clitic, full := range contractions {
+		if !strings.HasSuffix(norm, clitic) || len(norm) == len(clitic) {
+			continue
+		}
+		// The clitic is ASCII once normalized, so it spans as many runes in
+		// the original text.
+		split := end
+		for range utf8.RuneCountInString(clitic) {
+			_, size := utf8.DecodeLastRuneInString(text[start:split])
+			split -= size
+		}
+		stem := norm[:len(norm)-len(clitic)]
+		if clitic == "n't" {
+			if full, ok := negatedStems[stem]; ok {
+				stem = full
+			}
+		}
+		return append(tokens,
+			Token{Text: text[start:split], Norm: stem, Kind: Word, Start: start, End: split},
+			Token{Text: text[split:end], Norm: full, Kind: Word, Start: split, End: end},
+		)
+	}
+	return append(tokens, Token{Text: word, Norm: norm, Kind: Word, Start: start, End: end})
+}
+
+func isApostrophe(r rune) bool {
+	return r == '\'' || r == '’' || r == '‘'
+}
+
+// normalizeApostrophes replaces typographic apostrophes with straight ones.
+func normalizeApostrophes(s string) string {
+	return strings.NewReplacer("’", "'", "‘", "'").Replace(s)
+}
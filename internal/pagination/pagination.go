@@ -1,9 +1,34 @@
+// Package pagination pages through lists either by offset or by cursor.
+//
+// Offset pagination uses page and page_size. Every page also returns
+// next_cursor when more items follow; sending it back as cursor continues the
+// list from the last item seen (keyset pagination), which stays fast however
+// deep the client scrolls and does not skip or repeat items when new ones are
+// inserted. The total is counted in offset mode only, unless include_total is
+// set.
 package pagination
 
+import (
+	"encoding/base64"
+	"encoding/json"
+	"shadowify/internal/apperr"
+)
+
+const (
+	DefaultPageSize = 10
+	MaxPageSize     = 100
+)
+
 type Pagination struct {
-	Page     int   `json:"page" query:"page"`
-	PageSize int   `json:"page_size" query:"page_size"`
-	Total    int64 `json:"total"`
+	Page     int    `json:"page" query:"page" validate:"omitempty,min=1"`
+	PageSize int    `json:"page_size" query:"page_size" validate:"omitempty,min=1,max=100"`
+	Total    *int64 `json:"total,omitempty"`
+	// Cursor continues the list after the item it was issued for.
+	Cursor string `json:"-" query:"cursor"`
+	// IncludeTotal overrides whether the total is counted.
+	IncludeTotal *bool  `json:"-" query:"include_total"`
+	NextCursor   string `json:"next_cursor,omitempty"`
+	HasMore      bool   `json:"has_more"`
 }
 
 func NewPagination(page, pageSize int) *Pagination {
@@ -13,24 +38,97 @@ func NewPagination(page, pageSize int) *Pagination {
 	}
 }
 
+// WithTotal records the total number of items. It is ignored when counting
+// was skipped, see CountTotal.
 func (p *Pagination) WithTotal(total int64) *Pagination {
-	p.Total = total
+	if p.CountTotal() {
+		p.Total = &total
+	}
 	return p
 }
 
+// IsCursor reports whether the list continues from a cursor.
+func (p *Pagination) IsCursor() bool {
+	return p.Cursor != ""
+}
+
+// CountTotal reports whether the total should be counted. Counting is the
+// expensive part of large lists, so cursor requests skip it by default.
+func (p *Pagination) CountTotal() bool {
+	if p.IncludeTotal != nil {
+		return *p.IncludeTotal
+	}
+	return !p.IsCursor()
+}
+
+// Offset returns the number of items to skip, which is always zero when
+// continuing from a cursor. It also normalizes the page and page size
+// reported back to the client.
 func (p *Pagination) Offset() int {
+	p.PageSize = p.Limit()
+	if p.IsCursor() {
+		return 0
+	}
 	if p.Page < 1 {
 		p.Page = 1 // Default to page 1 if invalid
 	}
-	if p.PageSize < 1 || p.PageSize > 100 {
-		p.PageSize = 10 // Default page size if invalid
-	}
 	return (p.Page - 1) * p.PageSize
 }
 
+// Limit returns the page size, capped at MaxPageSize.
 func (p *Pagination) Limit() int {
-	if p.PageSize < 1 || p.PageSize > 100 {
-		return 10 // Default limit if PageSize is invalid
+	if p.PageSize < 1 {
+		return DefaultPageSize
 	}
-	return p.PageSize
+	return min(p.PageSize, MaxPageSize)
+}
+
+// FetchLimit is the number of rows to query: one more than the page size so
+// that Trim can tell whether more items follow.
+func (p *Pagination) FetchLimit() int {
+	return p.Limit() + 1
+}
+
+// Trim cuts items queried with FetchLimit to the page size and sets HasMore.
+// When more items follow and key is not nil, NextCursor is issued for the
+// key of the last item returned.
+func Trim[T any](p *Pagination, items []T, key func(T) any) []T {
+	if len(items) <= p.Limit() {
+		p.HasMore = false
+		return items
+	}
+	items = items[:p.Limit()]
+	p.HasMore = true
+	if key != nil {
+		p.NextCursor = EncodeCursor(key(items[len(items)-1]))
+	}
+	return items
+}
+
+// EncodeCursor returns an opaque cursor holding key, usually the sort columns
+// of an item.
+func EncodeCursor(key any) string {
+	data, err := json.Marshal(key)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor reads the key of the cursor into key.
+func DecodeCursor(cursor string, key any) error {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err == nil {
+		err = json.Unmarshal(data, key)
+	}
+	if err != nil {
+		return InvalidCursor().WithCause(err)
+	}
+	return nil
+}
+
+// InvalidCursor reports a cursor that was tampered with or issued for
+// another sort order.
+func InvalidCursor() *apperr.AppErr {
+	return apperr.Validation("pagination.invalid_cursor", "Invalid cursor").WithField("cursor")
 }
@@ -0,0 +1,91 @@
+package pagination
+
+import (
+	"shadowify/internal/apperr"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPagination_OffsetAndLimit(t *testing.T) {
+	tests := []struct {
+		name       string
+		pagination Pagination
+		offset     int
+		limit      int
+	}{
+		{name: "defaults", pagination: Pagination{}, offset: 0, limit: DefaultPageSize},
+		{name: "second page", pagination: Pagination{Page: 2, PageSize: 20}, offset: 20, limit: 20},
+		{name: "capped page size", pagination: Pagination{Page: 2, PageSize: 500}, offset: MaxPageSize, limit: MaxPageSize},
+		{name: "cursor ignores page", pagination: Pagination{Page: 3, PageSize: 5, Cursor: "abc"}, offset: 0, limit: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.offset, tt.pagination.Offset())
+			assert.Equal(t, tt.limit, tt.pagination.Limit())
+			assert.Equal(t, tt.limit+1, tt.pagination.FetchLimit())
+		})
+	}
+}
+
+func TestPagination_WithTotal(t *testing.T) {
+	yes, no := true, false
+
+	p := &Pagination{}
+	assert.EqualValues(t, 42, *p.WithTotal(42).Total)
+
+	p = &Pagination{Cursor: "abc"}
+	assert.Nil(t, p.WithTotal(42).Total, "cursor pages skip the total")
+
+	p = &Pagination{Cursor: "abc", IncludeTotal: &yes}
+	assert.EqualValues(t, 42, *p.WithTotal(42).Total)
+
+	p = &Pagination{IncludeTotal: &no}
+	assert.Nil(t, p.WithTotal(42).Total)
+}
+
+type testKey struct {
+	CreatedAt time.Time `json:"c"`
+	Id        string    `json:"i"`
+}
+
+func TestTrim(t *testing.T) {
+	key := func(i int) any { return testKey{Id: string(rune('a' + i))} }
+
+	p := &Pagination{PageSize: 2}
+	items := Trim(p, []int{0, 1, 2}, key)
+	assert.Equal(t, []int{0, 1}, items)
+	assert.True(t, p.HasMore)
+
+	var next testKey
+	require.NoError(t, DecodeCursor(p.NextCursor, &next))
+	assert.Equal(t, "b", next.Id)
+
+	p = &Pagination{PageSize: 2}
+	items = Trim(p, []int{0, 1}, key)
+	assert.Equal(t, []int{0, 1}, items)
+	assert.False(t, p.HasMore)
+	assert.Empty(t, p.NextCursor)
+
+	p = &Pagination{PageSize: 1}
+	Trim(p, []int{0, 1}, nil)
+	assert.True(t, p.HasMore)
+	assert.Empty(t, p.NextCursor, "no cursor without a key")
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	want := testKey{CreatedAt: time.Date(2024, 5, 1, 10, 30, 0, 123456000, time.UTC), Id: "7b0f0c1e"}
+
+	var got testKey
+	require.NoError(t, DecodeCursor(EncodeCursor(want), &got))
+	assert.True(t, want.CreatedAt.Equal(got.CreatedAt))
+	assert.Equal(t, want.Id, got.Id)
+
+	err := DecodeCursor("not a cursor!", &got)
+	require.Error(t, err)
+	assert.Equal(t, "pagination.invalid_cursor", apperr.From(err).Code)
+	assert.Equal(t, apperr.KindValidation, apperr.KindOf(err))
+}
@@ -0,0 +1,176 @@
+// Package procpool bounds how many CPU heavy processes run at once.
+//
+// Jobs acquire a slot before spawning a process and release it when the
+// process exits. When every slot is busy jobs wait in a queue per priority;
+// interactive jobs are always served before batch jobs.
+package procpool
+
+import (
+	"context"
+	"runtime"
+	"shadowify/internal/apperr"
+	"shadowify/internal/config"
+	"sync"
+	"time"
+)
+
+type Priority int
+
+const (
+	// PriorityInteractive is used for requests a user is waiting on.
+	PriorityInteractive Priority = iota
+	// PriorityBatch is used for background work such as video ingestion.
+	PriorityBatch
+
+	numPriorities = 2
+)
+
+func (p Priority) String() string {
+	if p == PriorityInteractive {
+		return "interactive"
+	}
+	return "batch"
+}
+
+const (
+	defaultQueueTimeout      = 30 * time.Second
+	defaultBatchQueueTimeout = 30 * time.Minute
+)
+
+type Stats struct {
+	MaxConcurrent     int `json:"max_concurrent"`
+	Threads           int `json:"threads"`
+	Running           int `json:"running"`
+	QueuedInteractive int `json:"queued_interactive"`
+	QueuedBatch       int `json:"queued_batch"`
+}
+
+type waiter struct {
+	ready chan struct{}
+}
+
+type Pool struct {
+	maxConcurrent int
+	threads       int
+	timeouts      [numPriorities]time.Duration
+
+	mu      sync.Mutex
+	running int
+	queues  [numPriorities][]*waiter
+}
+
+func New(cfg config.ProcPoolConfig) *Pool {
+	numCPU := runtime.NumCPU()
+	if cfg.MaxConcurrent <= 0 {
+		cfg.MaxConcurrent = max(1, numCPU/4)
+	}
+	if cfg.Threads <= 0 {
+		cfg.Threads = max(1, numCPU/cfg.MaxConcurrent)
+	}
+	if cfg.QueueTimeout <= 0 {
+		cfg.QueueTimeout = defaultQueueTimeout
+	}
+	if cfg.BatchQueueTimeout <= 0 {
+		cfg.BatchQueueTimeout = defaultBatchQueueTimeout
+	}
+	return &Pool{
+		maxConcurrent: cfg.MaxConcurrent,
+		threads:       cfg.Threads,
+		timeouts:      [numPriorities]time.Duration{cfg.QueueTimeout, cfg.BatchQueueTimeout},
+	}
+}
+
+// Threads returns how many threads a single job may use.
+func (p *Pool) Threads() int {
+	return p.threads
+}
+
+// Acquire waits for a free slot. The returned release function must be
+// called once the job is done. If no slot frees up within the queue timeout
+// of the priority an AppErr with code "procpool.queue_timeout" is returned.
+func (p *Pool) Acquire(ctx context.Context, priority Priority) (func(), error) {
+	p.mu.Lock()
+	if p.running < p.maxConcurrent && p.queuedAhead(priority) == 0 {
+		p.running++
+		p.mu.Unlock()
+		return p.release, nil
+	}
+	w := &waiter{ready: make(chan struct{})}
+	p.queues[priority] = append(p.queues[priority], w)
+	p.mu.Unlock()
+
+	timer := time.NewTimer(p.timeouts[priority])
+	defer timer.Stop()
+
+	var err error
+	select {
+	case <-w.ready:
+		return p.release, nil
+	case <-timer.C:
+		err = apperr.Unavailable("procpool.queue_timeout", "The server is busy, please try again later").
+			WithParam("priority", priority.String())
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.dequeue(priority, w) {
+		return nil, err
+	}
+	// The slot was handed over while giving up, pass it on.
+	p.releaseLocked()
+	return nil, err
+}
+
+// Stats returns a snapshot of the pool usage.
+func (p *Pool) Stats() Stats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return Stats{
+		MaxConcurrent:     p.maxConcurrent,
+		Threads:           p.threads,
+		Running:           p.running,
+		QueuedInteractive: len(p.queues[PriorityInteractive]),
+		QueuedBatch:       len(p.queues[PriorityBatch]),
+	}
+}
+
+func (p *Pool) release() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.releaseLocked()
+}
+
+// releaseLocked hands the slot to the next waiter or frees it.
+func (p *Pool) releaseLocked() {
+	for i := range p.queues {
+		if len(p.queues[i]) > 0 {
+			w := p.queues[i][0]
+			p.queues[i] = p.queues[i][1:]
+			close(w.ready)
+			return
+		}
+	}
+	p.running--
+}
+
+// queuedAhead returns how many jobs are waiting with the same or a higher priority.
+func (p *Pool) queuedAhead(priority Priority) int {
+	n := 0
+	for i := Priority(0); i <= priority; i++ {
+		n += len(p.queues[i])
+	}
+	return n
+}
+
+func (p *Pool) dequeue(priority Priority, w *waiter) bool {
+	queue := p.queues[priority]
+	for i := range queue {
+		if queue[i] == w {
+			p.queues[priority] = append(queue[:i], queue[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package procpool
+
+import (
+	"context"
+	"shadowify/internal/apperr"
+	"shadowify/internal/config"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPool_Defaults(t *testing.T) {
+	p := New(config.ProcPoolConfig{})
+	assert.GreaterOrEqual(t, p.Threads(), 1)
+	assert.GreaterOrEqual(t, p.Stats().MaxConcurrent, 1)
+}
+
+func TestPool_InteractiveBeforeBatch(t *testing.T) {
+	p := New(config.ProcPoolConfig{MaxConcurrent: 1, Threads: 1})
+
+	release, err := p.Acquire(context.Background(), PriorityBatch)
+	require.NoError(t, err)
+
+	order := make(chan Priority, 2)
+	acquire := func(priority Priority) {
+		release, err := p.Acquire(context.Background(), priority)
+		if err != nil {
+			return
+		}
+		order <- priority
+		release()
+	}
+	go acquire(PriorityBatch)
+	require.Eventually(t, func() bool { return p.Stats().QueuedBatch == 1 }, time.Second, time.Millisecond)
+	go acquire(PriorityInteractive)
+	require.Eventually(t, func() bool { return p.Stats().QueuedInteractive == 1 }, time.Second, time.Millisecond)
+
+	release()
+	assert.Equal(t, PriorityInteractive, <-order)
+	assert.Equal(t, PriorityBatch, <-order)
+	assert.Eventually(t, func() bool { return p.Stats().Running == 0 }, time.Second, time.Millisecond)
+}
+
+func TestPool_QueueTimeout(t *testing.T) {
+	p := New(config.ProcPoolConfig{MaxConcurrent: 1, Threads: 1, QueueTimeout: 10 * time.Millisecond})
+
+	release, err := p.Acquire(context.Background(), PriorityInteractive)
+	require.NoError(t, err)
+	defer release()
+
+	_, err = p.Acquire(context.Background(), PriorityInteractive)
+	appErr, ok := err.(*apperr.AppErr)
+	require.True(t, ok)
+	assert.Equal(t, "procpool.queue_timeout", appErr.Code)
+	assert.Equal(t, 0, p.Stats().QueuedInteractive)
+}
+
+func TestPool_ContextCanceled(t *testing.T) {
+	p := New(config.ProcPoolConfig{MaxConcurrent: 1, Threads: 1})
+
+	release, err := p.Acquire(context.Background(), PriorityInteractive)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = p.Acquire(ctx, PriorityBatch)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	release()
+	assert.Equal(t, 0, p.Stats().Running)
+}
@@ -0,0 +1,92 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often idle buckets and past usage are dropped.
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+	limit     Limit
+}
+
+type usageKey struct {
+	key string
+	day time.Time
+}
+
+// MemoryStore keeps buckets and usage in memory. Limits are per instance and
+// reset on restart.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	usage     map[usageKey]int64
+	lastSweep time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*bucket),
+		usage:   make(map[usageKey]int64),
+	}
+}
+
+func (s *MemoryStore) Take(ctx context.Context, key string, limit Limit, now time.Time) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), updatedAt: now}
+		s.buckets[key] = b
+	}
+	b.tokens = limit.refill(b.tokens, b.updatedAt, now)
+	b.updatedAt = now
+	b.limit = limit
+
+	if b.tokens < 1 {
+		return Result{RetryAfter: limit.retryAfter(b.tokens)}, nil
+	}
+	b.tokens--
+	return Result{Allowed: true}, nil
+}
+
+func (s *MemoryStore) AddUsage(ctx context.Context, key string, day time.Time, amount, max int64) (int64, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k := usageKey{key: key, day: day}
+	used := s.usage[k]
+	if used+amount > max {
+		return used, false, nil
+	}
+	s.usage[k] = used + amount
+	return used + amount, true, nil
+}
+
+// sweep drops buckets that have refilled completely and usage of past days.
+// It runs from Take, which every limited request goes through. The caller
+// must hold mu.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, b := range s.buckets {
+		if b.limit.refill(b.tokens, b.updatedAt, now) >= float64(b.limit.Burst) {
+			delete(s.buckets, key)
+		}
+	}
+	today := now.UTC().Truncate(24 * time.Hour)
+	for k := range s.usage {
+		if k.day.Before(today) {
+			delete(s.usage, k)
+		}
+	}
+}
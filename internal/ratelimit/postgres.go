@@ -0,0 +1,125 @@
+package ratelimit
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"shadowify/internal/logger"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PostgresStore keeps buckets and usage in the rate_limit_buckets and
+// usage_quotas tables so that every instance shares the same limits. Each
+// operation is a single upsert, which keeps concurrent requests consistent.
+type PostgresStore struct {
+	db *gorm.DB
+}
+
+func NewPostgresStore(db *gorm.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+// takeQuery refills the bucket and removes a token only when one is
+// available. No row is returned when the bucket is empty.
+const takeQuery = `
+WITH args AS (
+	SELECT CAST(@burst AS DOUBLE PRECISION) AS burst,
+		CAST(@rate AS DOUBLE PRECISION) AS rate,
+		CAST(@now AS TIMESTAMPTZ) AS at
+)
+INSERT INTO rate_limit_buckets AS b (key, tokens, updated_at)
+SELECT @key, args.burst - 1, args.at FROM args
+ON CONFLICT (key) DO UPDATE SET
+	tokens = (SELECT LEAST(args.burst, b.tokens + GREATEST(EXTRACT(EPOCH FROM args.at - b.updated_at), 0) * args.rate) - 1 FROM args),
+	updated_at = EXCLUDED.updated_at
+WHERE (SELECT LEAST(args.burst, b.tokens + GREATEST(EXTRACT(EPOCH FROM args.at - b.updated_at), 0) * args.rate) FROM args) >= 1
+RETURNING tokens`
+
+func (s *PostgresStore) Take(ctx context.Context, key string, limit Limit, now time.Time) (Result, error) {
+	args := map[string]any{"key": key, "burst": float64(limit.Burst), "rate": limit.Rate, "now": now}
+	var tokens []float64
+	if err := s.db.WithContext(ctx).Raw(takeQuery, args).Scan(&tokens).Error; err != nil {
+		return Result{}, err
+	}
+	if len(tokens) > 0 {
+		return Result{Allowed: true}, nil
+	}
+
+	var b struct {
+		Tokens    float64
+		UpdatedAt time.Time
+	}
+	err := s.db.WithContext(ctx).
+		Raw("SELECT tokens, updated_at FROM rate_limit_buckets WHERE key = ?", key).
+		Scan(&b).Error
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{RetryAfter: limit.retryAfter(limit.refill(b.Tokens, b.UpdatedAt, now))}, nil
+}
+
+// addUsageQuery adds amount unless the total would exceed max. No row is
+// returned when the quota is exhausted.
+const addUsageQuery = `
+INSERT INTO usage_quotas AS q (key, day, used)
+VALUES (@key, CAST(@day AS DATE), CAST(@amount AS BIGINT))
+ON CONFLICT (key, day) DO UPDATE SET used = q.used + EXCLUDED.used
+WHERE q.used + EXCLUDED.used <= CAST(@max AS BIGINT)
+RETURNING used`
+
+func (s *PostgresStore) AddUsage(ctx context.Context, key string, day time.Time, amount, max int64) (int64, bool, error) {
+	if amount > max {
+		used, err := s.usage(ctx, key, day)
+		return used, false, err
+	}
+
+	args := map[string]any{"key": key, "day": day, "amount": amount, "max": max}
+	var used []int64
+	if err := s.db.WithContext(ctx).Raw(addUsageQuery, args).Scan(&used).Error; err != nil {
+		return 0, false, err
+	}
+	if len(used) > 0 {
+		return used[0], true, nil
+	}
+	current, err := s.usage(ctx, key, day)
+	return current, false, err
+}
+
+func (s *PostgresStore) usage(ctx context.Context, key string, day time.Time) (int64, error) {
+	var used int64
+	err := s.db.WithContext(ctx).
+		Raw("SELECT used FROM usage_quotas WHERE key = ? AND day = ?", key, day).
+		Row().Scan(&used)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return used, err
+}
+
+// Cleanup deletes buckets idle since before and usage of days before it.
+func (s *PostgresStore) Cleanup(ctx context.Context, before time.Time) error {
+	db := s.db.WithContext(ctx)
+	if err := db.Exec("DELETE FROM rate_limit_buckets WHERE updated_at < ?", before).Error; err != nil {
+		return err
+	}
+	return db.Exec("DELETE FROM usage_quotas WHERE day < ?", before.UTC().Truncate(24*time.Hour)).Error
+}
+
+// RunJanitor deletes stale rows every hour until ctx is done.
+func (s *PostgresStore) RunJanitor(ctx context.Context) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// Buckets refill long before a day has passed
+			if err := s.Cleanup(ctx, time.Now().Add(-24*time.Hour)); err != nil {
+				logger.WithFields(logger.Fields{"error": err.Error()}).Error("Failed to clean up rate limit tables")
+			}
+		}
+	}
+}
@@ -0,0 +1,219 @@
+// Package ratelimit throttles expensive endpoints with token buckets and
+// enforces daily usage quotas per user.
+//
+// Buckets and usage counters live in a Store. The memory store suits a
+// single instance, the Postgres store shares limits between instances.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"shadowify/internal/apperr"
+	"shadowify/internal/config"
+	"shadowify/internal/logger"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	PolicySTT         = "stt"
+	PolicyTranslate   = "translate"
+	PolicyVideoCreate = "video_create"
+)
+
+var defaultPolicies = map[string]config.RateLimitPolicy{
+	PolicySTT:         {RequestsPerMinute: 10, Burst: 5},
+	PolicyTranslate:   {RequestsPerMinute: 60, Burst: 20},
+	PolicyVideoCreate: {RequestsPerMinute: 2, Burst: 3},
+}
+
+// Limit describes a token bucket.
+type Limit struct {
+	// Rate is the number of tokens added per second.
+	Rate float64
+	// Burst is the capacity of the bucket.
+	Burst int
+}
+
+// Result is the outcome of taking a token.
+type Result struct {
+	Allowed bool
+	// RetryAfter is how long until a token is available when not allowed.
+	RetryAfter time.Duration
+}
+
+// retryAfter returns how long a bucket holding tokens waits for a full token.
+func (l Limit) retryAfter(tokens float64) time.Duration {
+	if l.Rate <= 0 {
+		return 24 * time.Hour
+	}
+	return time.Duration((1 - tokens) / l.Rate * float64(time.Second))
+}
+
+// refill returns the tokens in a bucket last updated at updatedAt.
+func (l Limit) refill(tokens float64, updatedAt, now time.Time) float64 {
+	elapsed := now.Sub(updatedAt).Seconds()
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	return math.Min(float64(l.Burst), tokens+elapsed*l.Rate)
+}
+
+type Store interface {
+	// Take removes a token from the bucket identified by key.
+	Take(ctx context.Context, key string, limit Limit, now time.Time) (Result, error)
+	// AddUsage adds amount to the usage counted for key on day unless the
+	// total would exceed max. It returns the usage after the call and
+	// whether amount was added.
+	AddUsage(ctx context.Context, key string, day time.Time, amount, max int64) (int64, bool, error)
+}
+
+// NewStore creates the store selected by cfg.Store.
+func NewStore(cfg config.RateLimitConfig, db *gorm.DB) (Store, error) {
+	switch cfg.Store {
+	case "", "memory":
+		return NewMemoryStore(), nil
+	case "postgres":
+		return NewPostgresStore(db), nil
+	default:
+		return nil, fmt.Errorf("unknown rate limit store %q", cfg.Store)
+	}
+}
+
+// Limiter applies the per-route policies.
+type Limiter struct {
+	enabled  bool
+	store    Store
+	policies map[string]Limit
+	now      func() time.Time
+}
+
+func NewLimiter(cfg config.RateLimitConfig, store Store) *Limiter {
+	policies := make(map[string]Limit, len(defaultPolicies))
+	for name, policy := range defaultPolicies {
+		policies[name] = Limit{Rate: policy.RequestsPerMinute / 60, Burst: policy.Burst}
+	}
+	for name, policy := range cfg.Policies {
+		policies[name] = Limit{Rate: policy.RequestsPerMinute / 60, Burst: policy.Burst}
+	}
+	return &Limiter{
+		enabled:  cfg.Enabled,
+		store:    store,
+		policies: policies,
+		now:      time.Now,
+	}
+}
+
+// Allow takes a token for key from the bucket of policy. It returns a
+// too_many_requests error with the retry_after param in seconds when the
+// bucket is empty. Store failures are logged and the request is allowed, so
+// that an unavailable store does not take the endpoints down.
+func (l *Limiter) Allow(ctx context.Context, policy, key string) error {
+	limit, ok := l.policies[policy]
+	if !l.enabled || !ok {
+		return nil
+	}
+	result, err := l.store.Take(ctx, policy+":"+key, limit, l.now())
+	if err != nil {
+		logger.WithContext(ctx).WithFields(logger.Fields{"policy": policy, "error": err.Error()}).Error("Failed to check rate limit")
+		return nil
+	}
+	if result.Allowed {
+		return nil
+	}
+	return apperr.TooMany("ratelimit.exceeded", "Too many requests").
+		WithParam("policy", policy).
+		WithParam("retry_after", retrySeconds(result.RetryAfter))
+}
+
+type Metric string
+
+const (
+	MetricTranslationChars     Metric = "translation_chars"
+	MetricTranscriptionSeconds Metric = "transcription_seconds"
+)
+
+// Quota enforces daily usage limits. Days start at midnight UTC.
+type Quota struct {
+	enabled bool
+	store   Store
+	limits  map[Metric]int64
+	now     func() time.Time
+}
+
+func NewQuota(cfg config.RateLimitConfig, store Store) *Quota {
+	return &Quota{
+		enabled: cfg.Enabled,
+		store:   store,
+		limits: map[Metric]int64{
+			MetricTranslationChars:     cfg.Quotas.TranslationChars,
+			MetricTranscriptionSeconds: cfg.Quotas.TranscriptionSeconds,
+		},
+		now: time.Now,
+	}
+}
+
+// Check returns the exceeded error when amount would not fit in the daily
+// quota of every client key stored in ctx, without counting it. It lets
+// callers refuse work up front and Consume only once it succeeded.
+func (q *Quota) Check(ctx context.Context, metric Metric, amount int64) error {
+	return q.add(ctx, metric, amount, false)
+}
+
+// Consume counts amount against the daily quota of every client key stored
+// in ctx by the rate limit middleware. Work done outside of a limited
+// request, such as background jobs, is not counted.
+func (q *Quota) Consume(ctx context.Context, metric Metric, amount int64) error {
+	return q.add(ctx, metric, amount, true)
+}
+
+func (q *Quota) add(ctx context.Context, metric Metric, amount int64, count bool) error {
+	keys := KeysFromContext(ctx)
+	limit := q.limits[metric]
+	if !q.enabled || len(keys) == 0 || limit <= 0 || amount <= 0 {
+		return nil
+	}
+
+	now := q.now().UTC()
+	day := now.Truncate(24 * time.Hour)
+	for _, key := range keys {
+		// Adding nothing reads the current usage
+		added := int64(0)
+		if count {
+			added = amount
+		}
+		used, ok, err := q.store.AddUsage(ctx, string(metric)+":"+key, day, added, limit)
+		if err != nil {
+			logger.WithContext(ctx).WithFields(logger.Fields{"metric": metric, "error": err.Error()}).Error("Failed to count quota usage")
+			continue
+		}
+		if ok && (count || used+amount <= limit) {
+			continue
+		}
+		return apperr.TooMany("quota."+string(metric)+".exceeded", "Daily quota exceeded").
+			WithParam("limit", limit).
+			WithParam("used", used).
+			WithParam("retry_after", retrySeconds(day.Add(24*time.Hour).Sub(now)))
+	}
+	return nil
+}
+
+// retrySeconds rounds d up to whole seconds as expected by Retry-After.
+func retrySeconds(d time.Duration) int64 {
+	return max(1, int64(math.Ceil(d.Seconds())))
+}
+
+type keyContextKey struct{}
+
+// NewContext returns a copy of ctx carrying the keys that identify the
+// client, such as its IP and its user id.
+func NewContext(ctx context.Context, keys ...string) context.Context {
+	return context.WithValue(ctx, keyContextKey{}, keys)
+}
+
+func KeysFromContext(ctx context.Context) []string {
+	keys, _ := ctx.Value(keyContextKey{}).([]string)
+	return keys
+}
@@ -0,0 +1,133 @@
+package ratelimit
+
+import (
+	"context"
+	"shadowify/internal/apperr"
+	"shadowify/internal/config"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore_Take(t *testing.T) {
+	store := NewMemoryStore()
+	limit := Limit{Rate: 1, Burst: 2}
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+
+	for range limit.Burst {
+		result, err := store.Take(ctx, "key", limit, now)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+	}
+
+	result, err := store.Take(ctx, "key", limit, now)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, time.Second, result.RetryAfter)
+
+	result, err = store.Take(ctx, "other", limit, now)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed, "buckets are per key")
+
+	result, err = store.Take(ctx, "key", limit, now.Add(500*time.Millisecond))
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 500*time.Millisecond, result.RetryAfter)
+
+	result, err = store.Take(ctx, "key", limit, now.Add(time.Second))
+	require.NoError(t, err)
+	assert.True(t, result.Allowed, "the bucket refills over time")
+}
+
+func TestMemoryStore_AddUsage(t *testing.T) {
+	store := NewMemoryStore()
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+
+	used, ok, err := store.AddUsage(ctx, "key", day, 60, 100)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.EqualValues(t, 60, used)
+
+	used, ok, err = store.AddUsage(ctx, "key", day, 50, 100)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.EqualValues(t, 60, used, "rejected usage is not counted")
+
+	used, ok, err = store.AddUsage(ctx, "key", day.Add(24*time.Hour), 50, 100)
+	require.NoError(t, err)
+	assert.True(t, ok, "usage resets every day")
+	assert.EqualValues(t, 50, used)
+}
+
+func TestLimiter_Allow(t *testing.T) {
+	cfg := config.RateLimitConfig{
+		Enabled:  true,
+		Policies: map[string]config.RateLimitPolicy{PolicyTranslate: {RequestsPerMinute: 6, Burst: 1}},
+	}
+	limiter := NewLimiter(cfg, NewMemoryStore())
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	assert.NoError(t, limiter.Allow(ctx, PolicyTranslate, "user:1"))
+	err := limiter.Allow(ctx, PolicyTranslate, "user:1")
+	require.Error(t, err)
+	appErr := apperr.From(err)
+	assert.Equal(t, apperr.KindTooMany, appErr.Kind)
+	assert.Equal(t, "ratelimit.exceeded", appErr.Code)
+	assert.EqualValues(t, 10, appErr.Params["retry_after"])
+
+	assert.NoError(t, limiter.Allow(ctx, PolicySTT, "user:1"), "policies have separate buckets")
+	assert.NoError(t, limiter.Allow(ctx, "unknown", "user:1"))
+
+	disabled := NewLimiter(config.RateLimitConfig{Policies: cfg.Policies}, NewMemoryStore())
+	for range 3 {
+		assert.NoError(t, disabled.Allow(ctx, PolicyTranslate, "user:1"))
+	}
+}
+
+func TestQuota_Consume(t *testing.T) {
+	cfg := config.RateLimitConfig{
+		Enabled: true,
+		Quotas:  config.QuotaConfig{TranslationChars: 100},
+	}
+	quota := NewQuota(cfg, NewMemoryStore())
+	quota.now = func() time.Time { return time.Date(2024, 1, 1, 23, 0, 0, 0, time.UTC) }
+	ctx := NewContext(context.Background(), "user:1")
+
+	assert.NoError(t, quota.Consume(ctx, MetricTranslationChars, 80))
+	err := quota.Consume(ctx, MetricTranslationChars, 30)
+	require.Error(t, err)
+	appErr := apperr.From(err)
+	assert.Equal(t, apperr.KindTooMany, appErr.Kind)
+	assert.Equal(t, "quota.translation_chars.exceeded", appErr.Code)
+	assert.EqualValues(t, 3600, appErr.Params["retry_after"], "retry at midnight UTC")
+	assert.NoError(t, quota.Consume(ctx, MetricTranslationChars, 20))
+
+	assert.NoError(t, quota.Consume(NewContext(context.Background(), "user:2"), MetricTranslationChars, 80), "quotas are per client")
+	assert.NoError(t, quota.Consume(context.Background(), MetricTranslationChars, 1000), "work without a client is not counted")
+	assert.NoError(t, quota.Consume(ctx, MetricTranscriptionSeconds, 1000), "a zero limit disables the quota")
+}
+
+func TestQuota_Check(t *testing.T) {
+	cfg := config.RateLimitConfig{
+		Enabled: true,
+		Quotas:  config.QuotaConfig{TranslationChars: 100},
+	}
+	quota := NewQuota(cfg, NewMemoryStore())
+	ctx := NewContext(context.Background(), "ip:10.0.0.1", "user:1")
+
+	assert.NoError(t, quota.Check(ctx, MetricTranslationChars, 100))
+	assert.NoError(t, quota.Check(ctx, MetricTranslationChars, 100), "checking does not count")
+	assert.NoError(t, quota.Consume(ctx, MetricTranslationChars, 80))
+	err := quota.Check(ctx, MetricTranslationChars, 30)
+	assert.Equal(t, "quota.translation_chars.exceeded", apperr.From(err).Code)
+
+	other := NewContext(context.Background(), "ip:10.0.0.1", "user:2")
+	err = quota.Check(other, MetricTranslationChars, 30)
+	assert.Equal(t, "quota.translation_chars.exceeded", apperr.From(err).Code, "a new user id shares the quota of its IP")
+}
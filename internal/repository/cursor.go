@@ -0,0 +1,16 @@
+package repository
+
+import "time"
+
+// createdCursor is the sort key of lists ordered by creation, newest first.
+type createdCursor struct {
+	CreatedAt time.Time `json:"c"`
+	Id        string    `json:"i"`
+}
+
+// updatedCursor is the sort key of lists ordered by last update, latest
+// first.
+type updatedCursor struct {
+	UpdatedAt time.Time `json:"u"`
+	Id        string    `json:"i"`
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"shadowify/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DictionaryRepository struct {
+	db *gorm.DB
+}
+
+func NewDictionaryRepository(db *gorm.DB) *DictionaryRepository {
+	return &DictionaryRepository{db: db}
+}
+
+// SaveAll creates entries or replaces those with the same word and part of
+// speech, so that importing a newer dataset updates the dictionary.
+func (r *DictionaryRepository) SaveAll(ctx context.Context, entries []*model.DictionaryEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "word"}, {Name: "part_of_speech"}},
+			DoUpdates: clause.AssignmentColumns([]string{"lemma", "ipa", "cefr", "senses", "source", "updated_at"}),
+		}).
+		Create(entries).Error
+	if err != nil {
+		return dbError(err, "dictionary", "dictionary.save.error", "Failed to save dictionary entries")
+	}
+	return nil
+}
+
+// FindByWord returns the entries of word itself or of its lemma, those of the
+// word as written first.
+func (r *DictionaryRepository) FindByWord(ctx context.Context, word, lemma string) ([]*model.DictionaryEntry, error) {
+	var entries []*model.DictionaryEntry
+	err := r.db.WithContext(ctx).Model(&model.DictionaryEntry{}).
+		Where("word = ? OR lemma = ?", word, lemma).
+		Order(clause.OrderBy{Expression: clause.Expr{SQL: "word = ? DESC, word, part_of_speech", Vars: []any{word}}}).
+		Find(&entries).Error
+	if err != nil {
+		return nil, dbError(err, "dictionary", "dictionary.find.error", "Failed to find dictionary entries")
+	}
+	return entries, nil
+}
+
+// FindByLemmas returns the entries of the lemmas.
+func (r *DictionaryRepository) FindByLemmas(ctx context.Context, lemmas []string) ([]*model.DictionaryEntry, error) {
+	var entries []*model.DictionaryEntry
+	if len(lemmas) == 0 {
+		return entries, nil
+	}
+	err := r.db.WithContext(ctx).Model(&model.DictionaryEntry{}).
+		Where("lemma IN ?", lemmas).
+		Order("word").Order("part_of_speech").
+		Find(&entries).Error
+	if err != nil {
+		return nil, dbError(err, "dictionary", "dictionary.find.error", "Failed to find dictionary entries")
+	}
+	return entries, nil
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"shadowify/internal/apperr"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// dbError translates an error returned by gorm into an AppErr.
+//
+// Missing rows become "<entity>.not_found", unique and foreign key
+// violations "<entity>.already_exists" and "<entity>.invalid_reference", and
+// connection failures or timeouts are reported as unavailable. Any other
+// error is an internal error with the given code and message.
+func dbError(err error, entity, code, message string) *apperr.AppErr {
+	name := strings.ToUpper(entity[:1]) + entity[1:]
+	var connectErr *pgconn.ConnectError
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return apperr.NotFound(entity+".not_found", name+" not found")
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return apperr.Conflict(entity+".already_exists", name+" already exists").WithCause(err)
+	case errors.Is(err, gorm.ErrForeignKeyViolated):
+		return apperr.Validation(entity+".invalid_reference", "Referenced resource does not exist").WithCause(err)
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &connectErr):
+		return apperr.Unavailable("database.unavailable", "The database is unavailable, please try again later").WithCause(err)
+	default:
+		return apperr.Internal(code, message).WithCause(err)
+	}
+}
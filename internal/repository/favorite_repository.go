@@ -21,7 +21,7 @@ func (r *FavoriteRepository) FindByUserIdAndVideoId(ctx context.Context, userId
 	var favorite model.Favorite
 	err := r.db.WithContext(ctx).Where("user_id = ? AND video_id = ?", userId, videoId).First(&favorite).Error
 	if err != nil {
-		return nil, err
+		return nil, dbError(err, "favorite", "favorite.find.error", "Failed to find favorite")
 	}
 	return &favorite, nil
 }
@@ -29,7 +29,7 @@ func (r *FavoriteRepository) FindByUserIdAndVideoId(ctx context.Context, userId
 func (r *FavoriteRepository) Create(ctx context.Context, favorite *model.Favorite) error {
 	err := r.db.WithContext(ctx).Create(favorite).Error
 	if err != nil {
-		return err
+		return dbError(err, "favorite", "favorite.create.error", "Failed to create favorite")
 	}
 	return nil
 }
@@ -37,7 +37,27 @@ func (r *FavoriteRepository) Create(ctx context.Context, favorite *model.Favorit
 func (r *FavoriteRepository) Delete(ctx context.Context, userId string, videoId string) error {
 	err := r.db.WithContext(ctx).Where("user_id = ? AND video_id = ?", userId, videoId).Delete(&model.Favorite{}).Error
 	if err != nil {
-		return err
+		return dbError(err, "favorite", "favorite.delete.error", "Failed to delete favorite")
 	}
 	return nil
 }
+
+// TopCategories returns the categories of the videos a user favorited, most
+// frequent first.
+func (r *FavoriteRepository) TopCategories(ctx context.Context, userId string, limit int) ([]string, error) {
+	var categories []string
+	err := r.db.WithContext(ctx).
+		Table("favorites").
+		Joins("JOIN videos ON videos.id = favorites.video_id").
+		Joins("CROSS JOIN LATERAL jsonb_array_elements_text(CASE WHEN jsonb_typeof(videos.categories) = 'array' THEN videos.categories ELSE '[]'::jsonb END) AS category").
+		Where("favorites.user_id = ?", userId).
+		Group("category").
+		Order("count(*) DESC").
+		Order("category").
+		Limit(limit).
+		Pluck("category", &categories).Error
+	if err != nil {
+		return nil, dbError(err, "favorite", "favorite.categories.error", "Failed to get favorite categories")
+	}
+	return categories, nil
+}
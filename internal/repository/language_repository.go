@@ -2,7 +2,6 @@ package repository
 
 import (
 	"context"
-	"shadowify/internal/apperr"
 	"shadowify/internal/model"
 
 	"gorm.io/gorm"
@@ -21,7 +20,7 @@ func NewLanguageRepository(db *gorm.DB) *LanguageRepository {
 func (r *LanguageRepository) Create(ctx context.Context, language *model.Language) error {
 	err := r.db.WithContext(ctx).Create(language).Error
 	if err != nil {
-		return apperr.NewAppErr("language.create.error", "Failed to create language").WithCause(err)
+		return dbError(err, "language", "language.create.error", "Failed to create language")
 	}
 	return nil
 }
@@ -30,10 +29,7 @@ func (r *LanguageRepository) GetByID(ctx context.Context, id string) (*model.Lan
 	var language model.Language
 	err := r.db.WithContext(ctx).Where("id = ?", id).First(&language).Error
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, apperr.NewAppErr("language.not_found", "Language not found")
-		}
-		return nil, apperr.NewAppErr("language.get_by_id.error", "Failed to get language by ID").WithCause(err)
+		return nil, dbError(err, "language", "language.get_by_id.error", "Failed to get language by ID")
 	}
 	return &language, nil
 }
@@ -42,7 +38,7 @@ func (r *LanguageRepository) GetAll(ctx context.Context) ([]*model.Language, err
 	var languages []*model.Language
 	err := r.db.WithContext(ctx).Find(&languages).Error
 	if err != nil {
-		return nil, apperr.NewAppErr("language.get_all.error", "Failed to get all languages").WithCause(err)
+		return nil, dbError(err, "language", "language.get_all.error", "Failed to get all languages")
 	}
 	return languages, nil
 }
@@ -50,7 +46,7 @@ func (r *LanguageRepository) GetAll(ctx context.Context) ([]*model.Language, err
 func (r *LanguageRepository) Update(ctx context.Context, language *model.Language) error {
 	err := r.db.WithContext(ctx).Model(&model.Language{}).Where("id = ?", language.Id).Updates(language).Error
 	if err != nil {
-		return apperr.NewAppErr("language.update.error", "Failed to update language").WithCause(err)
+		return dbError(err, "language", "language.update.error", "Failed to update language")
 	}
 	return nil
 }
@@ -58,7 +54,7 @@ func (r *LanguageRepository) Update(ctx context.Context, language *model.Languag
 func (r *LanguageRepository) Delete(ctx context.Context, id string) error {
 	err := r.db.WithContext(ctx).Where("id = ?", id).Delete(&model.Language{}).Error
 	if err != nil {
-		return apperr.NewAppErr("language.delete.error", "Failed to delete language").WithCause(err)
+		return dbError(err, "language", "language.delete.error", "Failed to delete language")
 	}
 	return nil
 }
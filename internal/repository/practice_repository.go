@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"shadowify/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// levelSampleSize is the number of recent attempts, saved words and saved
+// sentences the level of a user is estimated from.
+const levelSampleSize = 50
+
+type PracticeRepository struct {
+	db *gorm.DB
+}
+
+func NewPracticeRepository(db *gorm.DB) *PracticeRepository {
+	return &PracticeRepository{db: db}
+}
+
+func (r *PracticeRepository) Create(ctx context.Context, attempt *model.PracticeAttempt) error {
+	if err := r.db.WithContext(ctx).Create(attempt).Error; err != nil {
+		return dbError(err, "practice_attempt", "practice.create.error", "Failed to record practice attempt")
+	}
+	return nil
+}
+
+// LevelCounts counts the CEFR levels of the latest attempts of a user and of
+// the segments their latest words and sentences were saved from.
+func (r *PracticeRepository) LevelCounts(ctx context.Context, userId string) (map[string]int64, error) {
+	var rows []struct {
+		Cefr  string
+		Count int64
+	}
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT cefr, count(*) AS count FROM (
+			(SELECT cefr FROM practice_attempts WHERE user_id = @user ORDER BY created_at DESC LIMIT @limit)
+			UNION ALL
+			(SELECT segments.cefr FROM words JOIN segments ON segments.id = words.segment_id
+			 WHERE words.user_id = @user ORDER BY words.created_at DESC LIMIT @limit)
+			UNION ALL
+			(SELECT segments.cefr FROM sentences JOIN segments ON segments.id = sentences.segment_id
+			 WHERE sentences.user_id = @user ORDER BY sentences.created_at DESC LIMIT @limit)
+		) AS levels
+		WHERE cefr <> ''
+		GROUP BY cefr`,
+		map[string]any{"user": userId, "limit": levelSampleSize},
+	).Scan(&rows).Error
+	if err != nil {
+		return nil, dbError(err, "practice_attempt", "practice.level.error", "Failed to estimate level")
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Cefr] = row.Count
+	}
+	return counts, nil
+}
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"shadowify/internal/ftsearch"
+
+	"gorm.io/gorm/clause"
+)
+
+// searchQuery returns the tsquery matching input against the search_vector
+// columns. It reports false when input holds no searchable term.
+func searchQuery(input *string) (clause.Expr, bool) {
+	if input == nil {
+		return clause.Expr{}, false
+	}
+	expression := ftsearch.BuildTsqueryExpression(*input, ftsearch.WithPrefixMatching())
+	if expression == "" {
+		return clause.Expr{}, false
+	}
+	return clause.Expr{SQL: "to_tsquery(CAST(? AS regconfig), ?)", Vars: []any{ftsearch.Config, expression}}, true
+}
+
+// meaningSearch returns the condition matching input against the
+// search_vector of words and sentences, which index meaning_en with the
+// English configuration and meaning_vi with the translation one. Each part is
+// queried with its own configuration. It reports false when input holds no
+// searchable term.
+func meaningSearch(input *string) (clause.Expr, bool) {
+	if input == nil {
+		return clause.Expr{}, false
+	}
+	expression := ftsearch.BuildTsqueryExpression(*input, ftsearch.WithPrefixMatching())
+	if expression == "" {
+		return clause.Expr{}, false
+	}
+	return clause.Expr{
+		SQL:  "(search_vector @@ to_tsquery(CAST(? AS regconfig), ?) OR search_vector @@ to_tsquery(CAST(? AS regconfig), ?))",
+		Vars: []any{ftsearch.Config, expression, ftsearch.TranslationConfig, expression},
+	}, true
+}
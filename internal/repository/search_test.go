@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"shadowify/internal/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// dryRun returns a database that renders statements without connecting, and
+// the statements rendered so far.
+func dryRun(t *testing.T) (*gorm.DB, *[]string) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	require.NoError(t, err)
+
+	var statements []string
+	capture := func(tx *gorm.DB) {
+		statements = append(statements, tx.Dialector.Explain(tx.Statement.SQL.String(), tx.Statement.Vars...))
+	}
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:capture", capture))
+	require.NoError(t, db.Callback().Row().After("gorm:row").Register("test:capture", capture))
+	return db, &statements
+}
+
+func TestWordRepository_ListByVietnameseMeaning(t *testing.T) {
+	db, statements := dryRun(t)
+	q := "xin chào"
+	_, _, err := NewWordRepository(db).List(context.Background(), &model.WordFilter{UserId: "user", Q: &q})
+	require.NoError(t, err)
+
+	require.NotEmpty(t, *statements)
+	for _, statement := range *statements {
+		assert.Contains(t, statement, "search_vector @@ to_tsquery(CAST('english' AS regconfig), 'xin:* & chào:*')")
+		assert.Contains(t, statement, "search_vector @@ to_tsquery(CAST('simple' AS regconfig), 'xin:* & chào:*')")
+	}
+}
+
+func TestSegmentRepository_SearchByLevel(t *testing.T) {
+	db, statements := dryRun(t)
+	_, _, err := NewSegmentRepository(db).Search(context.Background(), &model.TranscriptFilter{Q: "water", Cefr: "A2"})
+	require.NoError(t, err)
+
+	require.NotEmpty(t, *statements)
+	for _, statement := range *statements {
+		assert.Contains(t, statement, "segments.cefr = 'A2'")
+		assert.NotContains(t, statement, "videos.cefr")
+	}
+}
+
+func TestVideoRepository_RecommendByLevel(t *testing.T) {
+	db, statements := dryRun(t)
+	// The count would leave its statement behind for the subquery to reuse,
+	// as dry runs are not reset
+	includeTotal := false
+	filter := &model.VideoFilter{Type: model.VideoRecommended}
+	filter.IncludeTotal = &includeTotal
+	_, _, err := NewVideoRepository(db).Recommend(context.Background(), "user", &model.LearnerProfile{Level: "B1"}, filter)
+	require.NoError(t, err)
+
+	require.NotEmpty(t, *statements)
+	statement := (*statements)[len(*statements)-1]
+	assert.Contains(t, statement, "GREATEST(0, 1 - 0.5 * ABS(array_position(ARRAY['A1', 'A2', 'B1', 'B2', 'C1', 'C2'], videos.cefr) - 3)) AS level_score",
+		"videos at the level of the user score highest")
+	assert.Contains(t, statement, "COALESCE(CAST(videos.cefr_shares ->> CAST(3 AS INTEGER) AS DOUBLE PRECISION), 0) AS difficulty_score",
+		"difficulty is the share of segments up to B2")
+	assert.NotContains(t, statement, "FROM segments")
+	assert.Contains(t, statement, "ORDER BY score DESC")
+}
@@ -2,11 +2,20 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"shadowify/internal/apperr"
+	"shadowify/internal/ftsearch"
 	"shadowify/internal/model"
+	"shadowify/internal/pagination"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// MatchesPerVideo is the number of segments returned for each video found by
+// a transcript search.
+const MatchesPerVideo = 5
+
 type SegmentRepository struct {
 	db *gorm.DB
 }
@@ -16,14 +25,17 @@ func NewSegmentRepository(db *gorm.DB) *SegmentRepository {
 }
 
 func (r *SegmentRepository) Create(ctx context.Context, segments []*model.Segment) error {
-	return r.db.WithContext(ctx).Create(segments).Error
+	if err := r.db.WithContext(ctx).Create(segments).Error; err != nil {
+		return dbError(err, "segment", "segment.create.error", "Failed to create segments")
+	}
+	return nil
 }
 
 func (r *SegmentRepository) FindByVideoID(ctx context.Context, videoID string) ([]*model.Segment, error) {
 	var segments []*model.Segment
 	err := r.db.WithContext(ctx).Where("video_id = ?", videoID).Order("start_sec ASC").Find(&segments).Error
 	if err != nil {
-		return nil, err
+		return nil, dbError(err, "segment", "segment.list.error", "Failed to list segments")
 	}
 	return segments, nil
 }
@@ -32,7 +44,134 @@ func (r *SegmentRepository) FindById(ctx context.Context, id string) (*model.Seg
 	var segment model.Segment
 	err := r.db.WithContext(ctx).Where("id = ?", id).First(&segment).Error
 	if err != nil {
-		return nil, err
+		return nil, dbError(err, "segment", "segment.get.error", "Failed to get segment")
 	}
 	return &segment, nil
 }
+
+// rankCursor is the sort key of lists ordered by relevance.
+type rankCursor struct {
+	Rank float64 `json:"r"`
+	Id   string  `json:"i"`
+}
+
+// videoMatch is a video with the rank of its best matching segment.
+type videoMatch struct {
+	VideoId    string
+	SearchRank float64
+	MatchCount int64
+}
+
+// Search finds the videos whose transcript matches filter.Q, ordered by their
+// best matching segment. Each video comes with up to MatchesPerVideo of its
+// best segments and a highlighted snippet of each.
+func (r *SegmentRepository) Search(ctx context.Context, filter *model.TranscriptFilter) ([]*model.TranscriptMatch, int64, error) {
+	tsquery, ok := searchQuery(&filter.Q)
+	if !ok {
+		// Nothing to search for, only normalize the page reported back
+		filter.Offset()
+		return []*model.TranscriptMatch{}, 0, nil
+	}
+
+	var category string
+	if filter.Category != nil && *filter.Category != "" {
+		jsonVal, err := json.Marshal([]string{*filter.Category})
+		if err != nil {
+			return nil, 0, apperr.Internal("filter.encode.error", "Failed to encode category").WithCause(err)
+		}
+		category = string(jsonVal)
+	}
+	matching := func() *gorm.DB {
+		query := r.db.WithContext(ctx).Model(&model.Segment{}).
+			Joins("JOIN videos ON videos.id = segments.video_id").
+			Where("segments.search_vector @@ ?", tsquery)
+		if category != "" {
+			query = query.Where("videos.categories @> ?", category)
+		}
+		if filter.Cefr != "" {
+			query = query.Where("segments.cefr = ?", filter.Cefr)
+		}
+		return query
+	}
+
+	var total int64
+	if filter.CountTotal() {
+		if err := matching().Distinct("segments.video_id").Count(&total).Error; err != nil {
+			return nil, 0, dbError(err, "segment", "segment.search.error", "Failed to count transcript matches")
+		}
+	}
+
+	rank := clause.Expr{SQL: "max(ts_rank(segments.search_vector, ?))", Vars: []any{tsquery}}
+	query := matching().
+		Select("segments.video_id, ? AS search_rank, count(*) AS match_count", rank).
+		Group("segments.video_id")
+	offset := filter.Offset()
+	if filter.IsCursor() {
+		var cursor rankCursor
+		if err := pagination.DecodeCursor(filter.Cursor, &cursor); err != nil {
+			return nil, 0, err
+		}
+		query = query.Having("(?, segments.video_id) < (?, ?)", rank, cursor.Rank, cursor.Id)
+	}
+
+	var videoMatches []*videoMatch
+	err := query.Order("search_rank DESC").
+		Order("segments.video_id DESC").
+		Offset(offset).
+		Limit(filter.FetchLimit()).
+		Find(&videoMatches).Error
+	if err != nil {
+		return nil, 0, dbError(err, "segment", "segment.search.error", "Failed to search transcripts")
+	}
+	videoMatches = pagination.Trim(&filter.Pagination, videoMatches, func(m *videoMatch) any {
+		return rankCursor{Rank: m.SearchRank, Id: m.VideoId}
+	})
+	if len(videoMatches) == 0 {
+		return []*model.TranscriptMatch{}, total, nil
+	}
+
+	videoIds := make([]string, len(videoMatches))
+	for i, m := range videoMatches {
+		videoIds[i] = m.VideoId
+	}
+	var videos []*model.Video
+	if err := r.db.WithContext(ctx).Where("id IN ?", videoIds).Find(&videos).Error; err != nil {
+		return nil, 0, dbError(err, "segment", "segment.search.error", "Failed to get matching videos")
+	}
+
+	ranked := r.db.WithContext(ctx).Model(&model.Segment{}).
+		Select("segments.*, ts_headline(CAST(? AS regconfig), content, ?, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true') AS snippet, row_number() OVER (PARTITION BY video_id ORDER BY ts_rank(search_vector, ?) DESC, start_sec) AS match_rank",
+			ftsearch.Config, tsquery, tsquery).
+		Where("video_id IN ?", videoIds).
+		Where("search_vector @@ ?", tsquery)
+	if filter.Cefr != "" {
+		ranked = ranked.Where("cefr = ?", filter.Cefr)
+	}
+	var segments []*model.SegmentMatch
+	err = r.db.WithContext(ctx).Table("(?) AS matches", ranked).
+		Where("match_rank <= ?", MatchesPerVideo).
+		Order("start_sec ASC").
+		Find(&segments).Error
+	if err != nil {
+		return nil, 0, dbError(err, "segment", "segment.search.error", "Failed to get matching segments")
+	}
+
+	results := make(map[string]*model.TranscriptMatch, len(videoMatches))
+	for _, m := range videoMatches {
+		results[m.VideoId] = &model.TranscriptMatch{Segments: []*model.SegmentMatch{}, MatchCount: m.MatchCount}
+	}
+	for _, video := range videos {
+		results[video.Id].Video = video
+	}
+	for _, segment := range segments {
+		results[segment.VideoId].Segments = append(results[segment.VideoId].Segments, segment)
+	}
+	matches := make([]*model.TranscriptMatch, 0, len(videoMatches))
+	for _, m := range videoMatches {
+		// A video deleted in between the queries is skipped
+		if results[m.VideoId].Video != nil {
+			matches = append(matches, results[m.VideoId])
+		}
+	}
+	return matches, total, nil
+}
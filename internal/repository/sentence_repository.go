@@ -2,9 +2,8 @@ package repository
 
 import (
 	"context"
-	"shadowify/internal/apperr"
-	"shadowify/internal/ftsearch"
 	"shadowify/internal/model"
+	"shadowify/internal/pagination"
 
 	"gorm.io/gorm"
 )
@@ -17,8 +16,11 @@ func NewSentenceRepository(db *gorm.DB) *SentenceRepository {
 	return &SentenceRepository{db: db}
 }
 
-func (r *SentenceRepository) Create(sentence *model.Sentence) error {
-	return r.db.Model(&model.Sentence{}).Create(sentence).Error
+func (r *SentenceRepository) Create(ctx context.Context, sentence *model.Sentence) error {
+	if err := r.db.WithContext(ctx).Model(&model.Sentence{}).Create(sentence).Error; err != nil {
+		return dbError(err, "sentence", "sentence.create.error", "Failed to create sentence")
+	}
+	return nil
 }
 
 func (r *SentenceRepository) List(ctx context.Context, filter *model.SentenceFilter) ([]*model.Sentence, int64, error) {
@@ -27,36 +29,47 @@ func (r *SentenceRepository) List(ctx context.Context, filter *model.SentenceFil
 
 	query := r.db.WithContext(ctx).Model(&model.Sentence{}).Where("user_id = ?", filter.UserId)
 
-	if filter.Q != nil && *filter.Q != "" {
-		tsquery := gorm.Expr("to_tsquery('simple', ?)", ftsearch.BuildTsqueryExpression(*filter.Q, ftsearch.WithPrefixMatching()))
-		ftSearch := "to_tsvector('simple', coalesce(meaning_en,'') || ' ' || coalesce(meaning_vi,'')) @@ ?"
-		query = query.Where(ftSearch, tsquery)
+	if condition, ok := meaningSearch(filter.Q); ok {
+		query = query.Where(condition)
+	}
+
+	if filter.CountTotal() {
+		if err := query.Count(&total).Error; err != nil {
+			return nil, 0, dbError(err, "sentence", "sentence.list.error", "Failed to count sentences")
+		}
 	}
 
-	if err := query.Count(&total).Error; err != nil {
-		return nil, 0, apperr.NewAppErr("sentence.list.error", "Failed to count sentences").WithCause(err)
+	offset := filter.Offset()
+	if filter.IsCursor() {
+		var cursor createdCursor
+		if err := pagination.DecodeCursor(filter.Cursor, &cursor); err != nil {
+			return nil, 0, err
+		}
+		query = query.Where("(created_at, id) < (?, ?)", cursor.CreatedAt, cursor.Id)
 	}
 
-	err := query.Order("created_at DESC").Offset(filter.Offset()).Limit(filter.Limit()).Find(&sentences).Error
+	err := query.Order("created_at DESC").Order("id DESC").Offset(offset).Limit(filter.FetchLimit()).Find(&sentences).Error
 	if err != nil {
-		return nil, 0, apperr.NewAppErr("sentence.list.error", "Failed to list sentences").WithCause(err)
+		return nil, 0, dbError(err, "sentence", "sentence.list.error", "Failed to list sentences")
 	}
 
-	return sentences, total, nil
+	return pagination.Trim(&filter.Pagination, sentences, func(s *model.Sentence) any {
+		return createdCursor{CreatedAt: s.CreatedAt, Id: s.Id}
+	}), total, nil
 }
 
 func (r *SentenceRepository) FindByUserIdAndSegmentId(ctx context.Context, userId string, segmentId string) (*model.Sentence, error) {
 	var sentence model.Sentence
 	err := r.db.WithContext(ctx).Where("user_id = ? AND segment_id = ?", userId, segmentId).First(&sentence).Error
 	if err != nil {
-		return nil, apperr.NewAppErr("sentence.find.error", "Failed to find sentence").WithCause(err)
+		return nil, dbError(err, "sentence", "sentence.find.error", "Failed to find sentence")
 	}
 	return &sentence, nil
 }
 
 func (r SentenceRepository) DeleteByUserIdAndSegmentId(ctx context.Context, userId string, segmentId string) error {
 	if err := r.db.WithContext(ctx).Where("user_id = ? AND segment_id = ?", userId, segmentId).Delete(&model.Sentence{}).Error; err != nil {
-		return apperr.NewAppErr("sentence.delete.error", "Failed to delete sentence").WithCause(err)
+		return dbError(err, "sentence", "sentence.delete.error", "Failed to delete sentence")
 	}
 	return nil
 }
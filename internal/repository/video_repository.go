@@ -3,12 +3,17 @@ package repository
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"shadowify/internal/apperr"
-	"shadowify/internal/ftsearch"
-	"shadowify/internal/logger"
 	"shadowify/internal/model"
+	"shadowify/internal/pagination"
+	"slices"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type VideoRepository struct {
@@ -22,100 +27,456 @@ func NewVideoRepository(db *gorm.DB) *VideoRepository {
 func (r *VideoRepository) Create(ctx context.Context, video *model.Video, segments []*model.Segment) error {
 	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		if err := tx.Model(&model.Video{}).Create(video).Error; err != nil {
-			return apperr.NewAppErr("video.create.error", "Failed to create video").WithCause(err)
+			return dbError(err, "video", "video.create.error", "Failed to create video")
 		}
 
 		for _, segment := range segments {
 			segment.VideoId = video.Id
 		}
 		if err := tx.Model(&model.Segment{}).Create(segments).Error; err != nil {
-			return apperr.NewAppErr("video.create.error", "Failed to create video segments").WithCause(err)
+			return dbError(err, "video", "video.create.error", "Failed to create video segments")
 		}
 		return nil
 	})
 	if err != nil {
-		return apperr.NewAppErr("video.create.error", "Failed to create video with segments").WithCause(err)
+		// Errors returned from the transaction are already translated
+		var appErr *apperr.AppErr
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return dbError(err, "video", "video.create.error", "Failed to create video with segments")
 	}
 
 	return nil
 }
 
-func (r *VideoRepository) IncrementViewCount(ctx context.Context, videoId string) error {
-	return r.db.WithContext(ctx).Model(&model.Video{}).Where("id = ?", videoId).UpdateColumn("view_count", gorm.Expr("view_count + 1")).Error
-}
-
 func (r *VideoRepository) GetById(ctx context.Context, id, userId string) (*model.VideoDetail, error) {
 	var video model.VideoDetail
-	if err := r.db.WithContext(ctx).Model(&model.Video{}).Select("*, (SELECT 1 FROM favorites WHERE user_id = ? AND video_id = videos.id) AS is_favorite", userId).Where("id = ?", id).Scan(&video).Error; err != nil {
-		return nil, apperr.NewAppErr("video.get.error", "Failed to get video by ID").WithCause(err)
+	result := r.db.WithContext(ctx).Model(&model.Video{}).Select("*, (SELECT 1 FROM favorites WHERE user_id = ? AND video_id = videos.id) AS is_favorite", userId).Where("id = ?", id).Scan(&video)
+	if result.Error != nil {
+		return nil, dbError(result.Error, "video", "video.get.error", "Failed to get video by ID")
+	}
+	if result.RowsAffected == 0 {
+		return nil, dbError(gorm.ErrRecordNotFound, "video", "video.get.error", "Failed to get video by ID")
 	}
 	return &video, nil
 }
 
+// videoCursor is the sort key of the last video of a page.
+type videoCursor struct {
+	Sort      model.VideoType `json:"s,omitempty"`
+	Rank      float64         `json:"r,omitempty"`
+	ViewCount int64           `json:"v,omitempty"`
+	Trending  int64           `json:"t,omitempty"`
+	WatchedAt *time.Time      `json:"w,omitempty"`
+	CreatedAt time.Time       `json:"c"`
+	Id        string          `json:"i"`
+}
+
+// rankedVideo is a video with its relevance to the search query, its recent
+// views or when the user last watched it.
+type rankedVideo struct {
+	model.Video
+	SearchRank    float64   `gorm:"column:search_rank"`
+	TrendingViews int64     `gorm:"column:trending_views"`
+	WatchedAt     time.Time `gorm:"column:watched_at"`
+}
+
 func (r *VideoRepository) List(ctx context.Context, filter *model.VideoFilter) ([]*model.Video, int64, error) {
-	var videos []*model.Video
 	var total int64
 
-	// Base queries for count and data selection
-	query := r.db.WithContext(ctx).Model(&model.Video{})
+	query, err := r.filtered(ctx, filter, "")
+	if err != nil {
+		return nil, 0, err
+	}
+	tsquery, searching := searchQuery(filter.Q)
+	sort := filter.SortOrder(searching)
+	switch sort {
+	case model.VideoRecent:
+		query = query.Joins("JOIN watch_progress ON watch_progress.video_id = videos.id AND watch_progress.user_id = ?", filter.UserId)
+	case model.VideoTrending:
+		since := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 1-model.TrendingDays)
+		query = query.Joins("LEFT JOIN (SELECT video_id, sum(views) AS views FROM video_views_daily WHERE day >= ? GROUP BY video_id) AS trending ON trending.video_id = videos.id", since)
+	}
 
-	// Apply full-text search filter if provided
-	if filter.Q != nil && *filter.Q != "" {
-		tsquery := gorm.Expr("to_tsquery('simple', ?)", ftsearch.BuildTsqueryExpression(*filter.Q, ftsearch.WithPrefixMatching()))
-		// Search across title, full_title, and description
-		ftSearch := "to_tsvector('simple', coalesce(title,'') || ' ' || coalesce(full_title,'') || ' ' || coalesce(description,'')) @@ ?"
-		query = query.Where(ftSearch, tsquery)
-	}
-	if filter.Category != nil && *filter.Category != "" {
-		jsonVal, err := json.Marshal([]string{*filter.Category})
-		if err != nil {
-			return nil, 0, apperr.NewAppErr("filter.encode.error", "Failed to encode category").WithCause(err)
+	if filter.CountTotal() {
+		if err := query.Count(&total).Error; err != nil {
+			return nil, 0, dbError(err, "video", "video.list.error", "Failed to count videos")
 		}
-
-		categoryFilter := gorm.Expr("categories @> ?", string(jsonVal))
-		query = query.Where(categoryFilter)
 	}
 
-	// Count total with filter
-	err := query.Count(&total).Error
-	if err != nil {
-		return nil, 0, apperr.NewAppErr("video.list.error", "Failed to count videos").WithCause(err)
+	rank := clause.Expr{SQL: "ts_rank(search_vector, ?)", Vars: []any{tsquery}}
+	switch sort {
+	case model.VideoRelevance:
+		query = query.Select("videos.*, ? AS search_rank", rank)
+	case model.VideoRecent:
+		query = query.Select("videos.*, watch_progress.updated_at AS watched_at")
+	case model.VideoTrending:
+		query = query.Select("videos.*, COALESCE(trending.views, 0) AS trending_views")
 	}
 
-	if filter.Type != "" {
-		switch filter.Type {
+	offset := filter.Pagination.Offset()
+	if filter.IsCursor() {
+		var cursor videoCursor
+		if err := pagination.DecodeCursor(filter.Cursor, &cursor); err != nil {
+			return nil, 0, err
+		}
+		if cursor.Sort != sort {
+			return nil, 0, pagination.InvalidCursor()
+		}
+		switch sort {
+		case model.VideoRelevance:
+			query = query.Where("(?, id) < (?, ?)", rank, cursor.Rank, cursor.Id)
 		case model.VideoPopular:
-			query = query.Order("view_count DESC")
+			query = query.Where("(view_count, created_at, id) < (?, ?, ?)", cursor.ViewCount, cursor.CreatedAt, cursor.Id)
+		case model.VideoTrending:
+			query = query.Where("(COALESCE(trending.views, 0), view_count, videos.id) < (?, ?, ?)", cursor.Trending, cursor.ViewCount, cursor.Id)
+		case model.VideoRecent:
+			if cursor.WatchedAt == nil {
+				return nil, 0, pagination.InvalidCursor()
+			}
+			query = query.Where("(watch_progress.updated_at, videos.id) < (?, ?)", *cursor.WatchedAt, cursor.Id)
 		default:
-			logger.Warnf("Unknown video type filter: %s", filter.Type)
+			query = query.Where("(created_at, id) < (?, ?)", cursor.CreatedAt, cursor.Id)
 		}
 	}
-	err = query.Order("created_at DESC").
-		Offset(filter.Pagination.Offset()).
-		Limit(filter.Pagination.Limit()).
-		Find(&videos).Error
+	switch sort {
+	case model.VideoRelevance:
+		query = query.Order("search_rank DESC")
+	case model.VideoPopular:
+		query = query.Order("view_count DESC").Order("created_at DESC")
+	case model.VideoTrending:
+		query = query.Order("trending_views DESC").Order("view_count DESC")
+	case model.VideoRecent:
+		query = query.Order("watch_progress.updated_at DESC")
+	default:
+		query = query.Order("created_at DESC")
+	}
+
+	var ranked []*rankedVideo
+	err = query.Order("videos.id DESC").
+		Offset(offset).
+		Limit(filter.FetchLimit()).
+		Find(&ranked).Error
+	if err != nil {
+		return nil, 0, dbError(err, "video", "video.list.error", "Failed to list videos")
+	}
+
+	ranked = pagination.Trim(&filter.Pagination, ranked, func(v *rankedVideo) any {
+		cursor := videoCursor{Sort: sort, Rank: v.SearchRank, ViewCount: v.ViewCount, Trending: v.TrendingViews, CreatedAt: v.CreatedAt, Id: v.Id}
+		if sort == model.VideoRecent {
+			cursor.WatchedAt = &v.WatchedAt
+		}
+		return cursor
+	})
+	videos := make([]*model.Video, len(ranked))
+	for i, v := range ranked {
+		videos[i] = &v.Video
+	}
+	return videos, total, nil
+}
+
+// Weights of the parts of a recommendation score. Each part ranges from 0 to
+// 1, so a score does too.
+const (
+	recommendLevelWeight      = 0.35
+	recommendDifficultyWeight = 0.2
+	recommendCategoryWeight   = 0.25
+	recommendUnseenWeight     = 0.15
+	recommendPopularityWeight = 0.05
+)
+
+// recommendedVideo is a video with the parts of its recommendation score
+// that explain it.
+type recommendedVideo struct {
+	model.Video
+	Score         float64 `gorm:"column:score"`
+	LevelScore    float64 `gorm:"column:level_score"`
+	CategoryScore float64 `gorm:"column:category_score"`
+}
+
+// Recommend lists the videos matching filter for a user, best first. A video
+// scores higher when its level is close to the level of the user, when most
+// of its segments are at most one level above it according to its stored
+// cefr_shares, when it shares a category
+// with the favorites of the user, when the user has neither favorited nor
+// watched it yet and when it is popular.
+func (r *VideoRepository) Recommend(ctx context.Context, userId string, profile *model.LearnerProfile, filter *model.VideoFilter) ([]*model.RecommendedVideo, int64, error) {
+	var total int64
+
+	query, err := r.filtered(ctx, filter, "")
+	if err != nil {
+		return nil, 0, err
+	}
+	if filter.CountTotal() {
+		if err := query.Count(&total).Error; err != nil {
+			return nil, 0, dbError(err, "video", "video.recommend.error", "Failed to count recommended videos")
+		}
+	}
+
+	levelScore := clause.Expr{SQL: "0"}
+	difficultyScore := clause.Expr{SQL: "0"}
+	if level := slices.Index(model.CefrLevels, profile.Level) + 1; level > 0 {
+		levelScore = clause.Expr{SQL: "GREATEST(0, 1 - 0.5 * ABS(" + cefrIndex("videos.cefr") + " - ?))", Vars: []any{level}}
+		// cefr_shares is indexed from 0, so level is the index of the level
+		// above the one of the user
+		difficultyScore = clause.Expr{
+			SQL:  "COALESCE(CAST(videos.cefr_shares ->> CAST(? AS INTEGER) AS DOUBLE PRECISION), 0)",
+			Vars: []any{min(level, len(model.CefrLevels)-1)},
+		}
+	}
+	categoryScore := clause.Expr{SQL: "0"}
+	if len(profile.Categories) > 0 {
+		condition, err := containsAny("videos.categories", profile.Categories)
+		if err != nil {
+			return nil, 0, err
+		}
+		categoryScore = clause.Expr{SQL: "CASE WHEN ? THEN 1 ELSE 0 END", Vars: []any{condition}}
+	}
+	unseenScore := clause.Expr{
+		SQL: "CASE WHEN EXISTS (SELECT 1 FROM favorites WHERE favorites.user_id = ? AND favorites.video_id = videos.id)" +
+			" OR EXISTS (SELECT 1 FROM watch_progress WHERE watch_progress.user_id = ? AND watch_progress.video_id = videos.id) THEN 0 ELSE 1 END",
+		Vars: []any{userId, userId},
+	}
+
+	scored := r.db.WithContext(ctx).
+		Table("(?) AS videos", query.Select(
+			"videos.*, ? AS level_score, ? AS difficulty_score, ? AS category_score, ? AS unseen_score, ln(1 + view_count) / (1 + ln(1 + view_count)) AS popularity_score",
+			levelScore, difficultyScore, categoryScore, unseenScore,
+		)).
+		Select(
+			"videos.*, level_score * ? + difficulty_score * ? + category_score * ? + unseen_score * ? + popularity_score * ? AS score",
+			recommendLevelWeight, recommendDifficultyWeight, recommendCategoryWeight, recommendUnseenWeight, recommendPopularityWeight,
+		)
+	page := r.db.WithContext(ctx).Table("(?) AS videos", scored)
+
+	offset := filter.Pagination.Offset()
+	if filter.IsCursor() {
+		var cursor videoCursor
+		if err := pagination.DecodeCursor(filter.Cursor, &cursor); err != nil {
+			return nil, 0, err
+		}
+		if cursor.Sort != model.VideoRecommended {
+			return nil, 0, pagination.InvalidCursor()
+		}
+		page = page.Where("(score, id) < (?, ?)", cursor.Rank, cursor.Id)
+	}
+
+	var scoredVideos []*recommendedVideo
+	err = page.Order("score DESC").
+		Order("id DESC").
+		Offset(offset).
+		Limit(filter.FetchLimit()).
+		Find(&scoredVideos).Error
 	if err != nil {
-		return nil, 0, apperr.NewAppErr("video.list.error", "Failed to list videos").WithCause(err)
+		return nil, 0, dbError(err, "video", "video.recommend.error", "Failed to recommend videos")
 	}
 
+	scoredVideos = pagination.Trim(&filter.Pagination, scoredVideos, func(v *recommendedVideo) any {
+		return videoCursor{Sort: model.VideoRecommended, Rank: v.Score, CreatedAt: v.CreatedAt, Id: v.Id}
+	})
+	videos := make([]*model.RecommendedVideo, len(scoredVideos))
+	for i, v := range scoredVideos {
+		video := &model.RecommendedVideo{Video: v.Video, Score: v.Score}
+		if v.LevelScore == 1 {
+			video.Reasons = append(video.Reasons, model.ReasonLevel)
+		}
+		if v.CategoryScore > 0 {
+			video.Reasons = append(video.Reasons, model.ReasonFavorites)
+		}
+		if len(video.Reasons) == 0 {
+			video.Reasons = append(video.Reasons, model.ReasonPopular)
+		}
+		videos[i] = video
+	}
 	return videos, total, nil
 }
 
+// cefrIndex is the SQL expression of the position of the level in column
+// among model.CefrLevels, starting at 1. It is NULL for unknown levels.
+func cefrIndex(column string) string {
+	return "array_position(ARRAY['" + strings.Join(model.CefrLevels, "', '") + "'], " + column + ")"
+}
+
+// filtered returns a query of the videos matching the search and filters of
+// filter, leaving out the filter of the facet except.
+func (r *VideoRepository) filtered(ctx context.Context, filter *model.VideoFilter, except string) (*gorm.DB, error) {
+	query := r.db.WithContext(ctx).Model(&model.Video{})
+
+	// Apply full-text search filter if provided
+	if tsquery, ok := searchQuery(filter.Q); ok {
+		query = query.Where("search_vector @@ ?", tsquery)
+	}
+	if categories := filter.CategoryValues(); len(categories) > 0 && except != model.FacetCategories {
+		condition, err := containsAny("categories", categories)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where(condition)
+	}
+	if len(filter.Tags) > 0 && except != model.FacetTags {
+		condition, err := containsAny("tags", filter.Tags)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where(condition)
+	}
+	if len(filter.Duration) > 0 && except != model.FacetDuration {
+		query = query.Where(durationIn(filter.Duration))
+	}
+	if levels := filter.CefrValues(); levels != nil && except != model.FacetCefr {
+		query = query.Where("cefr IN ?", levels)
+	}
+	if filter.ChannelId != "" && except != model.FacetChannels {
+		query = query.Where("channel_id = ?", filter.ChannelId)
+	}
+	if filter.HasTranslation != nil && except != model.FacetHasTranslation {
+		condition := "subtitle_languages @> ?"
+		if !*filter.HasTranslation {
+			condition = "NOT " + condition
+		}
+		query = query.Where(condition, translationLanguages)
+	}
+	return query, nil
+}
+
+// translationLanguages matches the subtitle languages of videos with a
+// translation.
+var translationLanguages = `["` + model.TranslationLanguage + `"]`
+
+// containsAny matches the rows whose jsonb array column holds any of values.
+// Each value is matched with @> so that the GIN index is used.
+func containsAny(column string, values []string) (clause.Expr, error) {
+	conditions := make([]string, len(values))
+	vars := make([]any, len(values))
+	for i, value := range values {
+		jsonVal, err := json.Marshal([]string{value})
+		if err != nil {
+			return clause.Expr{}, apperr.Internal("filter.encode.error", "Failed to encode filter").WithCause(err)
+		}
+		conditions[i] = column + " @> ?"
+		vars[i] = string(jsonVal)
+	}
+	return clause.Expr{SQL: "(" + strings.Join(conditions, " OR ") + ")", Vars: vars}, nil
+}
+
+// durationIn matches the videos in any of buckets.
+func durationIn(buckets []model.DurationBucket) clause.Expr {
+	conditions := make([]string, len(buckets))
+	var vars []any
+	for i, bucket := range buckets {
+		min, max := bucket.Range()
+		if max == 0 {
+			conditions[i] = "duration >= ?"
+			vars = append(vars, min)
+			continue
+		}
+		conditions[i] = "(duration >= ? AND duration < ?)"
+		vars = append(vars, min, max)
+	}
+	return clause.Expr{SQL: "(" + strings.Join(conditions, " OR ") + ")", Vars: vars}
+}
+
+// durationBucket is the SQL expression of the duration bucket of a video.
+func durationBucket() string {
+	var b strings.Builder
+	b.WriteString("CASE")
+	for _, bucket := range model.DurationBuckets {
+		if _, max := bucket.Range(); max > 0 {
+			fmt.Fprintf(&b, " WHEN duration < %d THEN '%s'", max, bucket)
+		} else {
+			fmt.Fprintf(&b, " ELSE '%s'", bucket)
+		}
+	}
+	b.WriteString(" END")
+	return b.String()
+}
+
+// MaxFacetValues caps the values counted for open-ended facets such as tags.
+const MaxFacetValues = 20
+
+// Facets counts the videos matching filter per value of each facet. The
+// counts of a facet ignore the filter on its own field, so that the client
+// can offer the other values.
+func (r *VideoRepository) Facets(ctx context.Context, filter *model.VideoFilter) (*model.VideoFacets, error) {
+	facets := &model.VideoFacets{}
+	for _, facet := range []struct {
+		name   string
+		counts *[]*model.FacetCount
+		query  func(query *gorm.DB) *gorm.DB
+	}{
+		{model.FacetCategories, &facets.Categories, func(query *gorm.DB) *gorm.DB {
+			return query.Joins("CROSS JOIN LATERAL jsonb_array_elements_text(CASE WHEN jsonb_typeof(categories) = 'array' THEN categories ELSE '[]'::jsonb END) AS category").
+				Select("category AS value, count(*) AS count").
+				Group("category").
+				Order("count DESC").Order("value")
+		}},
+		{model.FacetTags, &facets.Tags, func(query *gorm.DB) *gorm.DB {
+			return query.Joins("CROSS JOIN LATERAL jsonb_array_elements_text(CASE WHEN jsonb_typeof(tags) = 'array' THEN tags ELSE '[]'::jsonb END) AS tag").
+				Select("tag AS value, count(*) AS count").
+				Group("tag").
+				Order("count DESC").Order("value").
+				Limit(MaxFacetValues)
+		}},
+		{model.FacetDuration, &facets.Duration, func(query *gorm.DB) *gorm.DB {
+			bucket := durationBucket()
+			return query.Select(bucket + " AS value, count(*) AS count").
+				Group(bucket).
+				Order("min(duration)")
+		}},
+		{model.FacetCefr, &facets.Cefr, func(query *gorm.DB) *gorm.DB {
+			return query.Where("cefr <> ''").
+				Select("cefr AS value, count(*) AS count").
+				Group("cefr").
+				Order("cefr")
+		}},
+		{model.FacetChannels, &facets.Channels, func(query *gorm.DB) *gorm.DB {
+			return query.Where("channel_id <> ''").
+				Select("channel_id AS value, max(channel) AS label, count(*) AS count").
+				Group("channel_id").
+				Order("count DESC").Order("label").
+				Limit(MaxFacetValues)
+		}},
+		{model.FacetHasTranslation, &facets.HasTranslation, func(query *gorm.DB) *gorm.DB {
+			hasTranslation := clause.Expr{SQL: "CASE WHEN subtitle_languages @> ? THEN 'true' ELSE 'false' END", Vars: []any{translationLanguages}}
+			return query.Select("? AS value, count(*) AS count", hasTranslation).
+				Group("value").
+				Order("value DESC")
+		}},
+	} {
+		query, err := r.filtered(ctx, filter, facet.name)
+		if err != nil {
+			return nil, err
+		}
+		*facet.counts = []*model.FacetCount{}
+		if err := facet.query(query).Scan(facet.counts).Error; err != nil {
+			return nil, dbError(err, "video", "video.facets.error", "Failed to count videos per "+facet.name)
+		}
+	}
+	return facets, nil
+}
+
 func (r *VideoRepository) DistinctCategories(ctx context.Context) ([]string, error) {
 	var categories []string
 	err := r.db.WithContext(ctx).Raw(`SELECT DISTINCT jsonb_array_elements_text(categories) FROM videos WHERE jsonb_typeof(categories) = 'array'`).Pluck("jsonb_array_elements_text", &categories).Error
 	if err != nil {
-		return nil, apperr.NewAppErr("video.categories.error", "Failed to get distinct video categories").WithCause(err)
+		return nil, dbError(err, "video", "video.categories.error", "Failed to get distinct video categories")
 	}
 	return categories, nil
 }
 
 func (r *VideoRepository) Update(ctx context.Context, video *model.Video) error {
-	return r.db.WithContext(ctx).Model(&model.Video{}).Where("id = ?", video.Id).Updates(video).Error
+	if err := r.db.WithContext(ctx).Model(&model.Video{}).Where("id = ?", video.Id).Updates(video).Error; err != nil {
+		return dbError(err, "video", "video.update.error", "Failed to update video")
+	}
+	return nil
 }
 
 func (r *VideoRepository) Delete(ctx context.Context, id string) error {
-	return r.db.WithContext(ctx).Where("id = ?", id).Delete(&model.Video{}).Error
+	if err := r.db.WithContext(ctx).Where("id = ?", id).Delete(&model.Video{}).Error; err != nil {
+		return dbError(err, "video", "video.delete.error", "Failed to delete video")
+	}
+	return nil
 }
 
 func (r *VideoRepository) FindFavoriteVideos(ctx context.Context, userId string, filter *model.FavoriteVideoFilter) ([]*model.Video, int64, error) {
@@ -126,26 +487,26 @@ func (r *VideoRepository) FindFavoriteVideos(ctx context.Context, userId string,
 		Where("favorites.user_id = ?", userId).
 		Order("favorites.created_at DESC")
 
-	if filter.Q != nil && *filter.Q != "" {
-		tsquery := gorm.Expr("to_tsquery('simple', ?)", ftsearch.BuildTsqueryExpression(*filter.Q, ftsearch.WithPrefixMatching()))
-		// Search across title, full_title, and description
-		ftSearch := "to_tsvector('simple', coalesce(videos.title,'') || ' ' || coalesce(videos.full_title,'') || ' ' || coalesce(videos.description,'')) @@ ?"
-		query = query.Where(ftSearch, tsquery)
+	if tsquery, ok := searchQuery(filter.Q); ok {
+		query = query.Where("videos.search_vector @@ ?", tsquery)
 	}
 
 	var total int64
-	err := query.Count(&total).Error
-	if err != nil {
-		return nil, 0, apperr.NewAppErr("video.find_favorite.error", "Failed to count favorite videos").WithCause(err)
+	if filter.CountTotal() {
+		if err := query.Count(&total).Error; err != nil {
+			return nil, 0, dbError(err, "video", "video.find_favorite.error", "Failed to count favorite videos")
+		}
 	}
 
-	err = query.Offset(filter.Pagination.Offset()).
-		Limit(filter.Pagination.Limit()).
+	// Favorites are ordered by when they were added, which is not part of
+	// the video, so they are paged by offset only.
+	err := query.Offset(filter.Pagination.Offset()).
+		Limit(filter.FetchLimit()).
 		Find(&videos).Error
 	if err != nil {
-		return nil, 0, apperr.NewAppErr("video.find_favorite.error", "Failed to list favorite videos").WithCause(err)
+		return nil, 0, dbError(err, "video", "video.find_favorite.error", "Failed to list favorite videos")
 	}
-	return videos, total, nil
+	return pagination.Trim(&filter.Pagination, videos, nil), total, nil
 }
 
 func (c *VideoRepository) GetByYoutubeId(ctx context.Context, youtubeId string) (*model.Video, error) {
@@ -154,10 +515,10 @@ func (c *VideoRepository) GetByYoutubeId(ctx context.Context, youtubeId string)
 		Where("youtube_id = ?", youtubeId).
 		First(&video).Error
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil // No video found with this YouTube ID
 		}
-		return nil, apperr.NewAppErr("video.get_by_youtube_id.error", "Failed to get video by YouTube ID").WithCause(err)
+		return nil, dbError(err, "video", "video.get_by_youtube_id.error", "Failed to get video by YouTube ID")
 	}
 	return &video, nil
 }
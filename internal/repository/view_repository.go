@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"shadowify/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ViewRepository struct {
+	db *gorm.DB
+}
+
+func NewViewRepository(db *gorm.DB) *ViewRepository {
+	return &ViewRepository{db: db}
+}
+
+// addViewsQuery inserts the views that were not counted yet and adds them to
+// the daily and all-time counts of their videos. Views of unknown videos are
+// ignored.
+const addViewsQuery = `
+WITH counted AS (
+	INSERT INTO video_views (video_id, viewer_id, window_start)
+	SELECT v.video_id, v.viewer_id, v.window_start
+	FROM jsonb_to_recordset(CAST(@views AS jsonb)) AS v(video_id TEXT, viewer_id TEXT, window_start TIMESTAMPTZ)
+	WHERE EXISTS (SELECT 1 FROM videos WHERE videos.id = v.video_id)
+	ON CONFLICT DO NOTHING
+	RETURNING video_id, window_start
+), daily AS (
+	INSERT INTO video_views_daily AS d (video_id, day, views)
+	SELECT video_id, CAST(window_start AT TIME ZONE 'UTC' AS DATE), count(*)
+	FROM counted
+	GROUP BY 1, 2
+	ON CONFLICT (video_id, day) DO UPDATE SET views = d.views + EXCLUDED.views
+)
+UPDATE videos SET view_count = view_count + totals.views
+FROM (SELECT video_id, count(*) AS views FROM counted GROUP BY video_id) AS totals
+WHERE videos.id = totals.video_id`
+
+// AddViews counts views, skipping those already counted in their window.
+func (r *ViewRepository) AddViews(ctx context.Context, views []model.VideoView) error {
+	data, err := json.Marshal(views)
+	if err != nil {
+		return err
+	}
+	if err := r.db.WithContext(ctx).Exec(addViewsQuery, map[string]any{"views": string(data)}).Error; err != nil {
+		return dbError(err, "video_view", "video_view.add.error", "Failed to count views")
+	}
+	return nil
+}
+
+// Cleanup deletes the views of windows that started before before. Their
+// daily counts are kept.
+func (r *ViewRepository) Cleanup(ctx context.Context, before time.Time) error {
+	if err := r.db.WithContext(ctx).Exec("DELETE FROM video_views WHERE window_start < ?", before).Error; err != nil {
+		return dbError(err, "video_view", "video_view.cleanup.error", "Failed to clean up views")
+	}
+	return nil
+}
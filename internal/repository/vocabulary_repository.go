@@ -0,0 +1,205 @@
+package repository
+
+import (
+	"context"
+	"shadowify/internal/model"
+	"shadowify/internal/pagination"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type VocabularyRepository struct {
+	db *gorm.DB
+}
+
+func NewVocabularyRepository(db *gorm.DB) *VocabularyRepository {
+	return &VocabularyRepository{db: db}
+}
+
+// Save creates or replaces the status of a lemma for a user.
+func (r *VocabularyRepository) Save(ctx context.Context, word *model.VocabularyWord) error {
+	err := r.db.WithContext(ctx).
+		Clauses(
+			clause.OnConflict{
+				Columns:   []clause.Column{{Name: "user_id"}, {Name: "lemma"}},
+				DoUpdates: clause.AssignmentColumns([]string{"status", "source", "updated_at"}),
+			},
+			clause.Returning{},
+		).
+		Create(word).Error
+	if err != nil {
+		return dbError(err, "vocabulary", "vocabulary.save.error", "Failed to save word status")
+	}
+	return nil
+}
+
+// SaveAll sets the status of lemmas for a user. Lemmas that already have a
+// status keep it unless overwrite is set.
+func (r *VocabularyRepository) SaveAll(ctx context.Context, words []*model.VocabularyWord, overwrite bool) error {
+	if len(words) == 0 {
+		return nil
+	}
+	onConflict := clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "lemma"}},
+		DoNothing: true,
+	}
+	if overwrite {
+		onConflict = clause.OnConflict{
+			Columns:   onConflict.Columns,
+			DoUpdates: clause.AssignmentColumns([]string{"status", "source", "updated_at"}),
+		}
+	}
+	if err := r.db.WithContext(ctx).Clauses(onConflict).Create(words).Error; err != nil {
+		return dbError(err, "vocabulary", "vocabulary.save.error", "Failed to save word statuses")
+	}
+	return nil
+}
+
+func (r *VocabularyRepository) List(ctx context.Context, filter *model.VocabularyFilter) ([]*model.VocabularyWord, int64, error) {
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&model.VocabularyWord{}).Where("user_id = ?", filter.UserId)
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Q != nil && *filter.Q != "" {
+		query = query.Where("lemma LIKE ?", escapeLike(strings.ToLower(*filter.Q))+"%")
+	}
+
+	if filter.CountTotal() {
+		if err := query.Count(&total).Error; err != nil {
+			return nil, 0, dbError(err, "vocabulary", "vocabulary.list.error", "Failed to count words")
+		}
+	}
+
+	offset := filter.Offset()
+	if filter.IsCursor() {
+		var cursor updatedCursor
+		if err := pagination.DecodeCursor(filter.Cursor, &cursor); err != nil {
+			return nil, 0, err
+		}
+		query = query.Where("(updated_at, id) < (?, ?)", cursor.UpdatedAt, cursor.Id)
+	}
+
+	var words []*model.VocabularyWord
+	err := query.Order("updated_at DESC").Order("id DESC").Offset(offset).Limit(filter.FetchLimit()).Find(&words).Error
+	if err != nil {
+		return nil, 0, dbError(err, "vocabulary", "vocabulary.list.error", "Failed to list words")
+	}
+	return pagination.Trim(&filter.Pagination, words, func(w *model.VocabularyWord) any {
+		return updatedCursor{UpdatedAt: w.UpdatedAt, Id: w.Id}
+	}), total, nil
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// SetVideoLemmas replaces the lemmas of a video. Lemmas stored meanwhile by a
+// concurrent call for the same video are kept.
+func (r *VocabularyRepository) SetVideoLemmas(ctx context.Context, videoId string, lemmas []*model.VideoLemma) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("video_id = ?", videoId).Delete(&model.VideoLemma{}).Error; err != nil {
+			return err
+		}
+		if len(lemmas) == 0 {
+			return nil
+		}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(lemmas, 500).Error
+	})
+	if err != nil {
+		return dbError(err, "video_lemma", "vocabulary.video_lemmas.error", "Failed to store the lemmas of the video")
+	}
+	return nil
+}
+
+// Comprehension counts the unique lemmas of a video and those a user knows.
+func (r *VocabularyRepository) Comprehension(ctx context.Context, userId, videoId string) (*model.Comprehension, error) {
+	var comprehension model.Comprehension
+	err := r.db.WithContext(ctx).
+		Table("video_lemmas").
+		Select("count(*) AS total, count(vocabulary.lemma) AS known").
+		Joins("LEFT JOIN vocabulary ON vocabulary.lemma = video_lemmas.lemma AND vocabulary.user_id = ? AND vocabulary.status = ?", userId, model.WordKnown).
+		Where("video_lemmas.video_id = ?", videoId).
+		Scan(&comprehension).Error
+	if err != nil {
+		return nil, dbError(err, "vocabulary", "vocabulary.comprehension.error", "Failed to compute comprehension")
+	}
+	if comprehension.Total > 0 {
+		comprehension.Percent = float64(comprehension.Known) * 100 / float64(comprehension.Total)
+	}
+	return &comprehension, nil
+}
+
+// seedQuery adds the size most frequent lemmas as known, leaving the lemmas
+// that already have a status.
+const seedQuery = `
+INSERT INTO vocabulary (id, user_id, lemma, status, source, created_at, updated_at)
+SELECT gen_random_uuid(), @user, lemma, @status, @source, now(), now()
+FROM word_frequencies
+WHERE rank <= @size
+ON CONFLICT (user_id, lemma) DO NOTHING`
+
+// SeedFrequent marks the size most frequent lemmas as known for a user and
+// returns the number of lemmas added.
+func (r *VocabularyRepository) SeedFrequent(ctx context.Context, userId string, size int, source model.VocabularySource) (int64, error) {
+	args := map[string]any{"user": userId, "status": model.WordKnown, "source": source, "size": size}
+	result := r.db.WithContext(ctx).Exec(seedQuery, args)
+	if result.Error != nil {
+		return 0, dbError(result.Error, "vocabulary", "vocabulary.seed.error", "Failed to seed vocabulary")
+	}
+	return result.RowsAffected, nil
+}
+
+// RandomLemmas picks up to n random lemmas ranked after from and up to to by
+// frequency.
+func (r *VocabularyRepository) RandomLemmas(ctx context.Context, from, to, n int) ([]string, error) {
+	var lemmas []string
+	err := r.db.WithContext(ctx).
+		Model(&model.WordFrequency{}).
+		Where("rank > ? AND rank <= ?", from, to).
+		Order("random()").
+		Limit(n).
+		Pluck("lemma", &lemmas).Error
+	if err != nil {
+		return nil, dbError(err, "word_frequency", "vocabulary.quiz.error", "Failed to pick quiz words")
+	}
+	return lemmas, nil
+}
+
+// FrequencyRanks returns the frequency rank of each of lemmas that appears in
+// the frequency list.
+func (r *VocabularyRepository) FrequencyRanks(ctx context.Context, lemmas []string) (map[string]int, error) {
+	var rows []*model.WordFrequency
+	err := r.db.WithContext(ctx).
+		Where("lemma IN ?", lemmas).
+		Find(&rows).Error
+	if err != nil {
+		return nil, dbError(err, "word_frequency", "vocabulary.quiz.error", "Failed to rank quiz words")
+	}
+	ranks := make(map[string]int, len(rows))
+	for _, row := range rows {
+		ranks[row.Lemma] = row.Rank
+	}
+	return ranks, nil
+}
+
+// ReplaceFrequencies replaces the frequency list.
+func (r *VocabularyRepository) ReplaceFrequencies(ctx context.Context, frequencies []*model.WordFrequency) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM word_frequencies").Error; err != nil {
+			return err
+		}
+		if len(frequencies) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(frequencies, 1000).Error
+	})
+	if err != nil {
+		return dbError(err, "word_frequency", "vocabulary.frequencies.error", "Failed to store the frequency list")
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"shadowify/internal/model"
+	"shadowify/internal/pagination"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WatchProgressRepository struct {
+	db *gorm.DB
+}
+
+func NewWatchProgressRepository(db *gorm.DB) *WatchProgressRepository {
+	return &WatchProgressRepository{db: db}
+}
+
+// Save creates or replaces the progress of a user in a video.
+func (r *WatchProgressRepository) Save(ctx context.Context, progress *model.WatchProgress) error {
+	err := r.db.WithContext(ctx).
+		Clauses(
+			clause.OnConflict{
+				Columns:   []clause.Column{{Name: "user_id"}, {Name: "video_id"}},
+				DoUpdates: clause.AssignmentColumns([]string{"segment_id", "position_sec", "completed", "updated_at"}),
+			},
+			clause.Returning{},
+		).
+		Create(progress).Error
+	if err != nil {
+		return dbError(err, "watch_progress", "watch_progress.save.error", "Failed to save watch progress")
+	}
+	return nil
+}
+
+func (r *WatchProgressRepository) FindByUserIdAndVideoId(ctx context.Context, userId, videoId string) (*model.WatchProgress, error) {
+	var progress model.WatchProgress
+	err := r.db.WithContext(ctx).Where("user_id = ? AND video_id = ?", userId, videoId).First(&progress).Error
+	if err != nil {
+		return nil, dbError(err, "watch_progress", "watch_progress.find.error", "Failed to find watch progress")
+	}
+	return &progress, nil
+}
+
+// ContinueWatching lists the videos a user started and has not completed,
+// last watched first.
+func (r *WatchProgressRepository) ContinueWatching(ctx context.Context, filter *model.ContinueWatchingFilter) ([]*model.WatchedVideo, int64, error) {
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&model.WatchProgress{}).
+		Where("user_id = ? AND NOT completed", filter.UserId)
+
+	if filter.CountTotal() {
+		if err := query.Count(&total).Error; err != nil {
+			return nil, 0, dbError(err, "watch_progress", "watch_progress.list.error", "Failed to count watched videos")
+		}
+	}
+
+	offset := filter.Offset()
+	if filter.IsCursor() {
+		var cursor updatedCursor
+		if err := pagination.DecodeCursor(filter.Cursor, &cursor); err != nil {
+			return nil, 0, err
+		}
+		query = query.Where("(updated_at, id) < (?, ?)", cursor.UpdatedAt, cursor.Id)
+	}
+
+	var progress []*model.WatchProgress
+	err := query.Order("updated_at DESC").Order("id DESC").Offset(offset).Limit(filter.FetchLimit()).Find(&progress).Error
+	if err != nil {
+		return nil, 0, dbError(err, "watch_progress", "watch_progress.list.error", "Failed to list watched videos")
+	}
+	progress = pagination.Trim(&filter.Pagination, progress, func(p *model.WatchProgress) any {
+		return updatedCursor{UpdatedAt: p.UpdatedAt, Id: p.Id}
+	})
+	if len(progress) == 0 {
+		return []*model.WatchedVideo{}, total, nil
+	}
+
+	videoIds := make([]string, len(progress))
+	for i, p := range progress {
+		videoIds[i] = p.VideoId
+	}
+	var videos []*model.Video
+	if err := r.db.WithContext(ctx).Where("id IN ?", videoIds).Find(&videos).Error; err != nil {
+		return nil, 0, dbError(err, "video", "watch_progress.list.error", "Failed to list watched videos")
+	}
+	byId := make(map[string]*model.Video, len(videos))
+	for _, video := range videos {
+		byId[video.Id] = video
+	}
+
+	// Progress in videos that were deleted since is left out.
+	watched := make([]*model.WatchedVideo, 0, len(progress))
+	for _, p := range progress {
+		if video, ok := byId[p.VideoId]; ok {
+			watched = append(watched, &model.WatchedVideo{Video: *video, Progress: p})
+		}
+	}
+	return watched, total, nil
+}
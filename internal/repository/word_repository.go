@@ -2,9 +2,8 @@ package repository
 
 import (
 	"context"
-	"shadowify/internal/apperr"
-	"shadowify/internal/ftsearch"
 	"shadowify/internal/model"
+	"shadowify/internal/pagination"
 
 	"gorm.io/gorm"
 )
@@ -19,7 +18,7 @@ func NewWordRepository(db *gorm.DB) *WordRepository {
 
 func (r *WordRepository) Create(ctx context.Context, word *model.Word) error {
 	if err := r.db.WithContext(ctx).Model(&model.Word{}).Create(word).Error; err != nil {
-		return apperr.NewAppErr("word.create.error", "Failed to create word").WithCause(err)
+		return dbError(err, "word", "word.create.error", "Failed to create word")
 	}
 	return nil
 }
@@ -30,39 +29,84 @@ func (r *WordRepository) List(ctx context.Context, filter *model.WordFilter) ([]
 
 	query := r.db.WithContext(ctx).Model(&model.Word{}).Where("user_id = ?", filter.UserId)
 
-	if filter.Q != nil && *filter.Q != "" {
-		tsquery := gorm.Expr("to_tsquery('simple', ?)", ftsearch.BuildTsqueryExpression(*filter.Q, ftsearch.WithPrefixMatching()))
-		// Search across title, full_title, and description
-		ftSearch := "to_tsvector('simple', coalesce(meaning_en,'') || ' ' || coalesce(meaning_vi,'')) @@ ?"
-		query = query.Where(ftSearch, tsquery)
+	if condition, ok := meaningSearch(filter.Q); ok {
+		query = query.Where(condition)
 	}
 
-	if err := query.Count(&total).Error; err != nil {
-		return nil, 0, apperr.NewAppErr("word.list.error", "Failed to count words").WithCause(err)
+	if filter.CountTotal() {
+		if err := query.Count(&total).Error; err != nil {
+			return nil, 0, dbError(err, "word", "word.list.error", "Failed to count words")
+		}
 	}
 
-	err := query.Order("created_at DESC").Offset(filter.Offset()).Limit(filter.Limit()).Find(&words).Error
+	offset := filter.Offset()
+	if filter.IsCursor() {
+		var cursor createdCursor
+		if err := pagination.DecodeCursor(filter.Cursor, &cursor); err != nil {
+			return nil, 0, err
+		}
+		query = query.Where("(created_at, id) < (?, ?)", cursor.CreatedAt, cursor.Id)
+	}
+
+	err := query.Order("created_at DESC").Order("id DESC").Offset(offset).Limit(filter.FetchLimit()).Find(&words).Error
 	if err != nil {
-		return nil, 0, apperr.NewAppErr("word.list.error", "Failed to list words").WithCause(err)
+		return nil, 0, dbError(err, "word", "word.list.error", "Failed to list words")
 	}
-	return words, total, nil
+	return pagination.Trim(&filter.Pagination, words, func(w *model.Word) any {
+		return createdCursor{CreatedAt: w.CreatedAt, Id: w.Id}
+	}), total, nil
 }
 
-func (r *WordRepository) FindByWord(ctx context.Context, word string, userId string) (*model.Word, error) {
+// FindByLemma returns the saved word of the user with the lemma.
+func (r *WordRepository) FindByLemma(ctx context.Context, lemma string, userId string) (*model.Word, error) {
 	var foundWord model.Word
 	if err := r.db.WithContext(ctx).Model(&model.Word{}).
-		Where("meaning_en = ? AND user_id = ?", word, userId).
+		Where("lemma = ? AND user_id = ?", lemma, userId).
+		Order("created_at").
 		First(&foundWord).Error; err != nil {
-		return nil, apperr.NewAppErr("word.find.error", "Failed to find word").WithCause(err)
+		return nil, dbError(err, "word", "word.find.error", "Failed to find word")
 	}
 	return &foundWord, nil
 }
 
-func (r *WordRepository) DeleteByWord(ctx context.Context, word string, userId string) error {
+// DeleteByLemma deletes the saved words of the user with the lemma.
+func (r *WordRepository) DeleteByLemma(ctx context.Context, lemma string, userId string) error {
 	if err := r.db.WithContext(ctx).Model(&model.Word{}).
-		Where("meaning_en = ? AND user_id = ?", word, userId).
+		Where("lemma = ? AND user_id = ?", lemma, userId).
 		Delete(&model.Word{}).Error; err != nil {
-		return apperr.NewAppErr("word.delete.error", "Failed to delete word").WithCause(err)
+		return dbError(err, "word", "word.delete.error", "Failed to delete word")
+	}
+	return nil
+}
+
+// Delete deletes a word.
+func (r *WordRepository) Delete(ctx context.Context, id string) error {
+	if err := r.db.WithContext(ctx).Where("id = ?", id).Delete(&model.Word{}).Error; err != nil {
+		return dbError(err, "word", "word.delete.error", "Failed to delete word")
+	}
+	return nil
+}
+
+// ListAfter returns up to limit words of any user ordered by id, starting after
+// afterId. It is used to walk the whole table in batches.
+func (r *WordRepository) ListAfter(ctx context.Context, afterId string, limit int) ([]*model.Word, error) {
+	var words []*model.Word
+	if err := r.db.WithContext(ctx).Model(&model.Word{}).
+		Where("id > ?", afterId).
+		Order("id").Limit(limit).
+		Find(&words).Error; err != nil {
+		return nil, dbError(err, "word", "word.list.error", "Failed to list words")
+	}
+	return words, nil
+}
+
+// UpdateLemma sets the lemma of a word. It fails with word.already_exists
+// when the user already saved another word with the lemma.
+func (r *WordRepository) UpdateLemma(ctx context.Context, id string, lemma string) error {
+	if err := r.db.WithContext(ctx).Model(&model.Word{}).
+		Where("id = ?", id).
+		Update("lemma", lemma).Error; err != nil {
+		return dbError(err, "word", "word.update.error", "Failed to update word")
 	}
 	return nil
 }
@@ -5,8 +5,10 @@
 package response
 
 import (
+	"fmt"
 	"net/http"
 
+	"shadowify/i18n"
 	"shadowify/internal/apperr"
 	"shadowify/internal/logger"
 	"shadowify/internal/pagination"
@@ -25,35 +27,72 @@ func SuccessWithPagination(c echo.Context, data any, pagination *pagination.Pagi
 	return c.JSON(http.StatusOK, NewSuccessResponse(data).WithPagination(pagination))
 }
 
-// WriteError writes an error response based on error type.
+// WriteError writes an error response based on the kind of the error.
+//
+// Errors wrapped with fmt.Errorf or WithCause are unwrapped to find the
+// AppErr, anything else is reported as an internal error. The request id set
+// by the RequestId middleware is included in the body so that clients can
+// report it. Messages are translated to the locale negotiated by the Locale
+// middleware while codes stay the same. An apperr.List is written as one
+// entry per error, with the status taken from the first. Rate limit errors
+// set Retry-After from their retry_after param.
 func WriteError(c echo.Context, err error) error {
 	if err == nil {
 		return c.NoContent(http.StatusNoContent)
 	}
-	if appErr, ok := err.(*apperr.AppErr); ok {
-		if appErr.Unwrap() == nil {
-			logger.Errorf("error: %s", appErr.Error())
-			return c.JSON(AppErrCodeToStatus(appErr.Code), NewErrorResponse(appErr))
-		}
-		logger.Errorf("error: %s, cause: %s", appErr.Error(), appErr.Unwrap().Error())
-		return c.JSON(AppErrCodeToStatus(appErr.Code), NewErrorResponse(appErr))
-	}
-	logger.Errorf("error: %s", err.Error())
-	return c.JSON(AppErrCodeToStatus("bad_request"), NewErrorResponse(
-		apperr.NewAppErr("unexpected_error", "An unexpected error occurred"),
-	))
+	ctx := c.Request().Context()
+	errs := apperr.ListFrom(err)
+	appErr := errs[0]
+	status := KindToStatus(appErr.Kind)
+
+	fields := logger.Fields{"kind": appErr.Kind, "code": appErr.Code, "status": status}
+	if appErr.Field != "" {
+		fields["field"] = appErr.Field
+	}
+	if len(errs) > 1 {
+		fields["errors"] = len(errs)
+	}
+	if cause := appErr.Unwrap(); cause != nil {
+		fields["cause"] = cause.Error()
+	}
+	log := logger.WithContext(ctx).WithFields(fields)
+	if status >= http.StatusInternalServerError {
+		log.Error(appErr.Message)
+	} else {
+		log.Warn(appErr.Message)
+	}
+
+	localized := make([]*apperr.AppErr, len(errs))
+	for i, e := range errs {
+		localized[i] = i18n.Localize(ctx, e)
+	}
+	if retryAfter, ok := appErr.Params["retry_after"]; ok && status == http.StatusTooManyRequests {
+		c.Response().Header().Set(echo.HeaderRetryAfter, fmt.Sprint(retryAfter))
+	}
+	requestId := c.Response().Header().Get(echo.HeaderXRequestID)
+	return c.JSON(status, NewErrorResponse(localized...).WithRequestId(requestId))
 }
 
-// AppErrCodeToStatus maps AppErr code to HTTP status code.
-func AppErrCodeToStatus(code string) int {
-	switch code {
-	case "unauthorized":
+// KindToStatus maps an error kind to its HTTP status code.
+func KindToStatus(kind apperr.Kind) int {
+	switch kind {
+	case apperr.KindBadRequest:
+		return http.StatusBadRequest
+	case apperr.KindValidation:
+		return http.StatusUnprocessableEntity
+	case apperr.KindUnauthorized:
 		return http.StatusUnauthorized
-	case "forbidden":
+	case apperr.KindForbidden:
 		return http.StatusForbidden
-	case "bad_request":
-		return http.StatusBadRequest
+	case apperr.KindNotFound:
+		return http.StatusNotFound
+	case apperr.KindConflict:
+		return http.StatusConflict
+	case apperr.KindTooMany:
+		return http.StatusTooManyRequests
+	case apperr.KindUnavailable:
+		return http.StatusServiceUnavailable
 	default:
-		return http.StatusBadRequest
+		return http.StatusInternalServerError
 	}
 }
@@ -11,6 +11,7 @@ type Response struct {
 	Metadata   any                    `json:"metadata,omitempty"`
 	Pagination *pagination.Pagination `json:"pagination,omitempty"`
 	Errors     []*apperr.AppErr       `json:"errors,omitempty"`
+	RequestId  string                 `json:"request_id,omitempty"`
 }
 
 func NewSuccessResponse(data any) *Response {
@@ -46,3 +47,8 @@ func (r *Response) WithError(err *apperr.AppErr) *Response {
 	r.Errors = append(r.Errors, err)
 	return r
 }
+
+func (r *Response) WithRequestId(requestId string) *Response {
+	r.RequestId = requestId
+	return r
+}
@@ -0,0 +1,42 @@
+package runner
+
+import (
+	"context"
+	"sync"
+)
+
+// Fake is a Runner for unit tests. It records every command and delegates
+// to Handler, which may write expected output files or return errors.
+type Fake struct {
+	Handler func(ctx context.Context, cmd Command) (*Result, error)
+
+	mu    sync.Mutex
+	calls []Command
+}
+
+func (f *Fake) Run(ctx context.Context, cmd Command) (*Result, error) {
+	f.mu.Lock()
+	f.calls = append(f.calls, cmd)
+	f.mu.Unlock()
+
+	if f.Handler == nil {
+		return &Result{}, nil
+	}
+	result, err := f.Handler(ctx, cmd)
+	if result == nil {
+		result = &Result{}
+	}
+	if cmd.Stdout != nil && len(result.Stdout) > 0 {
+		if _, writeErr := cmd.Stdout.Write(result.Stdout); writeErr != nil && err == nil {
+			err = writeErr
+		}
+	}
+	return result, err
+}
+
+// Calls returns the commands run so far.
+func (f *Fake) Calls() []Command {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Command(nil), f.calls...)
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package runner
+
+import "os/exec"
+
+// setProcessGroup is a no-op where process groups are not supported; the
+// default cancellation kills the process itself.
+func setProcessGroup(cmd *exec.Cmd) {}
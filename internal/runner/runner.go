@@ -0,0 +1,154 @@
+// Package runner executes external tools such as whisper-cli, ffmpeg and
+// yt-dlp.
+//
+// Every invocation honours the caller's context and a per-tool deadline. On
+// cancellation the whole process group is killed so helper processes spawned
+// by the tool do not outlive it. Failures are reported as *Error carrying the
+// exit code and the tail of stderr.
+package runner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"shadowify/internal/config"
+	"strings"
+	"time"
+)
+
+const (
+	defaultTimeout = 10 * time.Minute
+	// waitDelay bounds how long to wait for output pipes after a kill.
+	waitDelay = 5 * time.Second
+	// maxStderr is how much of stderr is kept in errors.
+	maxStderr = 4 << 10
+)
+
+type Command struct {
+	// Name is the executable, either a path or a name looked up in PATH.
+	Name  string
+	Args  []string
+	Stdin io.Reader
+	// Stdout receives the standard output. When nil it is captured in Result.
+	Stdout io.Writer
+	// Timeout overrides the deadline of the tool when set, for processes that
+	// live as long as a session rather than a single job.
+	Timeout time.Duration
+}
+
+// Tool returns the name used to look up the deadline of the command.
+func (c Command) Tool() string {
+	return filepath.Base(c.Name)
+}
+
+type Result struct {
+	Stdout []byte
+	Stderr []byte
+}
+
+type Runner interface {
+	Run(ctx context.Context, cmd Command) (*Result, error)
+}
+
+// Error describes a failed invocation.
+type Error struct {
+	Tool     string
+	Args     []string
+	ExitCode int
+	Stderr   string
+	Err      error
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("%s failed: %v", e.Tool, e.Err)
+	if e.ExitCode > 0 {
+		msg = fmt.Sprintf("%s exited with code %d", e.Tool, e.ExitCode)
+	}
+	if e.Stderr != "" {
+		msg += ": " + e.Stderr
+	}
+	return msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+type ExecRunner struct {
+	defaultTimeout time.Duration
+	timeouts       map[string]time.Duration
+}
+
+func New(cfg config.RunnerConfig) *ExecRunner {
+	if cfg.DefaultTimeout <= 0 {
+		cfg.DefaultTimeout = defaultTimeout
+	}
+	return &ExecRunner{
+		defaultTimeout: cfg.DefaultTimeout,
+		timeouts:       cfg.Timeouts,
+	}
+}
+
+// Timeout returns the deadline applied to a tool.
+func (r *ExecRunner) Timeout(tool string) time.Duration {
+	if timeout, ok := r.timeouts[tool]; ok && timeout > 0 {
+		return timeout
+	}
+	return r.defaultTimeout
+}
+
+func (r *ExecRunner) Run(ctx context.Context, c Command) (*Result, error) {
+	tool := c.Tool()
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = r.Timeout(tool)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, c.Name, c.Args...)
+	cmd.Stdin = c.Stdin
+	cmd.Stdout = &stdout
+	if c.Stdout != nil {
+		cmd.Stdout = c.Stdout
+	}
+	cmd.Stderr = &stderr
+	cmd.WaitDelay = waitDelay
+	setProcessGroup(cmd)
+
+	err := cmd.Run()
+	result := &Result{Stdout: stdout.Bytes(), Stderr: stderr.Bytes()}
+	if err == nil {
+		return result, nil
+	}
+
+	runErr := &Error{
+		Tool:   tool,
+		Args:   c.Args,
+		Stderr: tail(stderr.String(), maxStderr),
+		Err:    err,
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		runErr.ExitCode = exitErr.ExitCode()
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		// The process was killed because of the context, report why.
+		runErr.ExitCode = 0
+		runErr.Err = ctxErr
+	}
+	return result, runErr
+}
+
+func tail(s string, n int) string {
+	s = strings.TrimSpace(s)
+	if len(s) <= n {
+		return s
+	}
+	return "..." + s[len(s)-n:]
+}
@@ -0,0 +1,65 @@
+//go:build unix
+
+package runner
+
+import (
+	"context"
+	"errors"
+	"shadowify/internal/config"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecRunner_Run(t *testing.T) {
+	r := New(config.RunnerConfig{})
+
+	result, err := r.Run(context.Background(), Command{
+		Name:  "sh",
+		Args:  []string{"-c", "cat; echo done >&2"},
+		Stdin: strings.NewReader("hello"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(result.Stdout))
+	assert.Equal(t, "done\n", string(result.Stderr))
+}
+
+func TestExecRunner_ExitError(t *testing.T) {
+	r := New(config.RunnerConfig{})
+
+	_, err := r.Run(context.Background(), Command{
+		Name: "sh",
+		Args: []string{"-c", "echo broken input >&2; exit 3"},
+	})
+	var runErr *Error
+	require.True(t, errors.As(err, &runErr))
+	assert.Equal(t, "sh", runErr.Tool)
+	assert.Equal(t, 3, runErr.ExitCode)
+	assert.Equal(t, "broken input", runErr.Stderr)
+}
+
+func TestExecRunner_Timeout(t *testing.T) {
+	r := New(config.RunnerConfig{Timeouts: map[string]time.Duration{"sh": 50 * time.Millisecond}})
+
+	start := time.Now()
+	// The background sleep shares the process group and must be killed too,
+	// otherwise Run would wait for it to close stdout.
+	_, err := r.Run(context.Background(), Command{
+		Name: "sh",
+		Args: []string{"-c", "sleep 10 & sleep 10"},
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
+
+func TestExecRunner_Canceled(t *testing.T) {
+	r := New(config.RunnerConfig{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	_, err := r.Run(ctx, Command{Name: "sleep", Args: []string{"10"}})
+	assert.ErrorIs(t, err, context.Canceled)
+}
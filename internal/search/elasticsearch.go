@@ -0,0 +1,707 @@
+package search
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"shadowify/internal/apperr"
+	"shadowify/internal/config"
+	"shadowify/internal/ftsearch"
+	"shadowify/internal/metrics"
+	"shadowify/internal/model"
+	"shadowify/internal/pagination"
+	"shadowify/internal/repository"
+	"slices"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+const defaultElasticsearchTimeout = 10 * time.Second
+
+// videoFields are the searched fields of a video with their boosts.
+var videoFields = []string{"title^3", "full_title^3", "tags^2", "description"}
+
+const videoMapping = `{
+  "mappings": {
+    "dynamic": false,
+    "properties": {
+      "id": {"type": "keyword"},
+      "title": {"type": "text", "analyzer": "english"},
+      "full_title": {"type": "text", "analyzer": "english"},
+      "description": {"type": "text", "analyzer": "english"},
+      "tags": {"type": "text", "analyzer": "english", "fields": {"keyword": {"type": "keyword"}}},
+      "categories": {"type": "keyword"},
+      "cefr": {"type": "keyword"},
+      "channel": {"type": "keyword"},
+      "channel_id": {"type": "keyword"},
+      "subtitle_languages": {"type": "keyword"},
+      "language_id": {"type": "keyword"},
+      "youtube_id": {"type": "keyword"},
+      "view_count": {"type": "long"},
+      "duration": {"type": "integer"},
+      "created_at": {"type": "date"},
+      "updated_at": {"type": "date"}
+    }
+  }
+}`
+
+const segmentMapping = `{
+  "mappings": {
+    "dynamic": false,
+    "properties": {
+      "id": {"type": "keyword"},
+      "video_id": {"type": "keyword"},
+      "start_sec": {"type": "float"},
+      "end_sec": {"type": "float"},
+      "content": {"type": "text", "analyzer": "english"},
+      "cefr": {"type": "keyword"},
+      "video_categories": {"type": "keyword"}
+    }
+  }
+}`
+
+// ElasticsearchIndex keeps the videos and their segments in two indexes of an
+// Elasticsearch or OpenSearch cluster, using only the APIs both provide.
+// Segments carry the fields of their video needed to filter them.
+type ElasticsearchIndex struct {
+	url      string
+	username string
+	password string
+	videos   string
+	segments string
+	client   *http.Client
+}
+
+func NewElasticsearchIndex(cfg config.ElasticsearchConfig) *ElasticsearchIndex {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultElasticsearchTimeout
+	}
+	return &ElasticsearchIndex{
+		url:      strings.TrimSuffix(cfg.URL, "/"),
+		username: cfg.Username,
+		password: cfg.Password,
+		videos:   cfg.IndexPrefix + "videos",
+		segments: cfg.IndexPrefix + "segments",
+		client: &http.Client{
+			Timeout:   cfg.Timeout,
+			Transport: otelhttp.NewTransport(metrics.NewTransport("elasticsearch", http.DefaultTransport)),
+		},
+	}
+}
+
+// segmentDocument is a segment with the fields of its video it is filtered
+// by.
+type segmentDocument struct {
+	model.Segment
+	VideoCategories []string `json:"video_categories"`
+}
+
+// EnsureIndexes creates the indexes that do not exist and adds new fields to
+// the mappings of the others. With recreate, the existing indexes are deleted
+// first, which drops all documents.
+func (i *ElasticsearchIndex) EnsureIndexes(ctx context.Context, recreate bool) error {
+	for _, index := range []struct{ name, mapping string }{
+		{i.videos, videoMapping},
+		{i.segments, segmentMapping},
+	} {
+		if recreate {
+			if err := i.do(ctx, http.MethodDelete, "/"+index.name, nil, nil, http.StatusNotFound); err != nil {
+				return err
+			}
+		}
+		response, err := i.send(ctx, http.MethodHead, "/"+index.name, nil)
+		if err != nil {
+			return err
+		}
+		response.Body.Close()
+		if response.StatusCode != http.StatusOK {
+			if err := i.do(ctx, http.MethodPut, "/"+index.name, json.RawMessage(index.mapping), nil); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var mapping struct {
+			Mappings json.RawMessage `json:"mappings"`
+		}
+		if err := json.Unmarshal([]byte(index.mapping), &mapping); err != nil {
+			return apperr.Internal("search.encode.error", "Invalid index mapping").WithCause(err)
+		}
+		if err := i.do(ctx, http.MethodPut, "/"+index.name+"/_mapping", mapping.Mappings, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (i *ElasticsearchIndex) IndexVideo(ctx context.Context, video *model.Video) error {
+	return i.do(ctx, http.MethodPut, "/"+i.videos+"/_doc/"+url.PathEscape(video.Id), video, nil)
+}
+
+func (i *ElasticsearchIndex) DeleteVideo(ctx context.Context, videoId string) error {
+	if err := i.do(ctx, http.MethodDelete, "/"+i.videos+"/_doc/"+url.PathEscape(videoId), nil, nil, http.StatusNotFound); err != nil {
+		return err
+	}
+	return i.deleteSegments(ctx, videoId)
+}
+
+func (i *ElasticsearchIndex) deleteSegments(ctx context.Context, videoId string) error {
+	query := map[string]any{
+		"query": map[string]any{"term": map[string]any{"video_id": videoId}},
+	}
+	return i.do(ctx, http.MethodPost, "/"+i.segments+"/_delete_by_query?conflicts=proceed", query, nil, http.StatusNotFound)
+}
+
+func (i *ElasticsearchIndex) IndexSegments(ctx context.Context, video *model.Video, segments []*model.Segment) error {
+	if err := i.deleteSegments(ctx, video.Id); err != nil {
+		return err
+	}
+	if len(segments) == 0 {
+		return nil
+	}
+
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, segment := range segments {
+		action := map[string]any{"index": map[string]any{"_index": i.segments, "_id": segment.Id}}
+		document := segmentDocument{Segment: *segment, VideoCategories: video.Categories.Data}
+		document.VideoId = video.Id
+		if err := encoder.Encode(action); err != nil {
+			return apperr.Internal("search.encode.error", "Failed to encode segment").WithCause(err)
+		}
+		if err := encoder.Encode(document); err != nil {
+			return apperr.Internal("search.encode.error", "Failed to encode segment").WithCause(err)
+		}
+	}
+
+	var result struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			Error json.RawMessage `json:"error"`
+		} `json:"items"`
+	}
+	if err := i.do(ctx, http.MethodPost, "/_bulk", &body, &result); err != nil {
+		return err
+	}
+	if result.Errors {
+		failed := 0
+		var first json.RawMessage
+		for _, item := range result.Items {
+			for _, action := range item {
+				if action.Error != nil {
+					if first == nil {
+						first = action.Error
+					}
+					failed++
+				}
+			}
+		}
+		return apperr.Unavailable("search.request.error", "Failed to index segments").
+			WithParam("failed", failed).
+			WithCause(fmt.Errorf("bulk error: %s", first))
+	}
+	return nil
+}
+
+// searchCursor continues a video list after the sort values of the last hit.
+type searchCursor struct {
+	Sort  model.VideoType `json:"s"`
+	After []any           `json:"a"`
+}
+
+type searchHit struct {
+	Id        string              `json:"_id"`
+	Source    json.RawMessage     `json:"_source"`
+	Sort      []any               `json:"sort"`
+	Highlight map[string][]string `json:"highlight"`
+	InnerHits map[string]struct {
+		Hits searchHits `json:"hits"`
+	} `json:"inner_hits"`
+}
+
+type searchHits struct {
+	Total struct {
+		Value int64 `json:"value"`
+	} `json:"total"`
+	Hits []searchHit `json:"hits"`
+}
+
+type searchResponse struct {
+	Hits         searchHits                 `json:"hits"`
+	Aggregations map[string]json.RawMessage `json:"aggregations"`
+}
+
+func (i *ElasticsearchIndex) SearchVideos(ctx context.Context, filter *model.VideoFilter) ([]*model.Video, int64, error) {
+	query, searching := videoQuery(filter)
+	sort := filter.SortOrder(searching)
+	body := map[string]any{
+		"query":            query,
+		"sort":             videoSort(sort),
+		"size":             filter.FetchLimit(),
+		"track_total_hits": filter.CountTotal(),
+	}
+	offset := filter.Offset()
+	if filter.IsCursor() {
+		var cursor searchCursor
+		if err := pagination.DecodeCursor(filter.Cursor, &cursor); err != nil {
+			return nil, 0, err
+		}
+		if cursor.Sort != sort || len(cursor.After) == 0 {
+			return nil, 0, pagination.InvalidCursor()
+		}
+		body["search_after"] = cursor.After
+	} else {
+		body["from"] = offset
+	}
+
+	var result searchResponse
+	if err := i.do(ctx, http.MethodPost, "/"+i.videos+"/_search", body, &result); err != nil {
+		return nil, 0, err
+	}
+	hits := pagination.Trim(&filter.Pagination, result.Hits.Hits, func(hit searchHit) any {
+		return searchCursor{Sort: sort, After: hit.Sort}
+	})
+	videos := make([]*model.Video, len(hits))
+	for j, hit := range hits {
+		if err := json.Unmarshal(hit.Source, &videos[j]); err != nil {
+			return nil, 0, apperr.Internal("search.decode.error", "Failed to decode video").WithCause(err)
+		}
+	}
+	return videos, result.Hits.Total.Value, nil
+}
+
+// Facets computes all facets in one request. The query only searches, each
+// facet is an aggregation filtered by the filters of the other facets.
+func (i *ElasticsearchIndex) Facets(ctx context.Context, filter *model.VideoFilter) (*model.VideoFacets, error) {
+	hasTranslation := map[string]any{"term": map[string]any{"subtitle_languages": model.TranslationLanguage}}
+	durations := make([]any, len(model.DurationBuckets))
+	for j, bucket := range model.DurationBuckets {
+		durations[j] = durationRange(bucket)
+		durations[j].(map[string]any)["key"] = string(bucket)
+	}
+	aggregations := map[string]any{
+		model.FacetCategories: map[string]any{"terms": map[string]any{"field": "categories", "size": 100}},
+		model.FacetTags:       map[string]any{"terms": map[string]any{"field": "tags.keyword", "size": repository.MaxFacetValues}},
+		model.FacetDuration:   map[string]any{"range": map[string]any{"field": "duration", "ranges": durations}},
+		model.FacetCefr:       map[string]any{"terms": map[string]any{"field": "cefr", "size": len(model.CefrLevels) + 1}},
+		model.FacetChannels: map[string]any{
+			"terms": map[string]any{"field": "channel_id", "size": repository.MaxFacetValues},
+			"aggs":  map[string]any{"label": map[string]any{"terms": map[string]any{"field": "channel", "size": 1}}},
+		},
+		model.FacetHasTranslation: map[string]any{"filters": map[string]any{"filters": map[string]any{
+			"true":  hasTranslation,
+			"false": map[string]any{"bool": map[string]any{"must_not": hasTranslation}},
+		}}},
+	}
+	aggs := make(map[string]any, len(aggregations))
+	for name, aggregation := range aggregations {
+		aggs[name] = map[string]any{
+			"filter": map[string]any{"bool": map[string]any{"filter": videoFilters(filter, name)}},
+			"aggs":   map[string]any{"values": aggregation},
+		}
+	}
+
+	query, _ := videoQuery(&model.VideoFilter{Q: filter.Q})
+	body := map[string]any{
+		"query": query,
+		"size":  0,
+		"aggs":  aggs,
+	}
+	var result searchResponse
+	if err := i.do(ctx, http.MethodPost, "/"+i.videos+"/_search", body, &result); err != nil {
+		return nil, err
+	}
+
+	facets := &model.VideoFacets{}
+	for name, counts := range map[string]*[]*model.FacetCount{
+		model.FacetCategories:     &facets.Categories,
+		model.FacetTags:           &facets.Tags,
+		model.FacetDuration:       &facets.Duration,
+		model.FacetCefr:           &facets.Cefr,
+		model.FacetChannels:       &facets.Channels,
+		model.FacetHasTranslation: &facets.HasTranslation,
+	} {
+		var aggregation struct {
+			Values json.RawMessage `json:"values"`
+		}
+		if raw, ok := result.Aggregations[name]; ok {
+			if err := json.Unmarshal(raw, &aggregation); err != nil {
+				return nil, apperr.Internal("search.decode.error", "Failed to decode facets").WithCause(err)
+			}
+		}
+		var err error
+		if *counts, err = facetBuckets(aggregation.Values); err != nil {
+			return nil, err
+		}
+	}
+	facets.Cefr = slices.DeleteFunc(facets.Cefr, func(f *model.FacetCount) bool {
+		return f.Value == ""
+	})
+	slices.SortFunc(facets.Cefr, func(a, b *model.FacetCount) int {
+		return strings.Compare(a.Value, b.Value)
+	})
+	slices.SortFunc(facets.HasTranslation, func(a, b *model.FacetCount) int {
+		return strings.Compare(b.Value, a.Value)
+	})
+	return facets, nil
+}
+
+// SearchTranscripts collapses the matching segments by video. Collapsed
+// results cannot seek to a sort key, so pages continue by offset.
+func (i *ElasticsearchIndex) SearchTranscripts(ctx context.Context, filter *model.TranscriptFilter) ([]*model.TranscriptMatch, int64, error) {
+	offset, err := pageOffset(&filter.Pagination)
+	if err != nil {
+		return nil, 0, err
+	}
+	terms := ftsearch.Parse(filter.Q)
+	if len(terms) == 0 {
+		return []*model.TranscriptMatch{}, 0, nil
+	}
+
+	filters := []any{}
+	if filter.Category != nil && *filter.Category != "" {
+		filters = append(filters, map[string]any{"term": map[string]any{"video_categories": *filter.Category}})
+	}
+	if filter.Cefr != "" {
+		filters = append(filters, map[string]any{"term": map[string]any{"cefr": filter.Cefr}})
+	}
+	body := map[string]any{
+		"query": map[string]any{"bool": map[string]any{
+			"must":   []any{simpleQuery(terms, "content")},
+			"filter": filters,
+		}},
+		"collapse": map[string]any{
+			"field": "video_id",
+			"inner_hits": map[string]any{
+				"name": "best",
+				"size": repository.MatchesPerVideo,
+				"sort": []any{map[string]any{"_score": "desc"}, map[string]any{"start_sec": "asc"}},
+				"highlight": map[string]any{
+					"fields": map[string]any{"content": map[string]any{
+						"number_of_fragments": 0,
+						"pre_tags":            []string{"<mark>"},
+						"post_tags":           []string{"</mark>"},
+					}},
+				},
+			},
+		},
+		"sort":             []any{map[string]any{"_score": "desc"}, map[string]any{"video_id": "desc"}},
+		"from":             offset,
+		"size":             filter.FetchLimit(),
+		"track_total_hits": false,
+	}
+	if filter.CountTotal() {
+		// Approximate above a few thousand videos
+		body["aggs"] = map[string]any{"videos": map[string]any{"cardinality": map[string]any{"field": "video_id"}}}
+	}
+
+	var result searchResponse
+	if err := i.do(ctx, http.MethodPost, "/"+i.segments+"/_search", body, &result); err != nil {
+		return nil, 0, err
+	}
+	var total int64
+	if aggregation, ok := result.Aggregations["videos"]; ok {
+		var cardinality struct {
+			Value int64 `json:"value"`
+		}
+		if err := json.Unmarshal(aggregation, &cardinality); err != nil {
+			return nil, 0, apperr.Internal("search.decode.error", "Failed to decode total").WithCause(err)
+		}
+		total = cardinality.Value
+	}
+
+	hits := trimOffset(&filter.Pagination, result.Hits.Hits, offset)
+	matches := make([]*model.TranscriptMatch, 0, len(hits))
+	videoIds := make([]string, 0, len(hits))
+	for _, hit := range hits {
+		best := hit.InnerHits["best"].Hits
+		match := &model.TranscriptMatch{MatchCount: best.Total.Value, Segments: make([]*model.SegmentMatch, 0, len(best.Hits))}
+		for _, segmentHit := range best.Hits {
+			var document segmentDocument
+			if err := json.Unmarshal(segmentHit.Source, &document); err != nil {
+				return nil, 0, apperr.Internal("search.decode.error", "Failed to decode segment").WithCause(err)
+			}
+			snippet := document.Content
+			if fragments := segmentHit.Highlight["content"]; len(fragments) > 0 {
+				snippet = fragments[0]
+			}
+			match.Segments = append(match.Segments, &model.SegmentMatch{Segment: document.Segment, Snippet: snippet})
+		}
+		slices.SortFunc(match.Segments, func(a, b *model.SegmentMatch) int {
+			return cmp.Compare(a.StartSec, b.StartSec)
+		})
+		if len(match.Segments) > 0 {
+			videoIds = append(videoIds, match.Segments[0].VideoId)
+			matches = append(matches, match)
+		}
+	}
+	if len(matches) == 0 {
+		return matches, total, nil
+	}
+
+	videos, err := i.getVideos(ctx, videoIds)
+	if err != nil {
+		return nil, 0, err
+	}
+	// A video deleted in between the queries is skipped
+	return slices.DeleteFunc(matches, func(m *model.TranscriptMatch) bool {
+		m.Video = videos[m.Segments[0].VideoId]
+		return m.Video == nil
+	}), total, nil
+}
+
+// getVideos returns the indexed videos with the given ids, by id.
+func (i *ElasticsearchIndex) getVideos(ctx context.Context, ids []string) (map[string]*model.Video, error) {
+	var result struct {
+		Docs []struct {
+			Found  bool            `json:"found"`
+			Source json.RawMessage `json:"_source"`
+		} `json:"docs"`
+	}
+	if err := i.do(ctx, http.MethodPost, "/"+i.videos+"/_mget", map[string]any{"ids": ids}, &result); err != nil {
+		return nil, err
+	}
+	videos := make(map[string]*model.Video, len(result.Docs))
+	for _, doc := range result.Docs {
+		if !doc.Found {
+			continue
+		}
+		var video model.Video
+		if err := json.Unmarshal(doc.Source, &video); err != nil {
+			return nil, apperr.Internal("search.decode.error", "Failed to decode video").WithCause(err)
+		}
+		videos[video.Id] = &video
+	}
+	return videos, nil
+}
+
+func (i *ElasticsearchIndex) Ping(ctx context.Context) error {
+	return i.do(ctx, http.MethodGet, "/", nil, nil)
+}
+
+// videoQuery returns the query of the videos matching filter and whether it
+// searches for words.
+func videoQuery(filter *model.VideoFilter) (map[string]any, bool) {
+	must := []any{}
+	terms := parseQuery(filter.Q)
+	if len(terms) > 0 {
+		must = append(must, simpleQuery(terms, videoFields...))
+	}
+	return map[string]any{"bool": map[string]any{"must": must, "filter": videoFilters(filter, "")}}, len(terms) > 0
+}
+
+// videoFilters returns the filters of filter, leaving out the filter of the
+// facet except.
+func videoFilters(filter *model.VideoFilter, except string) []any {
+	filters := []any{}
+	if categories := filter.CategoryValues(); len(categories) > 0 && except != model.FacetCategories {
+		filters = append(filters, map[string]any{"terms": map[string]any{"categories": categories}})
+	}
+	if len(filter.Tags) > 0 && except != model.FacetTags {
+		filters = append(filters, map[string]any{"terms": map[string]any{"tags.keyword": filter.Tags}})
+	}
+	if len(filter.Duration) > 0 && except != model.FacetDuration {
+		ranges := make([]any, len(filter.Duration))
+		for j, bucket := range filter.Duration {
+			ranges[j] = map[string]any{"range": map[string]any{"duration": durationRange(bucket)}}
+		}
+		filters = append(filters, map[string]any{"bool": map[string]any{"should": ranges, "minimum_should_match": 1}})
+	}
+	if levels := filter.CefrValues(); levels != nil && except != model.FacetCefr {
+		filters = append(filters, map[string]any{"terms": map[string]any{"cefr": levels}})
+	}
+	if filter.ChannelId != "" && except != model.FacetChannels {
+		filters = append(filters, map[string]any{"term": map[string]any{"channel_id": filter.ChannelId}})
+	}
+	if filter.HasTranslation != nil && except != model.FacetHasTranslation {
+		hasTranslation := map[string]any{"term": map[string]any{"subtitle_languages": model.TranslationLanguage}}
+		if !*filter.HasTranslation {
+			hasTranslation = map[string]any{"bool": map[string]any{"must_not": hasTranslation}}
+		}
+		filters = append(filters, hasTranslation)
+	}
+	return filters
+}
+
+// durationRange returns the bounds of bucket as range options.
+func durationRange(bucket model.DurationBucket) map[string]any {
+	min, max := bucket.Range()
+	bounds := map[string]any{"from": min}
+	if max > 0 {
+		bounds["to"] = max
+	}
+	return bounds
+}
+
+func videoSort(sort model.VideoType) []any {
+	var fields []string
+	switch sort {
+	case model.VideoRelevance:
+		fields = []string{"_score"}
+	case model.VideoPopular:
+		fields = []string{"view_count", "created_at"}
+	default:
+		fields = []string{"created_at"}
+	}
+	clauses := make([]any, 0, len(fields)+1)
+	for _, field := range append(fields, "id") {
+		clauses = append(clauses, map[string]any{field: "desc"})
+	}
+	return clauses
+}
+
+// simpleQuery returns a simple_query_string query of terms on fields. Words
+// are analyzed before prefix matching so that they are stemmed like the
+// indexed text.
+func simpleQuery(terms []ftsearch.Term, fields ...string) map[string]any {
+	return map[string]any{"simple_query_string": map[string]any{
+		"query":            simpleQueryString(terms),
+		"fields":           fields,
+		"default_operator": "and",
+		"analyze_wildcard": true,
+		"flags":            "AND|OR|NOT|PHRASE|PREFIX|WHITESPACE",
+	}}
+}
+
+// simpleQueryString writes terms in the simple_query_string syntax. Lexemes
+// hold only letters and digits, so nothing needs escaping.
+func simpleQueryString(terms []ftsearch.Term) string {
+	var b strings.Builder
+	for j, term := range terms {
+		if j > 0 {
+			if term.Or {
+				b.WriteString(" | ")
+			} else {
+				b.WriteString(" + ")
+			}
+		}
+		if term.Negated {
+			b.WriteString("-")
+		}
+		words := strings.ToLower(strings.Join(term.Lexemes, " "))
+		if term.IsPhrase() {
+			b.WriteString(`"` + words + `"`)
+		} else {
+			b.WriteString(words + "*")
+		}
+	}
+	return b.String()
+}
+
+// facetBuckets reads the buckets of a terms, range or keyed filters
+// aggregation. Empty buckets are left out.
+func facetBuckets(aggregation json.RawMessage) ([]*model.FacetCount, error) {
+	facets := []*model.FacetCount{}
+	if aggregation == nil {
+		return facets, nil
+	}
+	type bucket struct {
+		Key      any   `json:"key"`
+		DocCount int64 `json:"doc_count"`
+		Label    struct {
+			Buckets []struct {
+				Key string `json:"key"`
+			} `json:"buckets"`
+		} `json:"label"`
+	}
+	var values struct {
+		Buckets json.RawMessage `json:"buckets"`
+	}
+	if err := json.Unmarshal(aggregation, &values); err != nil {
+		return nil, apperr.Internal("search.decode.error", "Failed to decode facets").WithCause(err)
+	}
+
+	var buckets []bucket
+	if err := json.Unmarshal(values.Buckets, &buckets); err != nil {
+		// Filters aggregations key their buckets by name
+		var keyed map[string]bucket
+		if err := json.Unmarshal(values.Buckets, &keyed); err != nil {
+			return nil, apperr.Internal("search.decode.error", "Failed to decode facets").WithCause(err)
+		}
+		for key, b := range keyed {
+			b.Key = key
+			buckets = append(buckets, b)
+		}
+	}
+	for _, b := range buckets {
+		if b.DocCount == 0 {
+			continue
+		}
+		facet := &model.FacetCount{Value: fmt.Sprint(b.Key), Count: b.DocCount}
+		if len(b.Label.Buckets) > 0 {
+			facet.Label = b.Label.Buckets[0].Key
+		}
+		facets = append(facets, facet)
+	}
+	return facets, nil
+}
+
+// send sends a request with body encoded as JSON, or as is when it is a
+// *bytes.Buffer of NDJSON.
+func (i *ElasticsearchIndex) send(ctx context.Context, method, path string, body any) (*http.Response, error) {
+	var reader io.Reader
+	contentType := "application/json"
+	switch b := body.(type) {
+	case nil:
+	case *bytes.Buffer:
+		reader = b
+		contentType = "application/x-ndjson"
+	default:
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, apperr.Internal("search.encode.error", "Failed to encode request body").WithCause(err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, i.url+path, reader)
+	if err != nil {
+		return nil, apperr.Internal("search.request.error", "Failed to create request").WithCause(err)
+	}
+	if reader != nil {
+		request.Header.Set("Content-Type", contentType)
+	}
+	if i.username != "" {
+		request.SetBasicAuth(i.username, i.password)
+	}
+	response, err := i.client.Do(request)
+	if err != nil {
+		return nil, apperr.Unavailable("search.request.error", "Failed to reach the search cluster").WithCause(err)
+	}
+	return response, nil
+}
+
+// do sends a request and decodes the response into out when it is not nil.
+// Statuses listed in ignore are not errors and leave out untouched.
+func (i *ElasticsearchIndex) do(ctx context.Context, method, path string, body, out any, ignore ...int) error {
+	response, err := i.send(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if slices.Contains(ignore, response.StatusCode) {
+		return nil
+	}
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		return apperr.Unavailable("search.request.error", "The search cluster responded with an error").
+			WithParam("status", response.StatusCode).
+			WithCause(errors.New(string(message)))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(response.Body).Decode(out); err != nil {
+		return apperr.Internal("search.decode.error", "Failed to decode response").WithCause(err)
+	}
+	return nil
+}
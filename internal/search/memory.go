@@ -0,0 +1,448 @@
+package search
+
+import (
+	"cmp"
+	"context"
+	"maps"
+	"shadowify/internal/ftsearch"
+	"shadowify/internal/model"
+	"shadowify/internal/repository"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Weights of the video fields, in the spirit of the weights of the Postgres
+// search vector.
+const (
+	titleWeight       = 1.0
+	tagsWeight        = 0.4
+	descriptionWeight = 0.2
+)
+
+// MemoryIndex keeps the index in memory. Words match by prefix, or exactly
+// within quotes, but are not stemmed.
+type MemoryIndex struct {
+	mu       sync.RWMutex
+	videos   map[string]*model.Video
+	segments map[string][]*model.Segment
+}
+
+func NewMemoryIndex() *MemoryIndex {
+	return &MemoryIndex{
+		videos:   make(map[string]*model.Video),
+		segments: make(map[string][]*model.Segment),
+	}
+}
+
+func (i *MemoryIndex) IndexVideo(ctx context.Context, video *model.Video) error {
+	v := *video
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.videos[v.Id] = &v
+	return nil
+}
+
+func (i *MemoryIndex) DeleteVideo(ctx context.Context, videoId string) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	delete(i.videos, videoId)
+	delete(i.segments, videoId)
+	return nil
+}
+
+func (i *MemoryIndex) IndexSegments(ctx context.Context, video *model.Video, segments []*model.Segment) error {
+	copies := make([]*model.Segment, len(segments))
+	for j, segment := range segments {
+		s := *segment
+		copies[j] = &s
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.segments[video.Id] = copies
+	return nil
+}
+
+// videoHit is a video matching a search with its score.
+type videoHit struct {
+	video *model.Video
+	score float64
+}
+
+// matchVideos returns the videos matching filter, unordered, leaving out the
+// filter of the facet except.
+func (i *MemoryIndex) matchVideos(filter *model.VideoFilter, except string) ([]videoHit, bool) {
+	terms := parseQuery(filter.Q)
+	var hits []videoHit
+	for _, video := range i.videos {
+		if !matchesFilter(video, filter, except) {
+			continue
+		}
+		hit := videoHit{video: video}
+		if len(terms) > 0 {
+			var ok bool
+			hit.score, ok = match(terms,
+				newField(video.Title, titleWeight),
+				newField(video.FullTitle, titleWeight),
+				newField(strings.Join(video.Tags.Data, " "), tagsWeight),
+				newField(video.Description, descriptionWeight),
+			)
+			if !ok {
+				continue
+			}
+		}
+		hits = append(hits, hit)
+	}
+	return hits, len(terms) > 0
+}
+
+func (i *MemoryIndex) SearchVideos(ctx context.Context, filter *model.VideoFilter) ([]*model.Video, int64, error) {
+	offset, err := pageOffset(&filter.Pagination)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	hits, searching := i.matchVideos(filter, "")
+	slices.SortFunc(hits, func(a, b videoHit) int {
+		switch filter.SortOrder(searching) {
+		case model.VideoRelevance:
+			if c := cmp.Compare(b.score, a.score); c != 0 {
+				return c
+			}
+		case model.VideoPopular:
+			if c := cmp.Compare(b.video.ViewCount, a.video.ViewCount); c != 0 {
+				return c
+			}
+			fallthrough
+		default:
+			if c := b.video.CreatedAt.Compare(a.video.CreatedAt); c != 0 {
+				return c
+			}
+		}
+		return cmp.Compare(b.video.Id, a.video.Id)
+	})
+
+	var total int64
+	if filter.CountTotal() {
+		total = int64(len(hits))
+	}
+	page := window(hits, offset, filter.FetchLimit())
+	videos := make([]*model.Video, len(page))
+	for j, hit := range page {
+		v := *hit.video
+		videos[j] = &v
+	}
+	return trimOffset(&filter.Pagination, videos, offset), total, nil
+}
+
+func (i *MemoryIndex) Facets(ctx context.Context, filter *model.VideoFilter) (*model.VideoFacets, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	facets := &model.VideoFacets{}
+	for _, facet := range []struct {
+		name   string
+		counts *[]*model.FacetCount
+		values func(video *model.Video) []string
+		// byValue orders the counts by value instead of by count
+		byValue bool
+		limit   int
+	}{
+		{name: model.FacetCategories, counts: &facets.Categories, values: func(video *model.Video) []string {
+			return video.Categories.Data
+		}},
+		{name: model.FacetTags, counts: &facets.Tags, limit: repository.MaxFacetValues, values: func(video *model.Video) []string {
+			return video.Tags.Data
+		}},
+		{name: model.FacetDuration, counts: &facets.Duration, byValue: true, values: func(video *model.Video) []string {
+			return []string{string(model.BucketOf(video.Duration))}
+		}},
+		{name: model.FacetCefr, counts: &facets.Cefr, byValue: true, values: func(video *model.Video) []string {
+			return []string{video.Cefr}
+		}},
+		{name: model.FacetChannels, counts: &facets.Channels, limit: repository.MaxFacetValues, values: func(video *model.Video) []string {
+			return []string{video.ChannelId}
+		}},
+		{name: model.FacetHasTranslation, counts: &facets.HasTranslation, values: func(video *model.Video) []string {
+			return []string{strconv.FormatBool(video.HasTranslation())}
+		}},
+	} {
+		hits, _ := i.matchVideos(filter, facet.name)
+		counts := make(map[string]*model.FacetCount)
+		for _, hit := range hits {
+			for _, value := range facet.values(hit.video) {
+				if value == "" {
+					continue
+				}
+				if counts[value] == nil {
+					counts[value] = &model.FacetCount{Value: value}
+				}
+				counts[value].Count++
+				if facet.name == model.FacetChannels {
+					counts[value].Label = hit.video.Channel
+				}
+			}
+		}
+
+		*facet.counts = slices.SortedFunc(maps.Values(counts), func(a, b *model.FacetCount) int {
+			if facet.byValue {
+				return compareFacetValues(facet.name, a.Value, b.Value)
+			}
+			if c := cmp.Compare(b.Count, a.Count); c != 0 {
+				return c
+			}
+			return cmp.Compare(a.Value, b.Value)
+		})
+		if facet.limit > 0 && len(*facet.counts) > facet.limit {
+			*facet.counts = (*facet.counts)[:facet.limit]
+		}
+	}
+	return facets, nil
+}
+
+// compareFacetValues orders durations from shortest to longest and levels
+// from easiest to hardest.
+func compareFacetValues(facet, a, b string) int {
+	if facet == model.FacetDuration {
+		return cmp.Compare(slices.Index(model.DurationBuckets, model.DurationBucket(a)), slices.Index(model.DurationBuckets, model.DurationBucket(b)))
+	}
+	return cmp.Compare(a, b)
+}
+
+func (i *MemoryIndex) SearchTranscripts(ctx context.Context, filter *model.TranscriptFilter) ([]*model.TranscriptMatch, int64, error) {
+	offset, err := pageOffset(&filter.Pagination)
+	if err != nil {
+		return nil, 0, err
+	}
+	terms := parseQuery(&filter.Q)
+	if len(terms) == 0 {
+		return []*model.TranscriptMatch{}, 0, nil
+	}
+
+	type segmentHit struct {
+		segment *model.Segment
+		score   float64
+	}
+	type transcriptHit struct {
+		match *model.TranscriptMatch
+		score float64
+	}
+
+	videoFilter := &model.VideoFilter{Category: filter.Category}
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	var hits []transcriptHit
+	for videoId, segments := range i.segments {
+		video, ok := i.videos[videoId]
+		if !ok || !matchesFilter(video, videoFilter, "") {
+			continue
+		}
+
+		var segmentHits []segmentHit
+		for _, segment := range segments {
+			if filter.Cefr != "" && segment.Cefr != filter.Cefr {
+				continue
+			}
+			if score, ok := match(terms, newField(segment.Content, 1)); ok {
+				segmentHits = append(segmentHits, segmentHit{segment: segment, score: score})
+			}
+		}
+		if len(segmentHits) == 0 {
+			continue
+		}
+
+		// Keep the best segments, in the order they are spoken
+		slices.SortFunc(segmentHits, func(a, b segmentHit) int {
+			if c := cmp.Compare(b.score, a.score); c != 0 {
+				return c
+			}
+			return cmp.Compare(a.segment.StartSec, b.segment.StartSec)
+		})
+		best := segmentHits[:min(len(segmentHits), repository.MatchesPerVideo)]
+		slices.SortFunc(best, func(a, b segmentHit) int {
+			return cmp.Compare(a.segment.StartSec, b.segment.StartSec)
+		})
+
+		v := *video
+		transcript := &model.TranscriptMatch{Video: &v, MatchCount: int64(len(segmentHits))}
+		for _, hit := range best {
+			transcript.Segments = append(transcript.Segments, &model.SegmentMatch{
+				Segment: *hit.segment,
+				Snippet: highlight(hit.segment.Content, terms),
+			})
+		}
+		hits = append(hits, transcriptHit{match: transcript, score: segmentHits[0].score})
+	}
+	slices.SortFunc(hits, func(a, b transcriptHit) int {
+		if c := cmp.Compare(b.score, a.score); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.match.Video.Id, a.match.Video.Id)
+	})
+
+	var total int64
+	if filter.CountTotal() {
+		total = int64(len(hits))
+	}
+	page := window(hits, offset, filter.FetchLimit())
+	matches := make([]*model.TranscriptMatch, len(page))
+	for j, hit := range page {
+		matches[j] = hit.match
+	}
+	return trimOffset(&filter.Pagination, matches, offset), total, nil
+}
+
+func (i *MemoryIndex) Ping(ctx context.Context) error {
+	return nil
+}
+
+// matchesFilter reports whether video passes the filters of filter, leaving
+// out the filter of the facet except. The search is matched separately.
+func matchesFilter(video *model.Video, filter *model.VideoFilter, except string) bool {
+	if categories := filter.CategoryValues(); len(categories) > 0 && except != model.FacetCategories &&
+		!slices.ContainsFunc(categories, func(c string) bool { return slices.Contains(video.Categories.Data, c) }) {
+		return false
+	}
+	if len(filter.Tags) > 0 && except != model.FacetTags &&
+		!slices.ContainsFunc(filter.Tags, func(t string) bool { return slices.Contains(video.Tags.Data, t) }) {
+		return false
+	}
+	if len(filter.Duration) > 0 && except != model.FacetDuration && !slices.Contains(filter.Duration, model.BucketOf(video.Duration)) {
+		return false
+	}
+	if levels := filter.CefrValues(); levels != nil && except != model.FacetCefr && !slices.Contains(levels, video.Cefr) {
+		return false
+	}
+	if filter.ChannelId != "" && except != model.FacetChannels && video.ChannelId != filter.ChannelId {
+		return false
+	}
+	if filter.HasTranslation != nil && except != model.FacetHasTranslation && video.HasTranslation() != *filter.HasTranslation {
+		return false
+	}
+	return true
+}
+
+func parseQuery(q *string) []ftsearch.Term {
+	if q == nil {
+		return nil
+	}
+	return ftsearch.Parse(*q)
+}
+
+// field is the lowercased words of a text with the weight of its matches.
+type field struct {
+	words  []string
+	weight float64
+}
+
+func newField(text string, weight float64) field {
+	return field{words: splitWords(text), weight: weight}
+}
+
+func splitWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// match evaluates terms from left to right against fields. The score sums
+// the weights of the fields each term was found in.
+func match(terms []ftsearch.Term, fields ...field) (float64, bool) {
+	var score float64
+	matched := false
+	for j, term := range terms {
+		var termScore float64
+		for _, f := range fields {
+			if containsTerm(f.words, term) {
+				termScore += f.weight
+			}
+		}
+		ok := termScore > 0
+		if term.Negated {
+			ok = !ok
+			termScore = 0
+		}
+		switch {
+		case j == 0:
+			matched = ok
+		case term.Or:
+			matched = matched || ok
+		default:
+			matched = matched && ok
+		}
+		score += termScore
+	}
+	return score, matched
+}
+
+func containsTerm(words []string, term ftsearch.Term) bool {
+	lexemes := make([]string, len(term.Lexemes))
+	for j, lexeme := range term.Lexemes {
+		lexemes[j] = strings.ToLower(lexeme)
+	}
+	for start := 0; start+len(lexemes) <= len(words); start++ {
+		if !term.IsPhrase() {
+			if strings.HasPrefix(words[start], lexemes[0]) {
+				return true
+			}
+			continue
+		}
+		if slices.Equal(words[start:start+len(lexemes)], lexemes) {
+			return true
+		}
+	}
+	return false
+}
+
+// highlight wraps the words of content matching terms in <mark> tags, like
+// ts_headline does.
+func highlight(content string, terms []ftsearch.Term) string {
+	matches := func(word string) bool {
+		word = strings.ToLower(word)
+		for _, term := range terms {
+			if term.Negated {
+				continue
+			}
+			for _, lexeme := range term.Lexemes {
+				lexeme = strings.ToLower(lexeme)
+				if word == lexeme || (!term.IsPhrase() && strings.HasPrefix(word, lexeme)) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	var b strings.Builder
+	runes := []rune(content)
+	for j := 0; j < len(runes); {
+		if !unicode.IsLetter(runes[j]) && !unicode.IsDigit(runes[j]) {
+			b.WriteRune(runes[j])
+			j++
+			continue
+		}
+		end := j
+		for end < len(runes) && (unicode.IsLetter(runes[end]) || unicode.IsDigit(runes[end])) {
+			end++
+		}
+		word := string(runes[j:end])
+		if matches(word) {
+			word = "<mark>" + word + "</mark>"
+		}
+		b.WriteString(word)
+		j = end
+	}
+	return b.String()
+}
+
+// window returns up to limit items starting at offset.
+func window[T any](items []T, offset, limit int) []T {
+	if offset >= len(items) {
+		return nil
+	}
+	return items[offset:min(len(items), offset+limit)]
+}
@@ -0,0 +1,47 @@
+package search
+
+import (
+	"context"
+	"shadowify/internal/model"
+	"shadowify/internal/repository"
+)
+
+// PostgresIndex searches the videos and segments tables. Their search vectors
+// are generated columns, so there is nothing to index.
+type PostgresIndex struct {
+	videoRepo   *repository.VideoRepository
+	segmentRepo *repository.SegmentRepository
+}
+
+func NewPostgresIndex(videoRepo *repository.VideoRepository, segmentRepo *repository.SegmentRepository) *PostgresIndex {
+	return &PostgresIndex{videoRepo: videoRepo, segmentRepo: segmentRepo}
+}
+
+func (i *PostgresIndex) IndexVideo(ctx context.Context, video *model.Video) error {
+	return nil
+}
+
+func (i *PostgresIndex) DeleteVideo(ctx context.Context, videoId string) error {
+	return nil
+}
+
+func (i *PostgresIndex) IndexSegments(ctx context.Context, video *model.Video, segments []*model.Segment) error {
+	return nil
+}
+
+func (i *PostgresIndex) SearchVideos(ctx context.Context, filter *model.VideoFilter) ([]*model.Video, int64, error) {
+	return i.videoRepo.List(ctx, filter)
+}
+
+func (i *PostgresIndex) Facets(ctx context.Context, filter *model.VideoFilter) (*model.VideoFacets, error) {
+	return i.videoRepo.Facets(ctx, filter)
+}
+
+func (i *PostgresIndex) SearchTranscripts(ctx context.Context, filter *model.TranscriptFilter) ([]*model.TranscriptMatch, int64, error) {
+	return i.segmentRepo.Search(ctx, filter)
+}
+
+// Ping is a no-op, the database has its own health check.
+func (i *PostgresIndex) Ping(ctx context.Context) error {
+	return nil
+}
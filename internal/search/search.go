@@ -0,0 +1,82 @@
+// Package search finds videos by their metadata and transcripts.
+//
+// The Postgres index searches the tables directly through their stored
+// tsvector columns. The Elasticsearch index keeps a copy of the videos and
+// segments, which the ingestion pipeline updates when a video is created and
+// cmd/reindex rebuilds from the database. The memory index is meant for
+// tests.
+//
+// All indexes read search input the same way, see ftsearch.Parse.
+package search
+
+import (
+	"context"
+	"fmt"
+	"shadowify/internal/config"
+	"shadowify/internal/model"
+	"shadowify/internal/pagination"
+	"shadowify/internal/repository"
+)
+
+type Index interface {
+	// IndexVideo adds or replaces a video.
+	IndexVideo(ctx context.Context, video *model.Video) error
+	// DeleteVideo removes a video and its segments.
+	DeleteVideo(ctx context.Context, videoId string) error
+	// IndexSegments replaces the segments of a video.
+	IndexSegments(ctx context.Context, video *model.Video, segments []*model.Segment) error
+	// SearchVideos lists the videos matching filter.
+	SearchVideos(ctx context.Context, filter *model.VideoFilter) ([]*model.Video, int64, error)
+	// Facets counts the videos matching filter per category and CEFR level.
+	Facets(ctx context.Context, filter *model.VideoFilter) (*model.VideoFacets, error)
+	// SearchTranscripts finds the videos where the words of filter.Q are
+	// spoken.
+	SearchTranscripts(ctx context.Context, filter *model.TranscriptFilter) ([]*model.TranscriptMatch, int64, error)
+	// Ping checks that the index is reachable.
+	Ping(ctx context.Context) error
+}
+
+// New creates the index selected by cfg.Backend.
+func New(cfg config.SearchConfig, videoRepo *repository.VideoRepository, segmentRepo *repository.SegmentRepository) (Index, error) {
+	switch cfg.Backend {
+	case "", "postgres":
+		return NewPostgresIndex(videoRepo, segmentRepo), nil
+	case "elasticsearch", "opensearch":
+		return NewElasticsearchIndex(cfg.Elasticsearch), nil
+	case "memory":
+		return NewMemoryIndex(), nil
+	default:
+		return nil, fmt.Errorf("unknown search backend %q", cfg.Backend)
+	}
+}
+
+// offsetCursor continues a list ordered by score, for indexes that cannot
+// seek to a sort key.
+type offsetCursor struct {
+	Offset int `json:"o"`
+}
+
+// pageOffset returns the number of items to skip for p, read from the cursor
+// when continuing from one.
+func pageOffset(p *pagination.Pagination) (int, error) {
+	offset := p.Offset()
+	if p.IsCursor() {
+		var cursor offsetCursor
+		if err := pagination.DecodeCursor(p.Cursor, &cursor); err != nil {
+			return 0, err
+		}
+		if cursor.Offset < 0 {
+			return 0, pagination.InvalidCursor()
+		}
+		offset = cursor.Offset
+	}
+	return offset, nil
+}
+
+// trimOffset trims items queried from offset with FetchLimit and issues the
+// cursor of the next page.
+func trimOffset[T any](p *pagination.Pagination, items []T, offset int) []T {
+	return pagination.Trim(p, items, func(T) any {
+		return offsetCursor{Offset: offset + p.Limit()}
+	})
+}
@@ -0,0 +1,305 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"shadowify/internal/config"
+	"shadowify/internal/database"
+	"shadowify/internal/ftsearch"
+	"shadowify/internal/model"
+	"shadowify/internal/pagination"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newVideo(id, title, cefr string, views int64, categories ...string) *model.Video {
+	video := &model.Video{
+		Title:      title,
+		Cefr:       cefr,
+		ViewCount:  views,
+		Categories: database.JSONType[[]string]{Data: categories},
+	}
+	video.Id = id
+	video.CreatedAt = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(len(id)) * time.Hour)
+	return video
+}
+
+func newIndex(t *testing.T) *MemoryIndex {
+	index := NewMemoryIndex()
+	ctx := context.Background()
+	videos := []*model.Video{
+		newVideo("a", "Cooking pasta at home", "A2", 10, "Food"),
+		newVideo("bb", "Running a marathon", "B1", 50, "Sports"),
+		newVideo("ccc", "Cooking for runners", "B1", 5, "Food", "Sports"),
+	}
+	for _, video := range videos {
+		require.NoError(t, index.IndexVideo(ctx, video))
+	}
+	require.NoError(t, index.IndexSegments(ctx, videos[0], []*model.Segment{
+		{VideoId: "a", StartSec: 5, Content: "Boil the water first.", Cefr: "A1"},
+		{VideoId: "a", StartSec: 1, Content: "On the other hand, you can bake it.", Cefr: "B1"},
+		{VideoId: "a", StartSec: 9, Content: "Then drain the pasta.", Cefr: "A1"},
+	}))
+	require.NoError(t, index.IndexSegments(ctx, videos[1], []*model.Segment{
+		{VideoId: "bb", StartSec: 3, Content: "Keep running, on the other hand slow down.", Cefr: "B2"},
+	}))
+	return index
+}
+
+func ids(videos []*model.Video) []string {
+	result := make([]string, len(videos))
+	for i, video := range videos {
+		result[i] = video.Id
+	}
+	return result
+}
+
+func TestMemoryIndex_SearchVideos(t *testing.T) {
+	index := newIndex(t)
+	ctx := context.Background()
+
+	q := "cook"
+	videos, total, err := index.SearchVideos(ctx, &model.VideoFilter{Q: &q})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.ElementsMatch(t, []string{"a", "ccc"}, ids(videos))
+
+	q = "cooking -pasta"
+	videos, _, err = index.SearchVideos(ctx, &model.VideoFilter{Q: &q})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ccc"}, ids(videos))
+
+	q = "pasta or marathon"
+	videos, _, err = index.SearchVideos(ctx, &model.VideoFilter{Q: &q, Type: model.VideoPopular})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bb", "a"}, ids(videos))
+
+	category := "Sports"
+	videos, _, err = index.SearchVideos(ctx, &model.VideoFilter{Category: &category, Cefr: "B1"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ccc", "bb"}, ids(videos), "recent first when browsing")
+}
+
+func TestMemoryIndex_SearchVideos_Cursor(t *testing.T) {
+	index := newIndex(t)
+	ctx := context.Background()
+
+	filter := &model.VideoFilter{Pagination: pagination.Pagination{PageSize: 2}}
+	videos, _, err := index.SearchVideos(ctx, filter)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ccc", "bb"}, ids(videos))
+	require.True(t, filter.HasMore)
+
+	filter = &model.VideoFilter{Pagination: pagination.Pagination{PageSize: 2, Cursor: filter.NextCursor}}
+	videos, total, err := index.SearchVideos(ctx, filter)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a"}, ids(videos))
+	assert.False(t, filter.HasMore)
+	assert.Zero(t, total, "cursor pages skip counting")
+}
+
+func TestMemoryIndex_Facets(t *testing.T) {
+	index := newIndex(t)
+
+	facets, err := index.Facets(context.Background(), &model.VideoFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, []*model.FacetCount{{Value: "Food", Count: 2}, {Value: "Sports", Count: 2}}, facets.Categories)
+	assert.Equal(t, []*model.FacetCount{{Value: "A2", Count: 1}, {Value: "B1", Count: 2}}, facets.Cefr)
+}
+
+func TestMemoryIndex_FacetFilters(t *testing.T) {
+	index := newIndex(t)
+	ctx := context.Background()
+	short := newVideo("dddd", "Quick tips", "C1", 1, "Education")
+	short.Duration = 90
+	short.ChannelId = "UC1"
+	short.Channel = "Tips"
+	short.SubtitleLanguages.Data = []string{"en", model.TranslationLanguage}
+	require.NoError(t, index.IndexVideo(ctx, short))
+
+	hasTranslation := true
+	filter := &model.VideoFilter{Duration: []model.DurationBucket{model.DurationShort}, HasTranslation: &hasTranslation}
+	videos, _, err := index.SearchVideos(ctx, filter)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dddd"}, ids(videos))
+
+	filter = &model.VideoFilter{Categories: []string{"Food", "Education"}, CefrMin: "B1"}
+	videos, _, err = index.SearchVideos(ctx, filter)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dddd", "ccc"}, ids(videos))
+
+	facets, err := index.Facets(ctx, &model.VideoFilter{Categories: []string{"Education"}})
+	require.NoError(t, err)
+	assert.Equal(t, []*model.FacetCount{{Value: "Food", Count: 2}, {Value: "Sports", Count: 2}, {Value: "Education", Count: 1}}, facets.Categories,
+		"a facet ignores its own filter")
+	assert.Equal(t, []*model.FacetCount{{Value: "C1", Count: 1}}, facets.Cefr)
+	assert.Equal(t, []*model.FacetCount{{Value: "UC1", Label: "Tips", Count: 1}}, facets.Channels)
+	assert.Equal(t, []*model.FacetCount{{Value: "short", Count: 1}}, facets.Duration)
+	assert.Equal(t, []*model.FacetCount{{Value: "true", Count: 1}}, facets.HasTranslation)
+}
+
+func TestMemoryIndex_SearchTranscripts(t *testing.T) {
+	index := newIndex(t)
+	ctx := context.Background()
+
+	matches, total, err := index.SearchTranscripts(ctx, &model.TranscriptFilter{Q: `"on the other hand"`})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	require.Len(t, matches, 2)
+	for _, match := range matches {
+		require.Len(t, match.Segments, 1)
+		assert.Contains(t, match.Segments[0].Snippet, "<mark>other</mark> <mark>hand</mark>")
+	}
+
+	matches, _, err = index.SearchTranscripts(ctx, &model.TranscriptFilter{Q: "the", Cefr: "A1"})
+	require.NoError(t, err)
+	require.Len(t, matches, 1, "the level filters segments, not videos")
+	assert.Equal(t, "a", matches[0].Video.Id)
+	assert.Equal(t, int64(2), matches[0].MatchCount)
+	require.Len(t, matches[0].Segments, 2)
+	assert.Equal(t, float32(5), matches[0].Segments[0].StartSec, "segments are in spoken order")
+	for _, segment := range matches[0].Segments {
+		assert.Equal(t, "A1", segment.Cefr)
+	}
+
+	require.NoError(t, index.DeleteVideo(ctx, "a"))
+	matches, _, err = index.SearchTranscripts(ctx, &model.TranscriptFilter{Q: "water"})
+	require.NoError(t, err)
+	assert.Empty(t, matches)
+}
+
+func TestSimpleQueryString(t *testing.T) {
+	terms := ftsearch.Parse(`Cooking "other hand" or pasta -cheese`)
+	assert.Equal(t, `cooking* + "other hand" | pasta* + -cheese*`, simpleQueryString(terms))
+}
+
+func TestElasticsearchIndex_SearchVideos(t *testing.T) {
+	var request map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/test_videos/_search", r.URL.Path)
+		body, _ := io.ReadAll(r.Body)
+		request = nil
+		require.NoError(t, json.Unmarshal(body, &request))
+		io.WriteString(w, `{"hits": {"total": {"value": 3}, "hits": [
+			{"_id": "a", "_source": {"id": "a", "title": "One"}, "sort": [2.5, "a"]},
+			{"_id": "b", "_source": {"id": "b", "title": "Two"}, "sort": [1.5, "b"]}
+		]}}`)
+	}))
+	defer server.Close()
+	index := NewElasticsearchIndex(config.ElasticsearchConfig{URL: server.URL, IndexPrefix: "test_"})
+
+	q := "hello"
+	filter := &model.VideoFilter{Q: &q, Cefr: "B1", Pagination: pagination.Pagination{PageSize: 1}}
+	videos, total, err := index.SearchVideos(context.Background(), filter)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+	assert.Equal(t, []string{"a"}, ids(videos))
+	assert.Equal(t, float64(2), request["size"])
+	assert.Contains(t, mustJSON(t, request["query"]), `"query":"hello*"`)
+	assert.Contains(t, mustJSON(t, request["query"]), `{"terms":{"cefr":["B1"]}}`)
+	require.True(t, filter.HasMore)
+
+	filter = &model.VideoFilter{Q: &q, Pagination: pagination.Pagination{Cursor: filter.NextCursor}}
+	_, _, err = index.SearchVideos(context.Background(), filter)
+	require.NoError(t, err)
+	assert.Equal(t, []any{2.5, "a"}, request["search_after"])
+	assert.Nil(t, request["from"])
+
+	filter = &model.VideoFilter{Type: model.VideoPopular, Pagination: pagination.Pagination{Cursor: filter.Cursor}}
+	_, _, err = index.SearchVideos(context.Background(), filter)
+	assert.Error(t, err, "cursors are bound to their sort order")
+}
+
+func TestElasticsearchIndex_Facets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Contains(t, string(body), `"channels":{"aggs":{"values"`)
+		io.WriteString(w, `{"hits": {"hits": []}, "aggregations": {
+			"cefr": {"values": {"buckets": [{"key": "B1", "doc_count": 2}, {"key": "A2", "doc_count": 1}, {"key": "", "doc_count": 4}]}},
+			"duration": {"values": {"buckets": [{"key": "short", "doc_count": 3}, {"key": "medium", "doc_count": 0}]}},
+			"channels": {"values": {"buckets": [{"key": "UC1", "doc_count": 5, "label": {"buckets": [{"key": "Tips"}]}}]}},
+			"has_translation": {"values": {"buckets": {"false": {"doc_count": 2}, "true": {"doc_count": 1}}}}
+		}}`)
+	}))
+	defer server.Close()
+	index := NewElasticsearchIndex(config.ElasticsearchConfig{URL: server.URL})
+
+	facets, err := index.Facets(context.Background(), &model.VideoFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, []*model.FacetCount{{Value: "A2", Count: 1}, {Value: "B1", Count: 2}}, facets.Cefr)
+	assert.Equal(t, []*model.FacetCount{{Value: "short", Count: 3}}, facets.Duration)
+	assert.Equal(t, []*model.FacetCount{{Value: "UC1", Label: "Tips", Count: 5}}, facets.Channels)
+	assert.Equal(t, []*model.FacetCount{{Value: "true", Count: 1}, {Value: "false", Count: 2}}, facets.HasTranslation)
+	assert.Empty(t, facets.Categories)
+}
+
+func TestElasticsearchIndex_IndexSegments(t *testing.T) {
+	var bulk string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/test_segments/_delete_by_query":
+			io.WriteString(w, `{"deleted": 0}`)
+		case "/_bulk":
+			assert.Equal(t, "application/x-ndjson", r.Header.Get("Content-Type"))
+			body, _ := io.ReadAll(r.Body)
+			bulk = string(body)
+			io.WriteString(w, `{"errors": true, "items": [{"index": {"error": {"type": "mapper_parsing_exception"}}}]}`)
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+	index := NewElasticsearchIndex(config.ElasticsearchConfig{URL: server.URL, IndexPrefix: "test_"})
+
+	video := newVideo("v", "Title", "B2", 0, "Music")
+	segment := &model.Segment{Content: "Hello"}
+	segment.Id = "s"
+	err := index.IndexSegments(context.Background(), video, []*model.Segment{segment})
+	assert.Error(t, err)
+
+	lines := strings.Split(strings.TrimSpace(bulk), "\n")
+	require.Len(t, lines, 2)
+	assert.JSONEq(t, `{"index": {"_index": "test_segments", "_id": "s"}}`, lines[0])
+	assert.Contains(t, lines[1], `"video_id":"v"`)
+	assert.Contains(t, lines[1], `"video_categories":["Music"]`)
+}
+
+func mustJSON(t *testing.T, v any) string {
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestMemoryIndex_FilterByVideoLevel(t *testing.T) {
+	index := NewMemoryIndex()
+	ctx := context.Background()
+	segments := []*model.Segment{
+		{Content: "Hello there.", Cefr: "A1"},
+		{Content: "The committee postponed its decision.", Cefr: "B2"},
+		{Content: "We argued about it for hours.", Cefr: "B1"},
+	}
+	video := newVideo("v", "Office meeting", "", 0)
+	video.Cefr = model.SegmentsLevel(segments)
+	require.NoError(t, index.IndexVideo(ctx, video))
+	require.NoError(t, index.IndexSegments(ctx, video, segments))
+
+	for _, filter := range []*model.VideoFilter{{Cefr: "B1"}, {CefrMin: "A2", CefrMax: "B1"}} {
+		videos, _, err := index.SearchVideos(ctx, filter)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"v"}, ids(videos))
+	}
+	videos, _, err := index.SearchVideos(ctx, &model.VideoFilter{CefrMin: "B2"})
+	require.NoError(t, err)
+	assert.Empty(t, videos)
+
+	facets, err := index.Facets(ctx, &model.VideoFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, []*model.FacetCount{{Value: "B1", Count: 1}}, facets.Cefr)
+}
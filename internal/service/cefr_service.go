@@ -0,0 +1,111 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"shadowify/internal/apperr"
+	"shadowify/internal/config"
+	"shadowify/internal/metrics"
+	"strings"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+const defaultCEFRURL = "http://localhost:5050"
+
+// CEFRService talks to the CEFR classifier that predicts the level of
+// English sentences.
+type CEFRService struct {
+	url    string
+	client *http.Client
+}
+
+func NewCEFRService(cfg config.CEFRConfig) *CEFRService {
+	if cfg.URL == "" {
+		cfg.URL = defaultCEFRURL
+	}
+	return &CEFRService{
+		url:    strings.TrimSuffix(cfg.URL, "/"),
+		client: &http.Client{Transport: otelhttp.NewTransport(metrics.NewTransport("cefr", http.DefaultTransport))},
+	}
+}
+
+// Predict returns the CEFR level of each sentence, in order.
+func (s *CEFRService) Predict(ctx context.Context, sentences []string) ([]string, error) {
+	var requestBody struct {
+		Sentences []string `json:"sentences"`
+	}
+	requestBody.Sentences = sentences
+
+	requestBodyByte, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, apperr.NewAppErr("request.encode.error", "Failed to encode request body").WithCause(err)
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url+"/predict", bytes.NewBuffer(requestBodyByte))
+	if err != nil {
+		return nil, apperr.NewAppErr("request.create.error", "Failed to create request").WithCause(err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	response, err := s.client.Do(request)
+	if err != nil {
+		return nil, apperr.Unavailable("cefr.request.error", "Failed to reach the CEFR classifier").WithCause(err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, apperr.Unavailable("cefr.request.error", "The CEFR classifier responded with an error").
+			WithParam("status", response.StatusCode)
+	}
+	bodyBytes, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, apperr.NewAppErr("response.read.error", "Failed to read response").WithCause(err)
+	}
+
+	var responseBody []struct {
+		Cefr     string `json:"cefr"`
+		Sentence string `json:"sentence"`
+	}
+	if err := json.Unmarshal(bodyBytes, &responseBody); err != nil {
+		return nil, apperr.NewAppErr("response.decode.error", "Failed to decode response").WithCause(err)
+	}
+	if len(responseBody) != len(sentences) {
+		return nil, apperr.NewAppErr("response.decode.error", "Unexpected number of predictions").
+			WithParam("expected", len(sentences)).
+			WithParam("actual", len(responseBody))
+	}
+
+	levels := make([]string, len(responseBody))
+	for i, prediction := range responseBody {
+		levels[i] = prediction.Cefr
+	}
+	return levels, nil
+}
+
+// Ping checks that the classifier is reachable.
+func (s *CEFRService) Ping(ctx context.Context) error {
+	return pingHTTP(ctx, s.client, s.url)
+}
+
+// pingHTTP reports whether a server answers at url. Any response below 500
+// counts, since only reachability is being checked.
+func pingHTTP(ctx context.Context, client *http.Client, url string) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, response.Body)
+
+	if response.StatusCode >= http.StatusInternalServerError {
+		return apperr.Unavailable("request.execute.error", "Server responded with an error").
+			WithParam("status", response.StatusCode)
+	}
+	return nil
+}
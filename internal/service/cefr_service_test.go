@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"shadowify/internal/config"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCEFRService_Predict(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		assert.Equal(t, "/predict", r.URL.Path)
+		var body struct {
+			Sentences []string `json:"sentences"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		predictions := []map[string]string{}
+		for _, sentence := range body.Sentences {
+			predictions = append(predictions, map[string]string{"cefr": "A1", "sentence": sentence})
+		}
+		json.NewEncoder(w).Encode(predictions)
+	}))
+	defer server.Close()
+
+	service := NewCEFRService(config.CEFRConfig{URL: server.URL + "/"})
+	levels, err := service.Predict(context.Background(), []string{"Hello.", "How are you?"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"A1", "A1"}, levels)
+	assert.NoError(t, service.Ping(context.Background()))
+}
+
+func TestCEFRService_PredictMismatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	service := NewCEFRService(config.CEFRConfig{URL: server.URL})
+	_, err := service.Predict(context.Background(), []string{"Hello."})
+	assert.Error(t, err)
+}
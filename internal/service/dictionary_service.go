@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"shadowify/internal/apperr"
+	"shadowify/internal/model"
+	"shadowify/internal/nlp"
+	"shadowify/internal/repository"
+	"strings"
+)
+
+type DictionaryService struct {
+	repo *repository.DictionaryRepository
+}
+
+func NewDictionaryService(repo *repository.DictionaryRepository) *DictionaryService {
+	return &DictionaryService{repo: repo}
+}
+
+// Lookup returns the dictionary entries of a word, or of its lemma when the
+// word is an inflected form such as "ran".
+func (s *DictionaryService) Lookup(ctx context.Context, word string) ([]*model.DictionaryEntry, error) {
+	word = strings.TrimSpace(word)
+	lemma := nlp.Normalize(word)
+	if lemma == "" {
+		return nil, apperr.Validation("validation.required", "Word is required").WithField("word")
+	}
+
+	entries, err := s.repo.FindByWord(ctx, word, lemma)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, apperr.NotFound("dictionary.not_found", "The word is not in the dictionary").WithParam("word", word)
+	}
+	return entries, nil
+}
+
+// Enrich sets the dictionary entries of saved words by their lemma.
+func (s *DictionaryService) Enrich(ctx context.Context, words ...*model.Word) error {
+	lemmas := make([]string, 0, len(words))
+	for _, word := range words {
+		lemmas = append(lemmas, word.Lemma)
+	}
+	entries, err := s.repo.FindByLemmas(ctx, lemmas)
+	if err != nil {
+		return err
+	}
+	byLemma := make(map[string][]*model.DictionaryEntry)
+	for _, entry := range entries {
+		byLemma[entry.Lemma] = append(byLemma[entry.Lemma], entry)
+	}
+	for _, word := range words {
+		word.Dictionary = byLemma[word.Lemma]
+	}
+	return nil
+}
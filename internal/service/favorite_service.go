@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"shadowify/internal/apperr"
 	"shadowify/internal/model"
 	"shadowify/internal/repository"
 )
@@ -29,12 +30,12 @@ func (s *FavoriteService) Create(ctx context.Context, userId string, videoId str
 }
 
 func (s *FavoriteService) Delete(ctx context.Context, userId string, videoId string) error {
-	favorite, err := s.favoriteRepository.FindByUserIdAndVideoId(ctx, userId, videoId)
+	_, err := s.favoriteRepository.FindByUserIdAndVideoId(ctx, userId, videoId)
+	if apperr.Is(err, apperr.KindNotFound) {
+		return nil // No favorite found to delete
+	}
 	if err != nil {
 		return err
 	}
-	if favorite == nil {
-		return nil // No favorite found to delete
-	}
 	return s.favoriteRepository.Delete(ctx, userId, videoId)
 }
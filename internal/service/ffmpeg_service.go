@@ -0,0 +1,131 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"shadowify/internal/runner"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type FFmpegService struct {
+	runner runner.Runner
+}
+
+func NewFFmpegService(runner runner.Runner) *FFmpegService {
+	return &FFmpegService{runner: runner}
+}
+
+// ConvertToWav converts an audio file to 16kHz mono wav as expected by whisper.
+func (s *FFmpegService) ConvertToWav(ctx context.Context, inputPath, outputPath string) error {
+	_, err := s.runner.Run(ctx, runner.Command{
+		Name: "ffmpeg",
+		Args: []string{"-hide_banner", "-loglevel", "error", "-y", "-i", inputPath,
+			"-ar", "16000", "-ac", "1", "-c:a", "pcm_s16le", outputPath},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to convert to wav: %w", err)
+	}
+	return nil
+}
+
+var errDecoderStopped = errors.New("ffmpeg stopped before the end of the stream")
+
+// PCMDecoder decodes an audio stream to 16kHz mono 16-bit PCM as it is
+// written, with a single ffmpeg process for the whole stream. Each chunk is
+// decoded once, and container formats such as WebM work because ffmpeg sees
+// the stream from its start.
+type PCMDecoder struct {
+	input *io.PipeWriter
+	done  chan struct{}
+
+	mu     sync.Mutex
+	output []byte
+	err    error
+}
+
+// NewPCMDecoder starts decoding a stream. When sampleRate is set the input is
+// treated as raw 16-bit PCM at that rate. The process lives until Close,
+// ctx is cancelled or timeout elapses.
+func (s *FFmpegService) NewPCMDecoder(ctx context.Context, sampleRate int, timeout time.Duration) *PCMDecoder {
+	args := []string{"-hide_banner", "-loglevel", "error"}
+	if sampleRate > 0 {
+		args = append(args, "-f", "s16le", "-ar", strconv.Itoa(sampleRate), "-ac", "1")
+	}
+	args = append(args, "-i", "pipe:0", "-f", "s16le", "-ar", "16000", "-ac", "1", "pipe:1")
+
+	reader, writer := io.Pipe()
+	d := &PCMDecoder{input: writer, done: make(chan struct{})}
+	go func() {
+		defer close(d.done)
+		_, err := s.runner.Run(ctx, runner.Command{
+			Name:    "ffmpeg",
+			Args:    args,
+			Stdin:   reader,
+			Stdout:  pcmSink{d},
+			Timeout: timeout,
+		})
+		if err != nil {
+			err = fmt.Errorf("failed to decode audio: %w", err)
+		}
+		d.mu.Lock()
+		d.err = err
+		d.mu.Unlock()
+		// Unblock a Write waiting for ffmpeg to read
+		reader.CloseWithError(cmp.Or(err, errDecoderStopped))
+	}()
+	return d
+}
+
+// Write feeds a chunk of the stream to ffmpeg. It fails once ffmpeg stopped,
+// for instance because the stream cannot be decoded.
+func (d *PCMDecoder) Write(chunk []byte) error {
+	_, err := d.input.Write(chunk)
+	return err
+}
+
+// PCM returns the audio decoded so far, in whole samples, and the error that
+// stopped ffmpeg if it stopped before Close.
+func (d *PCMDecoder) PCM() ([]byte, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.output[:len(d.output)&^1], d.err
+}
+
+// Close ends the stream, waits for ffmpeg to flush the rest of the audio and
+// returns all of it.
+func (d *PCMDecoder) Close() ([]byte, error) {
+	d.input.Close()
+	<-d.done
+	return d.PCM()
+}
+
+// pcmSink collects the output of a PCMDecoder.
+type pcmSink struct {
+	d *PCMDecoder
+}
+
+func (w pcmSink) Write(p []byte) (int, error) {
+	w.d.mu.Lock()
+	defer w.d.mu.Unlock()
+	w.d.output = append(w.d.output, p...)
+	return len(p), nil
+}
+
+// Version returns the first line of `ffmpeg -version`.
+func (s *FFmpegService) Version(ctx context.Context) (string, error) {
+	result, err := s.runner.Run(ctx, runner.Command{
+		Name: "ffmpeg",
+		Args: []string{"-hide_banner", "-version"},
+	})
+	if err != nil {
+		return "", err
+	}
+	version, _, _ := strings.Cut(string(result.Stdout), "\n")
+	return strings.TrimSpace(version), nil
+}
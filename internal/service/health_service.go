@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"runtime"
+	"shadowify/internal/config"
+	"shadowify/internal/database"
+	"shadowify/internal/logger"
+	"shadowify/internal/model"
+	"shadowify/internal/procpool"
+	"shadowify/internal/search"
+	"shadowify/internal/workspace"
+	"slices"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultHealthTimeout  = 5 * time.Second
+	defaultHealthCacheTTL = 5 * time.Second
+)
+
+// healthCheck probes a dependency and returns its version when known.
+type healthCheck struct {
+	name  string
+	probe func(ctx context.Context) (string, error)
+}
+
+type HealthService struct {
+	cfg config.HealthConfig
+	// readiness are the cheap checks of the dependencies every request
+	// needs. checks adds the tools and external services, which cost a
+	// process or an outbound call each.
+	readiness   []healthCheck
+	checks      []healthCheck
+	whisperPool *procpool.Pool
+	workspaces  *workspace.Manager
+	startedAt   time.Time
+
+	mu    sync.Mutex
+	ready *model.HealthReport
+}
+
+func NewHealthService(cfg config.HealthConfig, db *gorm.DB, whisperPool *procpool.Pool, workspaces *workspace.Manager, whisperService *WhisperService, ffmpegService *FFmpegService, ytDLPService *YTDLPService, cefrService *CEFRService, translatorService *TranslatorService, searchIndex search.Index) *HealthService {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultHealthTimeout
+	}
+	if cfg.CacheTTL <= 0 {
+		cfg.CacheTTL = defaultHealthCacheTTL
+	}
+	readiness := []healthCheck{
+		{name: "database", probe: func(ctx context.Context) (string, error) {
+			return "", database.Ping(ctx, db)
+		}},
+		{name: "search", probe: func(ctx context.Context) (string, error) {
+			return "", searchIndex.Ping(ctx)
+		}},
+		{name: "workspace", probe: func(ctx context.Context) (string, error) {
+			return "", workspaces.Check()
+		}},
+	}
+	return &HealthService{
+		cfg:       cfg,
+		readiness: readiness,
+		checks: append(slices.Clone(readiness),
+			healthCheck{name: "yt-dlp", probe: ytDLPService.Version},
+			healthCheck{name: "ffmpeg", probe: ffmpegService.Version},
+			healthCheck{name: "whisper-cli", probe: whisperService.CheckInstall},
+			healthCheck{name: "cefr", probe: func(ctx context.Context) (string, error) {
+				return "", cefrService.Ping(ctx)
+			}},
+			healthCheck{name: "translator", probe: func(ctx context.Context) (string, error) {
+				return "", translatorService.Ping(ctx)
+			}},
+		),
+		whisperPool: whisperPool,
+		workspaces:  workspaces,
+		startedAt:   time.Now(),
+	}
+}
+
+// Ready runs the readiness checks. The report is reused for CacheTTL, so
+// that frequent probes from orchestrators and anonymous callers run the checks
+// at most once per TTL.
+func (s *HealthService) Ready(ctx context.Context) *model.HealthReport {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ready == nil || time.Since(s.ready.CheckedAt) >= s.cfg.CacheTTL {
+		// Shared with the callers waiting on mu, so not canceled with the
+		// request that runs it
+		s.ready = s.check(context.WithoutCancel(ctx), s.readiness)
+	}
+	return s.ready
+}
+
+// Check probes every dependency concurrently, including the tools and
+// external services. The report is up only when all checks pass.
+func (s *HealthService) Check(ctx context.Context) *model.HealthReport {
+	return s.check(ctx, s.checks)
+}
+
+func (s *HealthService) check(ctx context.Context, checks []healthCheck) *model.HealthReport {
+	report := &model.HealthReport{
+		Status:    model.HealthStatusUp,
+		CheckedAt: time.Now(),
+		Checks:    make([]*model.HealthCheck, len(checks)),
+	}
+
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			report.Checks[i] = s.run(ctx, check)
+		}()
+	}
+	wg.Wait()
+
+	for _, check := range report.Checks {
+		if check.Status != model.HealthStatusUp {
+			report.Status = model.HealthStatusDown
+		}
+	}
+	return report
+}
+
+func (s *HealthService) run(ctx context.Context, check healthCheck) *model.HealthCheck {
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	version, err := check.probe(ctx)
+	result := &model.HealthCheck{
+		Name:      check.name,
+		Status:    model.HealthStatusUp,
+		Version:   version,
+		LatencyMs: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		logger.WithContext(ctx).WithFields(logger.Fields{"check": check.name, "error": err.Error()}).Error("Health check failed")
+		result.Status = model.HealthStatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// Diagnostics returns the full check report along with runtime and resource
+// usage details.
+func (s *HealthService) Diagnostics(ctx context.Context) *model.Diagnostics {
+	usage, err := s.workspaces.Usage()
+	if err != nil {
+		logger.WithContext(ctx).WithFields(logger.Fields{"error": err.Error()}).Error("Failed to compute workspace usage")
+	}
+	return &model.Diagnostics{
+		HealthReport:   s.Check(ctx),
+		Uptime:         time.Since(s.startedAt).Round(time.Second).String(),
+		GoVersion:      runtime.Version(),
+		Goroutines:     runtime.NumGoroutine(),
+		WhisperPool:    s.whisperPool.Stats(),
+		WorkspaceUsage: usage,
+	}
+}
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"shadowify/internal/config"
+	"shadowify/internal/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthService_ReadyCached(t *testing.T) {
+	probes := 0
+	var probeErr error
+	s := &HealthService{
+		cfg: config.HealthConfig{Timeout: time.Second, CacheTTL: time.Hour},
+		readiness: []healthCheck{{name: "database", probe: func(ctx context.Context) (string, error) {
+			probes++
+			return "", probeErr
+		}}},
+	}
+
+	report := s.Ready(context.Background())
+	assert.Equal(t, model.HealthStatusUp, report.Status)
+	probeErr = errors.New("connection refused")
+	assert.Equal(t, model.HealthStatusUp, s.Ready(context.Background()).Status, "the report is reused within the TTL")
+	assert.Equal(t, 1, probes)
+
+	s.cfg.CacheTTL = time.Nanosecond
+	assert.Equal(t, model.HealthStatusDown, s.Ready(context.Background()).Status)
+	assert.Equal(t, 2, probes)
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"shadowify/i18n"
+	"shadowify/internal/model"
+	"shadowify/internal/repository"
+	"shadowify/internal/tracing"
+)
+
+// maxAffinityCategories caps the favorite categories a video is matched
+// against.
+const maxAffinityCategories = 5
+
+type RecommendationService struct {
+	videoRepo    *repository.VideoRepository
+	practiceRepo *repository.PracticeRepository
+	favoriteRepo *repository.FavoriteRepository
+}
+
+func NewRecommendationService(videoRepo *repository.VideoRepository, practiceRepo *repository.PracticeRepository, favoriteRepo *repository.FavoriteRepository) *RecommendationService {
+	return &RecommendationService{
+		videoRepo:    videoRepo,
+		practiceRepo: practiceRepo,
+		favoriteRepo: favoriteRepo,
+	}
+}
+
+// Profile estimates the level of a user from their practice attempts and
+// saved words and sentences, and collects the categories they favor. Users
+// without an id get an empty profile.
+func (s *RecommendationService) Profile(ctx context.Context, userId string) (*model.LearnerProfile, error) {
+	profile := &model.LearnerProfile{}
+	if userId == "" {
+		return profile, nil
+	}
+
+	counts, err := s.practiceRepo.LevelCounts(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	profile.Level = model.EstimateLevel(counts)
+
+	profile.Categories, err = s.favoriteRepo.TopCategories(ctx, userId, maxAffinityCategories)
+	if err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// Recommend lists the videos matching filter that suit a user best, each
+// explained in the locale of the request.
+func (s *RecommendationService) Recommend(ctx context.Context, userId string, filter *model.VideoFilter) ([]*model.RecommendedVideo, int64, error) {
+	ctx, span := tracing.Start(ctx, "RecommendationService.Recommend")
+	defer span.End()
+
+	profile, err := s.Profile(ctx, userId)
+	if err != nil {
+		return nil, 0, err
+	}
+	videos, total, err := s.videoRepo.Recommend(ctx, userId, profile, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	locale := i18n.FromContext(ctx)
+	params := map[string]any{"level": profile.Level}
+	for _, video := range videos {
+		video.Explanation, _ = i18n.Translate(locale, string(video.Reasons[0]), params)
+	}
+	return videos, total, nil
+}
@@ -4,14 +4,16 @@ import (
 	"context"
 	"shadowify/internal/model"
 	"shadowify/internal/repository"
+	"shadowify/internal/search"
 )
 
 type SegmentService struct {
-	repo *repository.SegmentRepository
+	repo  *repository.SegmentRepository
+	index search.Index
 }
 
-func NewSegmentService(repo *repository.SegmentRepository) *SegmentService {
-	return &SegmentService{repo: repo}
+func NewSegmentService(repo *repository.SegmentRepository, index search.Index) *SegmentService {
+	return &SegmentService{repo: repo, index: index}
 }
 
 // GetSegmentsByVideoID retrieves all segments for a given video ID
@@ -22,3 +24,8 @@ func (s *SegmentService) GetSegmentsByVideoID(ctx context.Context, videoID strin
 func (s *SegmentService) GetSegmentByID(ctx context.Context, id string) (*model.Segment, error) {
 	return s.repo.FindById(ctx, id)
 }
+
+// SearchTranscripts finds the videos where the words of filter.Q are spoken.
+func (s *SegmentService) SearchTranscripts(ctx context.Context, filter *model.TranscriptFilter) ([]*model.TranscriptMatch, int64, error) {
+	return s.index.SearchTranscripts(ctx, filter)
+}
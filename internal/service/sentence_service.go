@@ -18,8 +18,8 @@ func NewSentenceService(sentenceRepository *repository.SentenceRepository, trans
 	}
 }
 
-func (s *SentenceService) Create(sentence *model.Sentence) error {
-	meaningVI, err := s.translatorService.Translate(context.Background(), &model.TranslateInput{
+func (s *SentenceService) Create(ctx context.Context, sentence *model.Sentence) error {
+	meaningVI, err := s.translatorService.Translate(ctx, &model.TranslateInput{
 		Text: sentence.MeaningEN,
 	})
 	if err != nil {
@@ -27,7 +27,7 @@ func (s *SentenceService) Create(sentence *model.Sentence) error {
 	}
 	sentence.MeaningVI = meaningVI.Text
 
-	return s.sentenceRepository.Create(sentence)
+	return s.sentenceRepository.Create(ctx, sentence)
 }
 
 func (s *SentenceService) GetByUserIdAndSegmentId(ctx context.Context, userId string, segmentId string) (*model.Sentence, error) {
@@ -1,123 +1,215 @@
 package service
 
 import (
-	"bytes"
 	"context"
 	"encoding/base64"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"net/http"
-	"os"
+	"math"
 	"path/filepath"
 	"shadowify/internal/apperr"
+	"shadowify/internal/audio"
+	"shadowify/internal/config"
 	"shadowify/internal/logger"
 	"shadowify/internal/model"
-
-	"github.com/google/uuid"
+	"shadowify/internal/ratelimit"
+	"shadowify/internal/repository"
+	"shadowify/internal/tracing"
+	"shadowify/internal/workspace"
+	"strings"
 )
 
+const defaultMaxUploadSize = 10 << 20
+
 type STTService struct {
+	cfg               config.STTConfig
+	workspaces        *workspace.Manager
 	whisperService    *WhisperService
+	ffmpegService     *FFmpegService
 	translatorService *TranslatorService
+	cefrService       *CEFRService
+	quota             *ratelimit.Quota
+	practiceRepo      *repository.PracticeRepository
 }
 
-func NewSTTService(whisperService *WhisperService, translatorService *TranslatorService) *STTService {
+func NewSTTService(cfg config.STTConfig, workspaces *workspace.Manager, whisperService *WhisperService, ffmpegService *FFmpegService, translatorService *TranslatorService, cefrService *CEFRService, quota *ratelimit.Quota, practiceRepo *repository.PracticeRepository) *STTService {
+	if cfg.MaxUploadSize <= 0 {
+		cfg.MaxUploadSize = defaultMaxUploadSize
+	}
+	if cfg.Stream.Step <= 0 {
+		cfg.Stream.Step = defaultStreamStep
+	}
+	if cfg.Stream.Window <= 0 {
+		cfg.Stream.Window = defaultStreamWindow
+	}
+	if cfg.Stream.MaxDuration <= 0 {
+		cfg.Stream.MaxDuration = defaultStreamMaxDuration
+	}
 	return &STTService{
+		cfg:               cfg,
+		workspaces:        workspaces,
 		whisperService:    whisperService,
+		ffmpegService:     ffmpegService,
 		translatorService: translatorService,
+		cefrService:       cefrService,
+		quota:             quota,
+		practiceRepo:      practiceRepo,
+	}
+}
+
+// saveAudio validates the uploaded recording and writes it to the workspace.
+func (s *STTService) saveAudio(ws *workspace.Workspace, input *model.AudioInput) (string, error) {
+	if input.ContentType != "" {
+		if _, ok := audio.FormatFromContentType(input.ContentType); !ok {
+			return "", apperr.Validation("stt.audio.unsupported_format", "Unsupported audio format").
+				WithParam("content_type", input.ContentType)
+		}
+	}
+
+	r := input.Audio
+	if r == nil {
+		r = base64.NewDecoder(base64.StdEncoding, strings.NewReader(input.AudioBase64))
+	}
+
+	maxSize := min(s.cfg.MaxUploadSize, ws.Available())
+	filePath, _, err := audio.Save(r, ws.Path("input"), maxSize)
+	if err != nil {
+		switch {
+		case errors.Is(err, audio.ErrEmpty):
+			return "", apperr.Validation("stt.audio.empty", "Audio is required")
+		case errors.Is(err, audio.ErrTooLarge):
+			return "", apperr.Validation("stt.audio.too_large", "Audio is too large").
+				WithParam("max_size", maxSize)
+		case errors.Is(err, audio.ErrUnsupportedFormat):
+			return "", apperr.Validation("stt.audio.unsupported_format", "Unsupported audio format")
+		}
+		var corrupt base64.CorruptInputError
+		if errors.As(err, &corrupt) {
+			return "", apperr.Validation("stt.decode.error", "Failed to decode audio base64").WithCause(err)
+		}
+		return "", apperr.NewAppErr("stt.write.error", "Failed to write audio file").WithCause(err)
+	}
+	return filePath, nil
+}
+
+// transcribe converts a saved recording to wav and runs whisper on it. Its
+// length is counted against the daily transcription quota only when whisper
+// succeeded, so failed transcriptions cost nothing.
+func (s *STTService) transcribe(ctx context.Context, filePath string) (string, error) {
+	// Use a distinct name so that wav uploads are not converted in place.
+	wavPath := strings.TrimSuffix(filePath, filepath.Ext(filePath)) + ".16k.wav"
+	if err := s.ffmpegService.ConvertToWav(ctx, filePath, wavPath); err != nil {
+		return "", err
 	}
+	duration, err := audio.WavDuration(wavPath)
+	if err != nil {
+		return "", apperr.NewAppErr("stt.decode.error", "Failed to read converted audio").WithCause(err)
+	}
+	seconds := int64(math.Ceil(duration.Seconds()))
+	if err := s.quota.Check(ctx, ratelimit.MetricTranscriptionSeconds, seconds); err != nil {
+		return "", err
+	}
+	text, err := s.whisperService.TranscribeWav(ctx, wavPath)
+	if err != nil {
+		return "", err
+	}
+	// A concurrent request may have used up the quota meanwhile. The
+	// transcription is already done, so it is returned anyway.
+	_ = s.quota.Consume(ctx, ratelimit.MetricTranscriptionSeconds, seconds)
+	return text, nil
 }
 
 func (s *STTService) EvaluateAudio(ctx context.Context, input *model.EvaluateInput) (*model.EvaluateOutput, error) {
-	audioData, err := base64.StdEncoding.DecodeString(input.AudioBase64)
+	ctx, span := tracing.Start(ctx, "STTService.EvaluateAudio")
+	defer span.End()
+
+	ws, err := s.workspaces.Acquire("stt")
 	if err != nil {
-		return nil, apperr.NewAppErr("stt.decode.error", "Failed to decode audio base64").WithCause(err)
+		return nil, err
 	}
+	defer ws.Close()
 
-	filePath := filepath.Join("./tmp", fmt.Sprintf("%s.m4a", uuid.NewString()))
-	err = os.WriteFile(filePath, audioData, 0644)
+	filePath, err := s.saveAudio(ws, &input.AudioInput)
 	if err != nil {
-		return nil, apperr.NewAppErr("stt.write.error", "Failed to write audio file").WithCause(err)
+		return nil, err
 	}
-	// defer os.Remove(filePath)
 	// logger.Infof("Evaluating audio file: %s", filePath)
 	// lang, err := s.whisperService.DetectLanguage(ctx, filePath)
 	// if lang != "en" {
 	// 	return nil, apperr.NewAppErr("video.create.error", "Only English videos are supported").WithCause(err)
 	// }
 
-	meaningEN, err := s.whisperService.TranscribeNoTimestamps(ctx, filePath)
+	meaningEN, err := s.transcribe(ctx, filePath)
 	if err != nil {
-		return nil, apperr.NewAppErr("stt.transcribe.error", "Failed to transcribe audio").WithCause(err)
+		return nil, apperr.Wrap(err, "stt.transcribe.error", "Failed to transcribe audio")
 	}
 
-	tranOutput, err := s.translatorService.Translate(ctx, &model.TranslateInput{Text: meaningEN})
+	output, err := s.evaluateText(ctx, meaningEN)
 	if err != nil {
-		return nil, apperr.NewAppErr("translator.translate.error", "Failed to translate meaning").WithCause(err)
-	}
-
-	output := &model.EvaluateOutput{
-		MeaningEN: meaningEN,
-		MeaningVI: tranOutput.Text,
+		return nil, err
 	}
+	s.recordAttempt(ctx, input.SegmentId, output)
+	return output, nil
+}
 
-	var requestBody struct {
-		Sentences []string `json:"sentences"`
+// recordAttempt stores the level of an evaluated recording, from which the
+// level of the user is estimated. The evaluation is returned to the user even
+// when it cannot be stored.
+func (s *STTService) recordAttempt(ctx context.Context, segmentId string, output *model.EvaluateOutput) {
+	user, ok := model.FromContext(ctx)
+	if !ok || user.Id == "" || output.Cefr == "" {
+		return
 	}
-
-	requestBody.Sentences = []string{meaningEN}
-
-	requestBodyByte, err := json.Marshal(requestBody)
-	if err != nil {
-		return nil, apperr.NewAppErr("request.encode.error", "Failed to encode request body").WithCause(err)
+	attempt := &model.PracticeAttempt{UserId: user.Id, Cefr: output.Cefr}
+	if segmentId != "" {
+		attempt.SegmentId = &segmentId
 	}
-	request, err := http.NewRequest("POST", "http://localhost:5050/predict", bytes.NewBuffer(requestBodyByte))
-	if err != nil {
-		return nil, apperr.NewAppErr("request.create.error", "Failed to create request").WithCause(err)
+	if err := s.practiceRepo.Create(ctx, attempt); err != nil {
+		logger.WithContext(ctx).WithFields(logger.Fields{"error": err.Error()}).Warn("Failed to record practice attempt")
 	}
-	request.Header.Set("Content-Type", "application/json")
-	response, err := http.DefaultClient.Do(request)
+}
+
+// evaluateText translates a transcript and predicts its CEFR level.
+func (s *STTService) evaluateText(ctx context.Context, meaningEN string) (*model.EvaluateOutput, error) {
+	tranOutput, err := s.translatorService.Translate(ctx, &model.TranslateInput{Text: meaningEN})
 	if err != nil {
-		return nil, apperr.NewAppErr("request.execute.error", "Failed to execute request").WithCause(err)
+		if apperr.Is(err, apperr.KindTooMany) {
+			return nil, err
+		}
+		return nil, apperr.Unavailable("translator.translate.error", "Failed to translate meaning").WithCause(err)
 	}
-	defer response.Body.Close()
 
-	var responseBody []struct {
-		Cefr     string `json:"cefr"`
-		Sentence string `json:"sentence"`
-	}
-	if response.StatusCode != http.StatusOK {
-		return nil, apperr.NewAppErr("request.execute.error", "Failed to get response from server").WithCause(err)
-	}
-	bodyBytes, err := io.ReadAll(response.Body)
-	if err != nil {
-		return nil, apperr.NewAppErr("response.read.error", "Failed to read response").WithCause(err)
+	output := &model.EvaluateOutput{
+		MeaningEN: meaningEN,
+		MeaningVI: tranOutput.Text,
 	}
-	err = json.Unmarshal(bodyBytes, &responseBody)
+
+	levels, err := s.cefrService.Predict(ctx, []string{meaningEN})
 	if err != nil {
-		return nil, apperr.NewAppErr("response.decode.error", "Failed to decode response").WithCause(err)
+		return nil, err
 	}
-
-	output.Cefr = responseBody[0].Cefr
+	output.Cefr = levels[0]
 	return output, nil
 }
 
 func (s *STTService) Transcribe(ctx context.Context, input *model.TranscribeInput) (*model.TranscribeOutput, error) {
-	audioData, err := base64.StdEncoding.DecodeString(input.AudioBase64)
+	ctx, span := tracing.Start(ctx, "STTService.Transcribe")
+	defer span.End()
+
+	ws, err := s.workspaces.Acquire("stt")
 	if err != nil {
 		return nil, err
 	}
+	defer ws.Close()
 
-	outputPath := filepath.Join("./tmp", fmt.Sprintf("%s.m4a", uuid.NewString()))
-	err = os.WriteFile(outputPath, audioData, 0644)
+	outputPath, err := s.saveAudio(ws, &input.AudioInput)
 	if err != nil {
-		return nil, fmt.Errorf("failed to write audio file: %w", err)
+		return nil, err
 	}
 
-	logger.Infof("Transcribing audio file: %s", outputPath)
+	logger.WithContext(ctx).WithFields(logger.Fields{"path": outputPath}).Info("Transcribing audio file")
 
-	text, err := s.whisperService.TranscribeNoTimestamps(ctx, outputPath)
+	text, err := s.transcribe(ctx, outputPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to transcribe audio: %w", err)
 	}
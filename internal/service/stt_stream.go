@@ -0,0 +1,269 @@
+package service
+
+import (
+	"context"
+	"os"
+	"shadowify/internal/apperr"
+	"shadowify/internal/audio"
+	"shadowify/internal/model"
+	"shadowify/internal/ratelimit"
+	"shadowify/internal/workspace"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	streamSampleRate     = 16000
+	streamBytesPerSecond = streamSampleRate * 2
+
+	defaultStreamStep        = 2 * time.Second
+	defaultStreamWindow      = 10 * time.Second
+	defaultStreamMaxDuration = 5 * time.Minute
+)
+
+// STTStream is an incremental transcription session. Audio is decoded to
+// 16kHz PCM as it arrives; every step of new audio the pending window is
+// re-transcribed as a partial hypothesis, and once the window is full it is
+// transcribed a last time and emitted as final.
+type STTStream struct {
+	s         *STTService
+	ws        *workspace.Workspace
+	segmentId string
+	// decoder converts the stream to 16kHz PCM. It is nil when the client
+	// already sends that.
+	decoder *PCMDecoder
+	cancel  context.CancelFunc
+	// maxReceived bounds the bytes a client may send, see streamLimit.
+	maxReceived int64
+
+	received  int64
+	raw       []byte
+	pcm       []byte
+	committed int
+	decoded   int
+	charged   int
+	finals    []string
+}
+
+// NewStream starts a streaming session for the encoding announced by the
+// client. The session ends with ctx.
+func (s *STTService) NewStream(ctx context.Context, msg *model.STTStreamMessage) (*STTStream, error) {
+	switch msg.Encoding {
+	case model.STTStreamPCM:
+		if msg.SampleRate <= 0 {
+			return nil, apperr.Validation("stt.stream.invalid_sample_rate", "Sample rate is required for PCM").WithField("sample_rate")
+		}
+	case model.STTStreamOpus:
+	default:
+		return nil, apperr.Validation("stt.stream.unsupported_encoding", "Unsupported stream encoding").
+			WithField("encoding").
+			WithParam("encoding", msg.Encoding)
+	}
+	if msg.SegmentId != "" && uuid.Validate(msg.SegmentId) != nil {
+		return nil, apperr.Validation("validation.uuid", "segment_id must be a valid id").WithField("segment_id")
+	}
+
+	ws, err := s.workspaces.Acquire("stt-stream")
+	if err != nil {
+		return nil, err
+	}
+
+	st := &STTStream{
+		s:           s,
+		ws:          ws,
+		segmentId:   msg.SegmentId,
+		maxReceived: s.streamLimit(msg),
+	}
+	if msg.Encoding != model.STTStreamPCM || msg.SampleRate != streamSampleRate {
+		sampleRate := 0
+		if msg.Encoding == model.STTStreamPCM {
+			sampleRate = msg.SampleRate
+		}
+		ctx, st.cancel = context.WithCancel(ctx)
+		// Live audio arrives in about real time, leave room for pauses
+		st.decoder = s.ffmpegService.NewPCMDecoder(ctx, sampleRate, 2*s.cfg.Stream.MaxDuration)
+	}
+	return st, nil
+}
+
+// streamLimit returns how many bytes a stream may send: MaxUploadSize, and
+// for raw PCM no more than MaxDuration of audio at its sample rate. Compressed
+// streams are bounded by their decoded duration too, which is checked as
+// they are decoded.
+func (s *STTService) streamLimit(msg *model.STTStreamMessage) int64 {
+	limit := s.cfg.MaxUploadSize
+	if msg.Encoding == model.STTStreamPCM {
+		limit = min(limit, int64(s.cfg.Stream.MaxDuration.Seconds()*float64(msg.SampleRate*2)))
+	}
+	return limit
+}
+
+// Write appends an audio chunk and returns the hypotheses it produced.
+func (st *STTStream) Write(ctx context.Context, chunk []byte) ([]*model.STTStreamEvent, error) {
+	st.received += int64(len(chunk))
+	if st.received > st.maxReceived {
+		return nil, apperr.Validation("stt.audio.too_large", "Audio is too large").
+			WithParam("max_size", st.maxReceived)
+	}
+	if err := st.decode(chunk); err != nil {
+		return nil, err
+	}
+	if err := st.checkDuration(); err != nil {
+		return nil, err
+	}
+	if err := st.chargeQuota(ctx, false); err != nil {
+		return nil, err
+	}
+
+	window := durationToBytes(st.s.cfg.Stream.Window)
+	var events []*model.STTStreamEvent
+	for len(st.pcm)-st.committed >= window {
+		text, err := st.transcribe(ctx, st.pcm[st.committed:st.committed+window])
+		if err != nil {
+			return nil, err
+		}
+		st.committed += window
+		st.decoded = st.committed
+		if text != "" {
+			st.finals = append(st.finals, text)
+			events = append(events, &model.STTStreamEvent{Type: model.STTStreamFinal, Text: text})
+		}
+	}
+
+	if len(st.pcm)-st.decoded >= durationToBytes(st.s.cfg.Stream.Step) {
+		text, err := st.transcribe(ctx, st.pcm[st.committed:])
+		if err != nil {
+			return nil, err
+		}
+		st.decoded = len(st.pcm)
+		events = append(events, &model.STTStreamEvent{Type: model.STTStreamPartial, Text: text})
+	}
+
+	return events, nil
+}
+
+// Finish finalizes the remaining audio and evaluates the whole transcript the
+// same way EvaluateAudio does.
+func (st *STTStream) Finish(ctx context.Context) ([]*model.STTStreamEvent, error) {
+	if st.decoder != nil {
+		pcm, err := st.decoder.Close()
+		if err != nil {
+			return nil, decodeError(err)
+		}
+		st.pcm = pcm
+		if err := st.checkDuration(); err != nil {
+			return nil, err
+		}
+	}
+	if err := st.chargeQuota(ctx, true); err != nil {
+		return nil, err
+	}
+
+	var events []*model.STTStreamEvent
+	if len(st.pcm) > st.committed {
+		text, err := st.transcribe(ctx, st.pcm[st.committed:])
+		if err != nil {
+			return nil, err
+		}
+		st.committed = len(st.pcm)
+		if text != "" {
+			st.finals = append(st.finals, text)
+			events = append(events, &model.STTStreamEvent{Type: model.STTStreamFinal, Text: text})
+		}
+	}
+
+	transcript := strings.Join(st.finals, " ")
+	if transcript == "" {
+		return nil, apperr.Validation("stt.stream.empty", "No speech was recognized")
+	}
+
+	output, err := st.s.evaluateText(ctx, transcript)
+	if err != nil {
+		return nil, err
+	}
+	st.s.recordAttempt(ctx, st.segmentId, output)
+	return append(events, &model.STTStreamEvent{Type: model.STTStreamResult, Result: output}), nil
+}
+
+// Close stops the decoder and releases the session workspace.
+func (st *STTStream) Close() error {
+	if st.decoder != nil {
+		st.cancel()
+		st.decoder.Close()
+	}
+	return st.ws.Close()
+}
+
+// decode adds a chunk to the PCM buffer. Only the new chunk is handed to the
+// decoder, which keeps the state of the stream; its output may lag behind
+// until Finish flushes it.
+func (st *STTStream) decode(chunk []byte) error {
+	if st.decoder == nil {
+		st.raw = append(st.raw, chunk...)
+		st.pcm = st.raw[:len(st.raw)&^1]
+		return nil
+	}
+
+	if err := st.decoder.Write(chunk); err != nil {
+		return decodeError(err)
+	}
+	pcm, err := st.decoder.PCM()
+	if err != nil {
+		return decodeError(err)
+	}
+	st.pcm = pcm
+	return nil
+}
+
+func decodeError(err error) error {
+	return apperr.Validation("stt.stream.decode.error", "Failed to decode audio stream").WithCause(err)
+}
+
+// checkDuration fails once the decoded audio exceeds the maximum duration.
+func (st *STTStream) checkDuration() error {
+	maxDuration := st.s.cfg.Stream.MaxDuration
+	if len(st.pcm) > durationToBytes(maxDuration) {
+		return apperr.Validation("stt.stream.too_long", "Stream exceeds the maximum duration").
+			WithParam("max_duration", maxDuration.String())
+	}
+	return nil
+}
+
+func (st *STTStream) transcribe(ctx context.Context, pcm []byte) (string, error) {
+	wavPath := st.ws.Path(uuid.NewString() + ".wav")
+	if err := audio.WriteWav(wavPath, pcm, streamSampleRate); err != nil {
+		return "", apperr.NewAppErr("stt.write.error", "Failed to write audio file").WithCause(err)
+	}
+	defer os.Remove(wavPath)
+
+	text, err := st.s.whisperService.TranscribeWav(ctx, wavPath)
+	if err != nil {
+		return "", apperr.Wrap(err, "stt.transcribe.error", "Failed to transcribe audio")
+	}
+	return text, nil
+}
+
+// chargeQuota counts the decoded audio against the daily transcription quota
+// in whole seconds. The remainder is charged, rounded up, when the stream is
+// finished.
+func (st *STTStream) chargeQuota(ctx context.Context, finish bool) error {
+	pending := len(st.pcm) - st.charged
+	seconds := pending / streamBytesPerSecond
+	if finish && pending%streamBytesPerSecond != 0 {
+		seconds++
+	}
+	if seconds <= 0 {
+		return nil
+	}
+	if err := st.s.quota.Consume(ctx, ratelimit.MetricTranscriptionSeconds, int64(seconds)); err != nil {
+		return err
+	}
+	st.charged = min(len(st.pcm), st.charged+seconds*streamBytesPerSecond)
+	return nil
+}
+
+func durationToBytes(d time.Duration) int {
+	return int(d.Seconds()*streamBytesPerSecond) &^ 1
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"shadowify/internal/apperr"
+	"shadowify/internal/config"
+	"shadowify/internal/model"
+	"shadowify/internal/procpool"
+	"shadowify/internal/ratelimit"
+	"shadowify/internal/runner"
+	"shadowify/internal/workspace"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newStreamService(t *testing.T, r runner.Runner, cfg config.STTConfig) *STTService {
+	workspaces, err := workspace.NewManager(config.WorkspaceConfig{Root: t.TempDir()})
+	require.NoError(t, err)
+	ffmpeg := NewFFmpegService(r)
+	whisper := NewWhisperService(r, procpool.New(config.ProcPoolConfig{}), ffmpeg)
+	quota := ratelimit.NewQuota(config.RateLimitConfig{}, ratelimit.NewMemoryStore())
+	return NewSTTService(cfg, workspaces, whisper, ffmpeg, nil, nil, quota, nil)
+}
+
+func TestSTTStream_DecodesOnlyNewData(t *testing.T) {
+	var decoded bytes.Buffer
+	fake := &runner.Fake{
+		// Copies the stream, as ffmpeg converts it chunk by chunk
+		Handler: func(ctx context.Context, cmd runner.Command) (*runner.Result, error) {
+			_, err := io.Copy(io.MultiWriter(cmd.Stdout, &decoded), cmd.Stdin)
+			return nil, err
+		},
+	}
+	s := newStreamService(t, fake, config.STTConfig{})
+	stream, err := s.NewStream(context.Background(), &model.STTStreamMessage{Type: model.STTStreamStart, Encoding: model.STTStreamOpus})
+	require.NoError(t, err)
+	defer stream.Close()
+
+	for range 3 {
+		_, err := stream.Write(context.Background(), []byte("chunk"))
+		require.NoError(t, err)
+	}
+	pcm, err := stream.decoder.Close()
+	require.NoError(t, err)
+
+	assert.Len(t, fake.Calls(), 1, "one ffmpeg process for the whole stream")
+	assert.Equal(t, "chunkchunkchunk", decoded.String(), "each chunk is decoded once")
+	assert.Len(t, pcm, 14)
+}
+
+func TestSTTStream_DecodeFailure(t *testing.T) {
+	fake := &runner.Fake{
+		Handler: func(ctx context.Context, cmd runner.Command) (*runner.Result, error) {
+			return nil, &runner.Error{Tool: cmd.Tool(), ExitCode: 1, Stderr: "Invalid data found when processing input"}
+		},
+	}
+	s := newStreamService(t, fake, config.STTConfig{})
+	stream, err := s.NewStream(context.Background(), &model.STTStreamMessage{Type: model.STTStreamStart, Encoding: model.STTStreamOpus})
+	require.NoError(t, err)
+	defer stream.Close()
+
+	_, err = stream.Write(context.Background(), []byte("not opus"))
+	assert.Equal(t, "stt.stream.decode.error", apperr.From(err).Code)
+}
+
+func TestSTTStream_TooLarge(t *testing.T) {
+	fake := &runner.Fake{
+		// Never outputs audio, so the duration limit cannot stop the stream
+		Handler: func(ctx context.Context, cmd runner.Command) (*runner.Result, error) {
+			_, err := io.Copy(io.Discard, cmd.Stdin)
+			return nil, err
+		},
+	}
+	s := newStreamService(t, fake, config.STTConfig{MaxUploadSize: 10})
+	stream, err := s.NewStream(context.Background(), &model.STTStreamMessage{Type: model.STTStreamStart, Encoding: model.STTStreamOpus})
+	require.NoError(t, err)
+	defer stream.Close()
+
+	_, err = stream.Write(context.Background(), []byte("0123456789"))
+	require.NoError(t, err)
+	_, err = stream.Write(context.Background(), []byte("a"))
+	assert.Equal(t, "stt.audio.too_large", apperr.From(err).Code)
+}
+
+func TestSTTStream_PCMLimit(t *testing.T) {
+	s := newStreamService(t, &runner.Fake{}, config.STTConfig{Stream: config.STTStreamConfig{MaxDuration: time.Second}})
+	stream, err := s.NewStream(context.Background(), &model.STTStreamMessage{Type: model.STTStreamStart, Encoding: model.STTStreamPCM, SampleRate: 8000})
+	require.NoError(t, err)
+	defer stream.Close()
+
+	assert.Equal(t, int64(16000), stream.maxReceived, "one second of 16-bit audio at 8kHz")
+}
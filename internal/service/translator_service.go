@@ -4,31 +4,46 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"log"
+	"fmt"
 	"net/http"
 	"net/url"
 	"shadowify/internal/apperr"
 	"shadowify/internal/config"
+	"shadowify/internal/metrics"
 	"shadowify/internal/model"
+	"shadowify/internal/ratelimit"
+	"unicode/utf8"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
 type TranslatorService struct {
 	cfg    config.AzureTranslatorConfig
 	client *http.Client
+	quota  *ratelimit.Quota
 }
 
-func NewTranslatorService(cfg config.AzureTranslatorConfig) *TranslatorService {
+func NewTranslatorService(cfg config.AzureTranslatorConfig, quota *ratelimit.Quota) *TranslatorService {
 	return &TranslatorService{
 		cfg:    cfg,
-		client: http.DefaultClient,
+		quota:  quota,
+		client: &http.Client{Transport: otelhttp.NewTransport(metrics.NewTransport("translator", http.DefaultTransport))},
 	}
 }
 
+// Translate translates English text to Vietnamese. The characters are
+// counted against the daily translation quota of the caller once the
+// translation succeeded, so failed calls cost nothing.
 func (s *TranslatorService) Translate(ctx context.Context, input *model.TranslateInput) (*model.TranslateOutput, error) {
+	chars := int64(utf8.RuneCountInString(input.Text))
+	if err := s.quota.Check(ctx, ratelimit.MetricTranslationChars, chars); err != nil {
+		return nil, err
+	}
+
 	u, _ := url.Parse(s.cfg.URI)
 	q := u.Query()
 	q.Add("from", "en")
-	q.Add("to", "vi")
+	q.Add("to", model.TranslationLanguage)
 	u.RawQuery = q.Encode()
 
 	body := []struct {
@@ -38,7 +53,7 @@ func (s *TranslatorService) Translate(ctx context.Context, input *model.Translat
 	}
 	b, _ := json.Marshal(body)
 
-	req, err := http.NewRequest("POST", u.String(), bytes.NewBuffer(b))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), bytes.NewBuffer(b))
 	if err != nil {
 		return nil, apperr.NewAppErr("translator.request.error", "Failed to create request").WithCause(err)
 	}
@@ -46,9 +61,14 @@ func (s *TranslatorService) Translate(ctx context.Context, input *model.Translat
 	req.Header.Add("Ocp-Apim-Subscription-Key", s.cfg.APIKey)
 	req.Header.Add("Ocp-Apim-Subscription-Region", s.cfg.Region)
 	req.Header.Add("Content-Type", "application/json")
-	res, err := http.DefaultClient.Do(req)
+	res, err := s.client.Do(req)
 	if err != nil {
-		return nil, apperr.NewAppErr("translator.request.error", "Failed to send request").WithCause(err)
+		return nil, apperr.Unavailable("translator.request.error", "Failed to send request").WithCause(err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, apperr.Unavailable("translator.request.error", "Failed to send request").
+			WithCause(fmt.Errorf("translator returned status %d", res.StatusCode))
 	}
 
 	type TranslationResponse []struct {
@@ -60,8 +80,11 @@ func (s *TranslatorService) Translate(ctx context.Context, input *model.Translat
 
 	var result TranslationResponse
 	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
-		log.Fatal(err)
+		return nil, apperr.Unavailable("translator.request.error", "Failed to send request").WithCause(err)
 	}
+	// A concurrent request may have used up the quota meanwhile. The
+	// translation is already paid for, so it is returned anyway.
+	_ = s.quota.Consume(ctx, ratelimit.MetricTranslationChars, chars)
 
 	translatedText := ""
 	if len(result) > 0 && len(result[0].Translations) > 0 {
@@ -72,3 +95,8 @@ func (s *TranslatorService) Translate(ctx context.Context, input *model.Translat
 		Text: translatedText,
 	}, nil // Placeholder for actual translation logic
 }
+
+// Ping checks that the translator endpoint is reachable.
+func (s *TranslatorService) Ping(ctx context.Context) error {
+	return pingHTTP(ctx, s.client, s.cfg.URI)
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"shadowify/internal/apperr"
+	"shadowify/internal/config"
+	"shadowify/internal/model"
+	"shadowify/internal/ratelimit"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranslatorService_QuotaChargedOnSuccess(t *testing.T) {
+	fail := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(`[{"translations":[{"text":"xin chào","to":"vi"}]}]`))
+	}))
+	defer server.Close()
+
+	quota := ratelimit.NewQuota(config.RateLimitConfig{
+		Enabled: true,
+		Quotas:  config.QuotaConfig{TranslationChars: 5},
+	}, ratelimit.NewMemoryStore())
+	s := NewTranslatorService(config.AzureTranslatorConfig{URI: server.URL}, quota)
+	ctx := ratelimit.NewContext(context.Background(), "ip:10.0.0.1")
+	input := &model.TranslateInput{Text: "hello"}
+
+	_, err := s.Translate(ctx, input)
+	assert.Equal(t, "translator.request.error", apperr.From(err).Code)
+
+	fail = false
+	output, err := s.Translate(ctx, input)
+	require.NoError(t, err, "failed calls are not charged")
+	assert.Equal(t, "xin chào", output.Text)
+
+	_, err = s.Translate(ctx, input)
+	assert.Equal(t, "quota.translation_chars.exceeded", apperr.From(err).Code)
+}
@@ -1,65 +1,61 @@
 package service
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
-	"io"
-	"net/http"
-	"net/url"
-	"os"
 	"shadowify/internal/apperr"
 	"shadowify/internal/database"
 	"shadowify/internal/dto"
 	"shadowify/internal/logger"
+	"shadowify/internal/metrics"
 	"shadowify/internal/model"
 	"shadowify/internal/repository"
-	"strings"
+	"shadowify/internal/search"
+	"shadowify/internal/tracing"
+	"shadowify/internal/validation"
+	"shadowify/internal/views"
+	"shadowify/internal/workspace"
 )
 
 type VideoService struct {
 	repo           *repository.VideoRepository
 	segmentRepo    *repository.SegmentRepository
+	index          search.Index
+	workspaces     *workspace.Manager
 	whisperService *WhisperService
 	ytDLPService   *YTDLPService
+	cefrService    *CEFRService
+	views          *views.Counter
+	vocabulary     *VocabularyService
 }
 
-func NewVideoService(repo *repository.VideoRepository, segmentRepo *repository.SegmentRepository, whisperService *WhisperService, ytDLPService *YTDLPService) *VideoService {
+func NewVideoService(repo *repository.VideoRepository, segmentRepo *repository.SegmentRepository, index search.Index, workspaces *workspace.Manager, whisperService *WhisperService, ytDLPService *YTDLPService, cefrService *CEFRService, views *views.Counter, vocabulary *VocabularyService) *VideoService {
 	return &VideoService{
 		repo:           repo,
 		segmentRepo:    segmentRepo,
+		index:          index,
+		workspaces:     workspaces,
 		whisperService: whisperService,
 		ytDLPService:   ytDLPService,
+		cefrService:    cefrService,
+		views:          views,
+		vocabulary:     vocabulary,
 	}
 }
 
 func (s *VideoService) getYoutubeIdFromRawInput(rawInput string) (string, error) {
-	if len(rawInput) == 0 {
-		return "", apperr.NewAppErr("bad_request", "youtube id is required")
+	youtubeId, ok := validation.ParseYoutubeId(rawInput)
+	if !ok {
+		return "", apperr.Validation("validation.youtube_id", "invalid youtube id or url").WithField("youtubeRawInput")
 	}
-
-	if strings.Contains(rawInput, "youtube.com/watch?v=") {
-		url, err := url.Parse(rawInput)
-		if err != nil {
-			return "", apperr.NewAppErr("bad_request", "invalid youtube url").WithCause(err)
-		}
-		queryParams := url.Query()
-		return queryParams.Get("v"), nil
-	}
-
-	if strings.HasPrefix(rawInput, "https://youtu.be/") {
-		url, err := url.Parse(rawInput)
-		if err != nil {
-			return "", apperr.NewAppErr("bad_request", "invalid youtube url").WithCause(err)
-		}
-		return strings.TrimPrefix(url.Path, "/"), nil
-	}
-
-	return strings.TrimSpace(rawInput), nil
+	return youtubeId, nil
 }
 
 func (s *VideoService) Create(ctx context.Context, req *dto.CreateVideoRequest) (*model.Video, error) {
-	logger.Infof("Starting video creation with raw input: %s", req.YoutubeRawInput)
+	ctx, span := tracing.Start(ctx, "VideoService.Create")
+	defer span.End()
+
+	log := logger.WithContext(ctx)
+	log.WithFields(logger.Fields{"raw_input": req.YoutubeRawInput}).Info("Starting video creation")
 	youtubeId, err := s.getYoutubeIdFromRawInput(req.YoutubeRawInput)
 	if err != nil {
 		return nil, err
@@ -67,115 +63,146 @@ func (s *VideoService) Create(ctx context.Context, req *dto.CreateVideoRequest)
 
 	yt, err := s.repo.GetByYoutubeId(ctx, youtubeId)
 	if err != nil {
-		return nil, apperr.NewAppErr("video.create.error", "Failed to check existing video").WithCause(err)
+		return nil, err
 	}
 	if yt != nil {
-		return nil, apperr.NewAppErr("video.create.error", "Video already exists").WithCause(err)
+		return nil, apperr.Conflict("video.already_exists", "Video already exists").WithParam("id", yt.Id)
 	}
 
-	logger.Infof("Starting download and extraction for YouTube ID: %s", youtubeId)
-	metadata, filePath, err := s.ytDLPService.DownloadAndExtract(ctx, youtubeId)
-	defer func() {
-		if filePath == "" {
-			return
-		}
-		err = os.Remove(filePath)
-		logger.Errorf("Failed to remove file %s: %v", filePath, err)
-	}()
+	ws, err := s.workspaces.Acquire("video")
 	if err != nil {
-		return nil, apperr.NewAppErr("video.create.error", "Failed to download and extract video").WithCause(err)
+		return nil, err
+	}
+	defer ws.Close()
+
+	log = log.WithFields(logger.Fields{"youtube_id": youtubeId})
+	log.Info("Starting download and extraction")
+	track := metrics.TrackStage("download")
+	metadata, filePath, err := s.ytDLPService.DownloadAndExtract(ctx, youtubeId, ws.Dir())
+	track(err)
+	if err != nil {
+		return nil, apperr.Unavailable("video.download.error", "Failed to download and extract video").WithCause(err)
 	}
 
+	track = metrics.TrackStage("detect_language")
 	lang, err := s.whisperService.DetectLanguage(ctx, filePath)
+	track(err)
+	if err != nil {
+		return nil, apperr.Wrap(err, "video.detect_language.error", "Failed to detect the video language")
+	}
 	if lang != "en" {
-		return nil, apperr.NewAppErr("video.create.error", "Only English videos are supported").WithCause(err)
+		return nil, apperr.Validation("video.unsupported_language", "Only English videos are supported").WithParam("language", lang)
 	}
 
 	video := &model.Video{
-		Title:          metadata.Title,
-		FullTitle:      metadata.FullTitle,
-		Description:    metadata.Description,
-		YoutubeId:      metadata.Id,
-		Duration:       metadata.Duration,
-		DurationString: metadata.DurationString,
-		Thumbnail:      metadata.Thumbnail,
-		Tags:           database.JSONType[[]string]{Data: metadata.Tags},
-		Categories:     database.JSONType[[]string]{Data: metadata.Categories},
-	}
-
-	logger.Infof("Starting transcription for video: %s", video.Title)
+		Title:             metadata.Title,
+		FullTitle:         metadata.FullTitle,
+		Description:       metadata.Description,
+		YoutubeId:         metadata.Id,
+		Duration:          metadata.Duration,
+		DurationString:    metadata.DurationString,
+		Thumbnail:         metadata.Thumbnail,
+		Tags:              database.JSONType[[]string]{Data: metadata.Tags},
+		Categories:        database.JSONType[[]string]{Data: metadata.Categories},
+		Channel:           metadata.Channel,
+		ChannelId:         metadata.ChannelId,
+		SubtitleLanguages: database.JSONType[[]string]{Data: metadata.SubtitleLanguages()},
+	}
+
+	log.WithFields(logger.Fields{"title": video.Title}).Info("Starting transcription")
+	track = metrics.TrackStage("transcribe")
 	segments, err := s.whisperService.Transcribe(ctx, filePath)
+	track(err)
 	if err != nil {
 		return nil, err
 	}
 
-	logger.Infof("Starting CEFR prediction for %d segments", len(segments))
+	log.WithFields(logger.Fields{"segments": len(segments)}).Info("Starting CEFR prediction")
 
-	var requestBody struct {
-		Sentences []string `json:"sentences"`
+	sentences := make([]string, len(segments))
+	for i, segment := range segments {
+		sentences[i] = segment.Content
 	}
-	for _, segment := range segments {
-		requestBody.Sentences = append(requestBody.Sentences, segment.Content)
-	}
-	requestBodyByte, err := json.Marshal(requestBody)
-	if err != nil {
-		return nil, apperr.NewAppErr("request.encode.error", "Failed to encode request body").WithCause(err)
-	}
-	request, err := http.NewRequest("POST", "http://localhost:5050/predict", bytes.NewBuffer(requestBodyByte))
+	track = metrics.TrackStage("cefr")
+	levels, err := s.cefrService.Predict(ctx, sentences)
+	track(err)
 	if err != nil {
-		return nil, apperr.NewAppErr("request.create.error", "Failed to create request").WithCause(err)
+		return nil, err
 	}
-	request.Header.Set("Content-Type", "application/json")
-	response, err := http.DefaultClient.Do(request)
-	if err != nil {
-		return nil, apperr.NewAppErr("request.execute.error", "Failed to execute request").WithCause(err)
+	for i := range segments {
+		segments[i].Cefr = levels[i]
 	}
-	defer response.Body.Close()
+	video.Cefr = model.SegmentsLevel(segments)
+	video.CefrShares = database.JSONType[[]float64]{Data: model.SegmentsCefrShares(segments)}
 
-	var responseBody []struct {
-		Cefr     string `json:"cefr"`
-		Sentence string `json:"sentence"`
-	}
-	if response.StatusCode != http.StatusOK {
-		return nil, apperr.NewAppErr("request.execute.error", "Failed to get response from server").WithCause(err)
-	}
-	bodyBytes, err := io.ReadAll(response.Body)
-	if err != nil {
-		return nil, apperr.NewAppErr("response.read.error", "Failed to read response").WithCause(err)
-	}
-	err = json.Unmarshal(bodyBytes, &responseBody)
+	log.WithFields(logger.Fields{"segments": len(segments), "cefr": video.Cefr}).Info("CEFR prediction completed")
+	track = metrics.TrackStage("store")
+	err = s.repo.Create(ctx, video, segments)
+	track(err)
 	if err != nil {
-		return nil, apperr.NewAppErr("response.decode.error", "Failed to decode response").WithCause(err)
+		return nil, err
 	}
 
-	for i := range segments {
-		segments[i].Cefr = responseBody[i].Cefr
+	// The database is the source of truth, a video missing from the index is
+	// added back by cmd/reindex.
+	track = metrics.TrackStage("index")
+	err = s.index.IndexVideo(ctx, video)
+	if err == nil {
+		err = s.index.IndexSegments(ctx, video, segments)
 	}
-
-	logger.Infof("CEFR prediction completed for %d segments", len(segments))
-	err = s.repo.Create(ctx, video, segments)
+	track(err)
 	if err != nil {
-		return nil, err
+		log.WithFields(logger.Fields{"video_id": video.Id, "error": err.Error()}).Error("Failed to index video")
+	}
+	// Lemmas missing here are stored by cmd/lemmatize
+	if err := s.vocabulary.IndexVideo(ctx, video.Id, segments); err != nil {
+		log.WithFields(logger.Fields{"video_id": video.Id, "error": err.Error()}).Warn("Failed to store video lemmas")
 	}
 
 	return video, nil
 }
 
 func (s *VideoService) GetById(ctx context.Context, id, userId string) (*model.VideoDetail, error) {
+	ctx, span := tracing.Start(ctx, "VideoService.GetById")
+	defer span.End()
+
 	video, err := s.repo.GetById(ctx, id, userId)
 	if err != nil {
 		return nil, err
 	}
-	go func() {
-		if err := s.repo.IncrementViewCount(context.TODO(), id); err != nil {
-			logger.Warnf("Failed to increment view count for video %s: %v", id, err)
+	if userId != "" {
+		video.Comprehension, err = s.vocabulary.Comprehension(ctx, userId, id)
+		if err != nil {
+			logger.WithContext(ctx).WithFields(logger.Fields{"video_id": id, "error": err.Error()}).Warn("Failed to compute comprehension")
 		}
-	}()
+	}
 	return video, nil
 }
 
+// RecordView counts a view of a video from a device, or from an address when
+// no device id is sent.
+func (s *VideoService) RecordView(videoId, deviceId, ip string) {
+	s.views.Record(videoId, deviceId, ip)
+}
+
 func (s *VideoService) List(ctx context.Context, filter *model.VideoFilter) ([]*model.Video, int64, error) {
-	return s.repo.List(ctx, filter)
+	ctx, span := tracing.Start(ctx, "VideoService.List")
+	defer span.End()
+
+	// Watch history and views are only kept current in the database, the
+	// index holds the view counts of the last reindex.
+	switch filter.Type {
+	case model.VideoRecent, model.VideoTrending, model.VideoPopular:
+		return s.repo.List(ctx, filter)
+	}
+	return s.index.SearchVideos(ctx, filter)
+}
+
+func (s *VideoService) Facets(ctx context.Context, filter *model.VideoFilter) (*model.VideoFacets, error) {
+	ctx, span := tracing.Start(ctx, "VideoService.Facets")
+	defer span.End()
+
+	return s.index.Facets(ctx, filter)
 }
 
 func (s *VideoService) GetFavoriteVideos(ctx context.Context, userId string, filter *model.FavoriteVideoFilter) ([]*model.Video, int64, error) {
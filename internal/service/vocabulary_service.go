@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"shadowify/internal/apperr"
+	"shadowify/internal/model"
+	"shadowify/internal/nlp"
+	"shadowify/internal/repository"
+	"shadowify/internal/tracing"
+)
+
+// quizWordsPerBand is the number of words the placement quiz asks about in
+// each frequency band.
+const quizWordsPerBand = 5
+
+type VocabularyService struct {
+	repo *repository.VocabularyRepository
+}
+
+func NewVocabularyService(repo *repository.VocabularyRepository) *VocabularyService {
+	return &VocabularyService{
+		repo: repo,
+	}
+}
+
+// lemmaCounts counts the lemmas of the words of texts, leaving out
+// stopwords, which carry no vocabulary.
+func lemmaCounts(texts ...string) map[string]int {
+	counts := make(map[string]int)
+	for _, text := range texts {
+		for _, lemma := range nlp.Lemmas(text) {
+			if !nlp.IsStopword(lemma) {
+				counts[lemma]++
+			}
+		}
+	}
+	return counts
+}
+
+// lemmaOf returns the lemma of a single word, or false for phrases.
+func lemmaOf(word string) (string, bool) {
+	lemmas := nlp.Lemmas(word)
+	if len(lemmas) != 1 {
+		return "", false
+	}
+	return lemmas[0], true
+}
+
+// IndexVideo stores the lemmas spoken in a video.
+func (s *VocabularyService) IndexVideo(ctx context.Context, videoId string, segments []*model.Segment) error {
+	texts := make([]string, len(segments))
+	for i, segment := range segments {
+		texts[i] = segment.Content
+	}
+	counts := lemmaCounts(texts...)
+	lemmas := make([]*model.VideoLemma, 0, len(counts))
+	for lemma, occurrences := range counts {
+		lemmas = append(lemmas, &model.VideoLemma{VideoId: videoId, Lemma: lemma, Occurrences: occurrences})
+	}
+	return s.repo.SetVideoLemmas(ctx, videoId, lemmas)
+}
+
+// Comprehension returns the share of the lemmas of a video the user knows, or
+// nil when the lemmas of the video are not stored. Those of videos added
+// before vocabulary tracking are stored by cmd/lemmatize.
+func (s *VocabularyService) Comprehension(ctx context.Context, userId, videoId string) (*model.Comprehension, error) {
+	ctx, span := tracing.Start(ctx, "VocabularyService.Comprehension")
+	defer span.End()
+
+	comprehension, err := s.repo.Comprehension(ctx, userId, videoId)
+	if err != nil || comprehension.Total == 0 {
+		return nil, err
+	}
+	return comprehension, nil
+}
+
+func (s *VocabularyService) List(ctx context.Context, filter *model.VocabularyFilter) ([]*model.VocabularyWord, int64, error) {
+	if filter.UserId == "" {
+		return nil, 0, apperr.Unauthorized("unauthorized", "User not authenticated")
+	}
+	return s.repo.List(ctx, filter)
+}
+
+// SetStatus marks a word as known, learning or unknown.
+func (s *VocabularyService) SetStatus(ctx context.Context, userId, word string, status model.WordStatus) (*model.VocabularyWord, error) {
+	if userId == "" {
+		return nil, apperr.Unauthorized("unauthorized", "User not authenticated")
+	}
+	lemma, ok := lemmaOf(word)
+	if !ok {
+		return nil, apperr.Validation("vocabulary.invalid_word", "A single word is required").WithField("word")
+	}
+
+	entry := &model.VocabularyWord{UserId: userId, Lemma: lemma, Status: status, Source: model.SourceManual}
+	if err := s.repo.Save(ctx, entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// MarkSaved adds a word the user saved as one they are learning, unless it
+// already has a status. Phrases are left out.
+func (s *VocabularyService) MarkSaved(ctx context.Context, userId, word string) error {
+	lemma, ok := lemmaOf(word)
+	if !ok {
+		return nil
+	}
+	entry := &model.VocabularyWord{UserId: userId, Lemma: lemma, Status: model.WordLearning, Source: model.SourceSaved}
+	return s.repo.SaveAll(ctx, []*model.VocabularyWord{entry}, false)
+}
+
+// Seed marks the most frequent lemmas as known for a learner of a level or
+// with a vocabulary size.
+func (s *VocabularyService) Seed(ctx context.Context, userId string, req *model.VocabularySeedRequest) (*model.VocabularySeedResult, error) {
+	if userId == "" {
+		return nil, apperr.Unauthorized("unauthorized", "User not authenticated")
+	}
+	size := req.Size
+	if size == 0 {
+		size = model.VocabularySize(req.Level)
+	}
+	if size == 0 {
+		return nil, apperr.Validation("validation.required", "level is required").WithField("level")
+	}
+	return s.seed(ctx, userId, size, model.SourceFrequency)
+}
+
+func (s *VocabularyService) seed(ctx context.Context, userId string, size int, source model.VocabularySource) (*model.VocabularySeedResult, error) {
+	seeded, err := s.repo.SeedFrequent(ctx, userId, size, source)
+	if err != nil {
+		return nil, err
+	}
+	return &model.VocabularySeedResult{Size: size, Level: model.VocabularyLevel(size), Seeded: seeded}, nil
+}
+
+// Quiz picks words from every frequency band for the placement quiz, most
+// frequent first.
+func (s *VocabularyService) Quiz(ctx context.Context) ([]*model.QuizWord, error) {
+	words := []*model.QuizWord{}
+	from := 0
+	for _, to := range model.FrequencyBands {
+		lemmas, err := s.repo.RandomLemmas(ctx, from, to, quizWordsPerBand)
+		if err != nil {
+			return nil, err
+		}
+		for _, lemma := range lemmas {
+			words = append(words, &model.QuizWord{Lemma: lemma})
+		}
+		from = to
+	}
+	return words, nil
+}
+
+// SubmitQuiz estimates the vocabulary size of a user from their quiz answers,
+// marks that many of the most frequent lemmas as known and records the
+// answers themselves.
+func (s *VocabularyService) SubmitQuiz(ctx context.Context, userId string, req *model.VocabularyQuizRequest) (*model.VocabularySeedResult, error) {
+	ctx, span := tracing.Start(ctx, "VocabularyService.SubmitQuiz")
+	defer span.End()
+
+	if userId == "" {
+		return nil, apperr.Unauthorized("unauthorized", "User not authenticated")
+	}
+
+	answers := make(map[string]bool, len(req.Answers))
+	lemmas := make([]string, 0, len(req.Answers))
+	for _, answer := range req.Answers {
+		lemma, ok := lemmaOf(answer.Lemma)
+		if !ok {
+			continue
+		}
+		if _, seen := answers[lemma]; !seen {
+			lemmas = append(lemmas, lemma)
+		}
+		answers[lemma] = answer.Known
+	}
+	ranks, err := s.repo.FrequencyRanks(ctx, lemmas)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make([]int, len(model.FrequencyBands))
+	answered := make([]int, len(model.FrequencyBands))
+	for lemma, rank := range ranks {
+		for i, to := range model.FrequencyBands {
+			if rank <= to {
+				answered[i]++
+				if answers[lemma] {
+					known[i]++
+				}
+				break
+			}
+		}
+	}
+
+	result, err := s.seed(ctx, userId, model.EstimateVocabularySize(known, answered), model.SourceQuiz)
+	if err != nil {
+		return nil, err
+	}
+
+	words := make([]*model.VocabularyWord, len(lemmas))
+	for i, lemma := range lemmas {
+		status := model.WordUnknown
+		if answers[lemma] {
+			status = model.WordKnown
+		}
+		words[i] = &model.VocabularyWord{UserId: userId, Lemma: lemma, Status: status, Source: model.SourceQuiz}
+	}
+	if err := s.repo.SaveAll(ctx, words, true); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
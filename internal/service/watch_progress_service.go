@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"shadowify/internal/apperr"
+	"shadowify/internal/model"
+	"shadowify/internal/repository"
+	"shadowify/internal/tracing"
+)
+
+type WatchProgressService struct {
+	repo      *repository.WatchProgressRepository
+	videoRepo *repository.VideoRepository
+}
+
+func NewWatchProgressService(repo *repository.WatchProgressRepository, videoRepo *repository.VideoRepository) *WatchProgressService {
+	return &WatchProgressService{
+		repo:      repo,
+		videoRepo: videoRepo,
+	}
+}
+
+// Save records where a user stopped in a video.
+func (s *WatchProgressService) Save(ctx context.Context, userId, videoId string, req *model.WatchProgressRequest) (*model.WatchProgress, error) {
+	ctx, span := tracing.Start(ctx, "WatchProgressService.Save")
+	defer span.End()
+
+	if userId == "" {
+		return nil, apperr.Unauthorized("unauthorized", "User not authenticated")
+	}
+	if _, err := s.videoRepo.GetById(ctx, videoId, userId); err != nil {
+		return nil, err
+	}
+
+	progress := &model.WatchProgress{
+		UserId:      userId,
+		VideoId:     videoId,
+		PositionSec: req.PositionSec,
+		Completed:   req.Completed,
+	}
+	if req.SegmentId != "" {
+		progress.SegmentId = &req.SegmentId
+	}
+	if err := s.repo.Save(ctx, progress); err != nil {
+		return nil, err
+	}
+	return progress, nil
+}
+
+func (s *WatchProgressService) Get(ctx context.Context, userId, videoId string) (*model.WatchProgress, error) {
+	if userId == "" {
+		return nil, apperr.Unauthorized("unauthorized", "User not authenticated")
+	}
+	return s.repo.FindByUserIdAndVideoId(ctx, userId, videoId)
+}
+
+// ContinueWatching lists the videos the user started and has not completed.
+func (s *WatchProgressService) ContinueWatching(ctx context.Context, filter *model.ContinueWatchingFilter) ([]*model.WatchedVideo, int64, error) {
+	ctx, span := tracing.Start(ctx, "WatchProgressService.ContinueWatching")
+	defer span.End()
+
+	if filter.UserId == "" {
+		return nil, 0, apperr.Unauthorized("unauthorized", "User not authenticated")
+	}
+	return s.repo.ContinueWatching(ctx, filter)
+}
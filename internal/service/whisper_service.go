@@ -5,37 +5,74 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"runtime"
 	"shadowify/internal/logger"
 	"shadowify/internal/model"
+	"shadowify/internal/procpool"
+	"shadowify/internal/runner"
+	"shadowify/internal/tracing"
 	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	// detectModel is used for language detection and short recordings.
+	detectModel = "ggml-tiny.bin"
+	// transcribeModel is used for full video transcripts.
+	transcribeModel = "ggml-base.en.bin"
 )
 
 type WhisperService struct {
+	runner        runner.Runner
+	pool          *procpool.Pool
+	ffmpegService *FFmpegService
 }
 
-func NewWhisperService() *WhisperService {
-	return &WhisperService{}
+func NewWhisperService(runner runner.Runner, pool *procpool.Pool, ffmpegService *FFmpegService) *WhisperService {
+	return &WhisperService{
+		runner:        runner,
+		pool:          pool,
+		ffmpegService: ffmpegService,
+	}
 }
 
-func (s *WhisperService) DetectLanguage(ctx context.Context, audioFilePath string) (string, error) {
-	wd, _ := os.Getwd()
-	cmd := exec.Command(filepath.Join(wd, "lib/whisper-cli"),
-		"-m", filepath.Join(wd, "lib/ggml-tiny.bin"),
-		"-f", audioFilePath,
+// run executes whisper-cli with the given model while holding a pool slot.
+func (s *WhisperService) run(ctx context.Context, priority procpool.Priority, modelName string, args ...string) (*runner.Result, error) {
+	_, waitSpan := tracing.Start(ctx, "procpool.acquire", attribute.String("procpool.priority", priority.String()))
+	release, err := s.pool.Acquire(ctx, priority)
+	tracing.RecordError(waitSpan, err)
+	waitSpan.End()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	args = append([]string{
+		"-m", libPath(modelName),
+		"-t", fmt.Sprintf("%d", s.pool.Threads()),
 		"-np",
-		"-dl",
-		"-t", fmt.Sprintf("%d", runtime.NumCPU()-2),
-		"-oj",
-	)
+	}, args...)
+	result, err := s.runner.Run(ctx, runner.Command{
+		Name: libPath("whisper-cli"),
+		Args: args,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to run whisper-cli: %w", err)
+	}
+	return result, nil
+}
+
+func (s *WhisperService) DetectLanguage(ctx context.Context, audioFilePath string) (string, error) {
+	ctx, span := tracing.Start(ctx, "WhisperService.DetectLanguage")
+	defer span.End()
 
-	if err := cmd.Run(); err != nil {
-		return "", fmt.Errorf("failed to run whisper-cli: %w\nOutput: %s", err, audioFilePath)
+	if _, err := s.run(ctx, procpool.PriorityBatch, detectModel, "-f", audioFilePath, "-dl", "-oj"); err != nil {
+		return "", err
 	}
 
 	jsonPath := audioFilePath + ".json"
+	defer removeFile(jsonPath)
 	data, err := os.ReadFile(jsonPath)
 	if err != nil {
 		return "", fmt.Errorf("failed to read json output: %w", err)
@@ -51,31 +88,26 @@ func (s *WhisperService) DetectLanguage(ctx context.Context, audioFilePath strin
 		return "", fmt.Errorf("failed to parse json: %w", err)
 	}
 
-	if err := os.Remove(jsonPath); err != nil {
-		return "", fmt.Errorf("failed to delete json file: %w", err)
-	}
-
 	return parsed.Result.Language, nil
 }
 
 func (s *WhisperService) Transcribe(ctx context.Context, audioFilePath string) ([]*model.Segment, error) {
-	wd, _ := os.Getwd()
-	cmd := exec.Command(filepath.Join(wd, "lib/whisper-cli"),
-		"-m", filepath.Join(wd, "lib/ggml-base.en.bin"),
+	ctx, span := tracing.Start(ctx, "WhisperService.Transcribe")
+	defer span.End()
+
+	_, err := s.run(ctx, procpool.PriorityBatch, transcribeModel,
 		"-f", audioFilePath,
-		"-np",
-		"-t", fmt.Sprintf("%d", runtime.NumCPU()-2),
 		"-oj",
 		"-sow",
 		"-ml", "500",
 		"-wt", "0.05",
 	)
-
-	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("failed to run whisper-cli: %w\nOutput: %s", err, audioFilePath)
+	if err != nil {
+		return nil, err
 	}
 
 	jsonPath := audioFilePath + ".json"
+	defer removeFile(jsonPath)
 	data, err := os.ReadFile(jsonPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read json output: %w", err)
@@ -104,51 +136,59 @@ func (s *WhisperService) Transcribe(ctx context.Context, audioFilePath string) (
 		}
 	}
 
-	if err := os.Remove(jsonPath); err != nil {
-		return nil, fmt.Errorf("failed to delete json file: %w", err)
-	}
-
 	return segments, nil
 }
 
-func convertToWav(inputPath, outputPath string) error {
-	cmd := exec.Command("ffmpeg", "-y", "-i", inputPath,
-		"-ar", "16000", "-ac", "1", "-c:a", "pcm_s16le", outputPath)
-	cmd.Stderr = os.Stderr // để debug lỗi nếu cần
-	cmd.Stdout = os.Stdout
-	return cmd.Run()
+// libPath returns the absolute path of a file shipped under lib/.
+func libPath(name string) string {
+	wd, _ := os.Getwd()
+	return filepath.Join(wd, "lib", name)
 }
 
-func (s *WhisperService) TranscribeNoTimestamps(ctx context.Context, audioFilePath string) (string, error) {
+// CheckInstall verifies that whisper-cli runs and the models it needs are
+// present, returning the model files found.
+func (s *WhisperService) CheckInstall(ctx context.Context) (string, error) {
+	var missing []string
+	for _, modelName := range []string{detectModel, transcribeModel} {
+		if _, err := os.Stat(libPath(modelName)); err != nil {
+			missing = append(missing, modelName)
+		}
+	}
+	if len(missing) > 0 {
+		return "", fmt.Errorf("missing whisper models: %s", strings.Join(missing, ", "))
+	}
 
-	wavPath := strings.TrimSuffix(audioFilePath, filepath.Ext(audioFilePath)) + ".wav"
-	if err := convertToWav(audioFilePath, wavPath); err != nil {
-		return "", fmt.Errorf("failed to convert to wav: %w", err)
+	// Printing the usage does not load a model, so no pool slot is needed.
+	if _, err := s.runner.Run(ctx, runner.Command{
+		Name: libPath("whisper-cli"),
+		Args: []string{"-h"},
+	}); err != nil {
+		return "", err
 	}
-	wd, _ := os.Getwd()
-	cmd := exec.Command(filepath.Join(wd, "lib/whisper-cli"),
-		"-m", filepath.Join(wd, "lib/ggml-tiny.bin"),
+	return strings.Join([]string{detectModel, transcribeModel}, ", "), nil
+}
+
+// removeFile deletes an intermediate file produced by an external tool.
+func removeFile(path string) {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		logger.WithFields(logger.Fields{"path": path, "error": err.Error()}).Error("Failed to remove file")
+	}
+}
+
+// TranscribeWav transcribes a 16kHz mono wav file without timestamps.
+func (s *WhisperService) TranscribeWav(ctx context.Context, wavPath string) (string, error) {
+	ctx, span := tracing.Start(ctx, "WhisperService.TranscribeWav")
+	defer span.End()
+
+	result, err := s.run(ctx, procpool.PriorityInteractive, detectModel,
 		"-f", wavPath,
-		"-np",
 		"-nt",
 		"-nf",
 		"-l", "auto",
-		"-t", fmt.Sprintf("%d", runtime.NumCPU()-2),
 	)
-	output, err := cmd.Output()
 	if err != nil {
-		return "", fmt.Errorf("failed to run whisper-cli: %w", err)
+		return "", err
 	}
 
-	go func() {
-		if err := os.Remove(wavPath); err != nil {
-			logger.Errorf("failed to delete wav file: %v", err)
-		}
-
-		if err := os.Remove(audioFilePath); err != nil {
-			logger.Errorf("failed to delete output file: %v", err)
-		}
-	}()
-
-	return strings.TrimSpace(string(output)), nil
+	return strings.TrimSpace(string(result.Stdout)), nil
 }
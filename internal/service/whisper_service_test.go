@@ -2,13 +2,19 @@ package service
 
 import (
 	"context"
+	"os"
+	"path/filepath"
+	"shadowify/internal/config"
+	"shadowify/internal/procpool"
+	"shadowify/internal/runner"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 )
 
 func TestWhisperService_DetectLanguage(t *testing.T) {
-	service := NewWhisperService()
+	r := runner.New(config.RunnerConfig{})
+	service := NewWhisperService(r, procpool.New(config.ProcPoolConfig{}), NewFFmpegService(r))
 	audioFilePath := "./tmp/nawe0Nl93IA.wav" // Replace with a valid audio file path for testing
 
 	// Call the DetectLanguage method
@@ -21,3 +27,49 @@ func TestWhisperService_DetectLanguage(t *testing.T) {
 	assert.NotEmpty(t, language, "Expected a non-empty language result")
 	assert.Equal(t, "ja", language, "Expected language to be 'jp'") // Adjust expected value based on your test audio file
 }
+
+func TestWhisperService_Transcribe(t *testing.T) {
+	fake := &runner.Fake{
+		Handler: func(ctx context.Context, cmd runner.Command) (*runner.Result, error) {
+			output := `{"transcription":[
+				{"text":" Hello there.","offsets":{"from":0,"to":1500}},
+				{"text":" How are you?","offsets":{"from":1500,"to":3200}}
+			]}`
+			for i, arg := range cmd.Args {
+				if arg == "-f" {
+					return nil, os.WriteFile(cmd.Args[i+1]+".json", []byte(output), 0644)
+				}
+			}
+			return nil, &runner.Error{Tool: cmd.Tool(), ExitCode: 1, Stderr: "no input file"}
+		},
+	}
+	service := NewWhisperService(fake, procpool.New(config.ProcPoolConfig{Threads: 2}), NewFFmpegService(fake))
+	audioFilePath := filepath.Join(t.TempDir(), "audio.wav")
+
+	segments, err := service.Transcribe(context.Background(), audioFilePath)
+	assert.NoError(t, err)
+	assert.Len(t, segments, 2)
+	assert.Equal(t, "Hello there.", segments[0].Content)
+	assert.Equal(t, float32(1.5), segments[1].StartSec)
+	assert.Equal(t, float32(3.2), segments[1].EndSec)
+	assert.NoFileExists(t, audioFilePath+".json")
+
+	calls := fake.Calls()
+	assert.Len(t, calls, 1)
+	assert.Equal(t, "whisper-cli", calls[0].Tool())
+	assert.Contains(t, calls[0].Args, audioFilePath)
+}
+
+func TestWhisperService_TranscribeFailure(t *testing.T) {
+	fake := &runner.Fake{
+		Handler: func(ctx context.Context, cmd runner.Command) (*runner.Result, error) {
+			return nil, &runner.Error{Tool: cmd.Tool(), ExitCode: 1, Stderr: "failed to read audio"}
+		},
+	}
+	service := NewWhisperService(fake, procpool.New(config.ProcPoolConfig{}), NewFFmpegService(fake))
+
+	_, err := service.Transcribe(context.Background(), filepath.Join(t.TempDir(), "audio.wav"))
+	var runErr *runner.Error
+	assert.ErrorAs(t, err, &runErr)
+	assert.Equal(t, "failed to read audio", runErr.Stderr)
+}
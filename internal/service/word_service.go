@@ -2,68 +2,116 @@ package service
 
 import (
 	"context"
+	"errors"
 	"shadowify/internal/apperr"
+	"shadowify/internal/logger"
 	"shadowify/internal/model"
+	"shadowify/internal/nlp"
 	"shadowify/internal/repository"
 )
 
 type WordService struct {
 	wordRepository    *repository.WordRepository
 	translatorService *TranslatorService
+	vocabularyService *VocabularyService
+	dictionaryService *DictionaryService
 }
 
-func NewWordService(wordRepository *repository.WordRepository, translatorService *TranslatorService) *WordService {
+func NewWordService(wordRepository *repository.WordRepository, translatorService *TranslatorService, vocabularyService *VocabularyService, dictionaryService *DictionaryService) *WordService {
 	return &WordService{
 		wordRepository:    wordRepository,
 		translatorService: translatorService,
+		vocabularyService: vocabularyService,
+		dictionaryService: dictionaryService,
 	}
 }
 
+// GetByWord returns the saved word with the same lemma as word, so that
+// "Ran" finds a saved "run".
 func (s *WordService) GetByWord(ctx context.Context, word string, userId string) (*model.Word, error) {
-	if word == "" {
-		return nil, apperr.NewAppErr("bad_request", "Word is required")
+	lemma := nlp.Normalize(word)
+	if lemma == "" {
+		return nil, apperr.Validation("validation.required", "Word is required").WithField("word")
 	}
 
 	if userId == "" {
-		return nil, apperr.NewAppErr("bad_request", "User ID is required")
+		return nil, apperr.Unauthorized("unauthorized", "User not authenticated")
 	}
 
-	return s.wordRepository.FindByWord(ctx, word, userId)
+	found, err := s.wordRepository.FindByLemma(ctx, lemma, userId)
+	if err != nil {
+		return nil, err
+	}
+	s.enrich(ctx, found)
+	return found, nil
 }
 
 func (s *WordService) List(ctx context.Context, filter *model.WordFilter) ([]*model.Word, int64, error) {
-	return s.wordRepository.List(ctx, filter)
+	words, total, err := s.wordRepository.List(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	s.enrich(ctx, words...)
+	return words, total, nil
+}
+
+// enrich adds the dictionary entries to words. The words are still returned
+// when the dictionary cannot be read.
+func (s *WordService) enrich(ctx context.Context, words ...*model.Word) {
+	if err := s.dictionaryService.Enrich(ctx, words...); err != nil {
+		logger.WithContext(ctx).WithFields(logger.Fields{"error": err.Error()}).Warn("Failed to add dictionary entries to words")
+	}
 }
 
 func (s *WordService) Create(ctx context.Context, word *model.Word) error {
 	if word.UserId == "" {
-		return apperr.NewAppErr("bad_request", "User ID is required")
+		return apperr.Unauthorized("unauthorized", "User not authenticated")
 	}
 
-	if word.MeaningEN == "" {
-		return apperr.NewAppErr("bad_request", "Meaning in English is required")
+	word.Lemma = nlp.Normalize(word.MeaningEN)
+	if word.Lemma == "" {
+		return apperr.Validation("validation.required", "Meaning in English is required").WithField("meaning_en")
 	}
 
-	w, _ := s.wordRepository.FindByWord(ctx, word.MeaningEN, word.UserId)
-	if w != nil {
-		return apperr.NewAppErr("word_exists", "Word already exists").WithCause(nil)
+	// Inflected forms of a saved word are the same word. Concurrent requests
+	// pass this check, the unique lemma index rejects all but one of them.
+	_, err := s.wordRepository.FindByLemma(ctx, word.Lemma, word.UserId)
+	if err == nil {
+		return apperr.Conflict("word.already_exists", "Word already exists")
+	}
+	if !apperr.Is(err, apperr.KindNotFound) {
+		return err
 	}
 
 	meaningVI, err := s.translatorService.Translate(ctx, &model.TranslateInput{
 		Text: word.MeaningEN,
 	})
 	if err != nil {
-		return apperr.NewAppErr("translation_error", "Failed to translate meaning").WithCause(err)
+		// Quota and rate limit errors must reach the client as they are
+		var appErr *apperr.AppErr
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return apperr.Unavailable("translator.translate.error", "Failed to translate meaning").WithCause(err)
 	}
 	word.MeaningVI = meaningVI.Text
 
-	return s.wordRepository.Create(ctx, word)
+	if err := s.wordRepository.Create(ctx, word); err != nil {
+		return err
+	}
+	// The saved word stays usable when the vocabulary cannot be updated
+	if err := s.vocabularyService.MarkSaved(ctx, word.UserId, word.MeaningEN); err != nil {
+		logger.WithContext(ctx).WithFields(logger.Fields{"error": err.Error()}).Warn("Failed to add saved word to vocabulary")
+	}
+	return nil
 }
 
+// DeleteByWord deletes the saved words with the same lemma as word.
 func (s *WordService) DeleteByWord(ctx context.Context, word string, userId string) error {
-	if word == "" {
-		return apperr.NewAppErr("bad_request", "Word is required")
+	lemma := nlp.Normalize(word)
+	if lemma == "" {
+		return apperr.Validation("validation.required", "Word is required").WithField("word")
 	}
 
-	return s.wordRepository.DeleteByWord(ctx, word, userId)
+	return s.wordRepository.DeleteByLemma(ctx, lemma, userId)
 }
@@ -5,38 +5,44 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"shadowify/internal/model"
-
-	"github.com/google/uuid"
+	"shadowify/internal/runner"
+	"shadowify/internal/tracing"
+	"strings"
 )
 
 type YTDLPService struct {
+	runner runner.Runner
 }
 
-func NewYTDLPService() *YTDLPService {
-	return &YTDLPService{}
+func NewYTDLPService(runner runner.Runner) *YTDLPService {
+	return &YTDLPService{runner: runner}
 }
 
-func (s *YTDLPService) DownloadAndExtract(ctx context.Context, youtubeId string) (*model.YoutubeMetadata, string, error) {
-	uid := uuid.New().String()
-	outputBase := filepath.Join("./tmp", uid)
+// DownloadAndExtract downloads the audio track of a video as wav into dir and
+// returns its metadata and the audio path.
+func (s *YTDLPService) DownloadAndExtract(ctx context.Context, youtubeId string, dir string) (*model.YoutubeMetadata, string, error) {
+	ctx, span := tracing.Start(ctx, "YTDLPService.DownloadAndExtract")
+	defer span.End()
 
-	cmd := exec.CommandContext(ctx, "yt-dlp",
-		"-x",
-		"--audio-format", "wav",
-		"--write-info-json",
-		"-o", outputBase,
-		"https://www.youtube.com/watch?v="+youtubeId,
-	)
+	outputBase := filepath.Join(dir, youtubeId)
 
-	if err := cmd.Run(); err != nil {
+	_, err := s.runner.Run(ctx, runner.Command{
+		Name: "yt-dlp",
+		Args: []string{
+			"-x",
+			"--audio-format", "wav",
+			"--write-info-json",
+			"-o", outputBase,
+			"https://www.youtube.com/watch?v=" + youtubeId,
+		},
+	})
+	if err != nil {
 		return nil, "", fmt.Errorf("yt-dlp command failed: %w", err)
 	}
 
 	jsonPath := outputBase + ".info.json"
-	defer os.Remove(jsonPath)
 
 	jsonData, err := os.ReadFile(jsonPath)
 	if err != nil {
@@ -52,3 +58,15 @@ func (s *YTDLPService) DownloadAndExtract(ctx context.Context, youtubeId string)
 
 	return metadata, audioPath, nil
 }
+
+// Version returns the installed yt-dlp release.
+func (s *YTDLPService) Version(ctx context.Context) (string, error) {
+	result, err := s.runner.Run(ctx, runner.Command{
+		Name: "yt-dlp",
+		Args: []string{"--version"},
+	})
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(result.Stdout)), nil
+}
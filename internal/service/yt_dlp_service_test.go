@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"shadowify/internal/config"
+	"shadowify/internal/runner"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -9,8 +11,8 @@ import (
 
 func TestExtractMetadata(t *testing.T) {
 	youutubeId := "nawe0Nl93IA"
-	service := NewYTDLPService()
-	metadata, filePath, err := service.DownloadAndExtract(context.Background(), youutubeId)
+	service := NewYTDLPService(runner.New(config.RunnerConfig{}))
+	metadata, filePath, err := service.DownloadAndExtract(context.Background(), youutubeId, t.TempDir())
 	assert.NoError(t, err)
 	// log.Printf("Metadata: %+v", metadata)
 	t.Logf("File Path: %s", filePath)
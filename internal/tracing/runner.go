@@ -0,0 +1,35 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"shadowify/internal/runner"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Runner wraps every command run by next in a span named after the tool.
+type Runner struct {
+	next runner.Runner
+}
+
+func NewRunner(next runner.Runner) *Runner {
+	return &Runner{next: next}
+}
+
+func (r *Runner) Run(ctx context.Context, cmd runner.Command) (*runner.Result, error) {
+	tool := cmd.Tool()
+	ctx, span := Start(ctx, "exec "+tool,
+		attribute.String("process.executable.name", tool),
+		attribute.StringSlice("process.command_args", cmd.Args),
+	)
+	defer span.End()
+
+	result, err := r.next.Run(ctx, cmd)
+	var runErr *runner.Error
+	if errors.As(err, &runErr) && runErr.ExitCode > 0 {
+		span.SetAttributes(attribute.Int("process.exit.code", runErr.ExitCode))
+	}
+	RecordError(span, err)
+	return result, err
+}
@@ -0,0 +1,44 @@
+package tracing
+
+import (
+	"context"
+	"shadowify/internal/config"
+	"shadowify/internal/runner"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSetup_Disabled(t *testing.T) {
+	shutdown, err := Setup(context.Background(), config.TracingConfig{})
+	require.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+
+	_, err = Setup(context.Background(), config.TracingConfig{Enabled: true, Exporter: "zipkin"})
+	assert.Error(t, err)
+}
+
+func TestRunner(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	fake := &runner.Fake{
+		Handler: func(ctx context.Context, cmd runner.Command) (*runner.Result, error) {
+			return nil, &runner.Error{Tool: cmd.Tool(), ExitCode: 2, Stderr: "bad input"}
+		},
+	}
+	_, err := NewRunner(fake).Run(context.Background(), runner.Command{Name: "/usr/bin/ffmpeg", Args: []string{"-i", "in.wav"}})
+	assert.Error(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "exec ffmpeg", spans[0].Name())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Contains(t, spans[0].Attributes(), attribute.Int("process.exit.code", 2))
+}
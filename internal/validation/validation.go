@@ -0,0 +1,160 @@
+// Package validation checks request DTOs against their validate struct tags
+// and reports every invalid field as an apperr.AppErr.
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"shadowify/internal/apperr"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+const defaultMaxAudioSize = 10 << 20
+
+var (
+	cefrLevels     = map[string]bool{"A1": true, "A2": true, "B1": true, "B2": true, "C1": true, "C2": true}
+	languageCodeRe = regexp.MustCompile(`^[a-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+	youtubeIdRe    = regexp.MustCompile(`^[A-Za-z0-9_-]{11}$`)
+	youtubeHosts   = map[string]bool{"youtube.com": true, "www.youtube.com": true, "m.youtube.com": true, "music.youtube.com": true}
+)
+
+// Validator implements echo.Validator.
+type Validator struct {
+	validate *validator.Validate
+}
+
+// New creates a validator with the custom rules registered. maxAudioSize is
+// the largest decoded size accepted by the audio_size rule.
+func New(maxAudioSize int64) *Validator {
+	if maxAudioSize <= 0 {
+		maxAudioSize = defaultMaxAudioSize
+	}
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	validate.RegisterTagNameFunc(fieldName)
+
+	validate.RegisterValidation("cefr", func(fl validator.FieldLevel) bool {
+		return IsCEFRLevel(fl.Field().String())
+	})
+	validate.RegisterValidation("language_code", func(fl validator.FieldLevel) bool {
+		return languageCodeRe.MatchString(fl.Field().String())
+	})
+	validate.RegisterValidation("youtube_id", func(fl validator.FieldLevel) bool {
+		_, ok := ParseYoutubeId(fl.Field().String())
+		return ok
+	})
+	validate.RegisterValidation("audio_size", func(fl validator.FieldLevel) bool {
+		return decodedLen(fl.Field().String()) <= maxAudioSize
+	})
+
+	return &Validator{validate: validate}
+}
+
+// Validate checks i and returns an apperr.List with one entry per invalid
+// field.
+func (v *Validator) Validate(i any) error {
+	err := v.validate.Struct(i)
+	if err == nil {
+		return nil
+	}
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		return apperr.Internal("validation.error", "Failed to validate request").WithCause(err)
+	}
+
+	list := make(apperr.List, 0, len(fieldErrs))
+	for _, fe := range fieldErrs {
+		appErr := apperr.Validation("validation."+fe.Tag(), message(fe)).WithField(fe.Field())
+		if fe.Param() != "" {
+			appErr.WithParam("param", fe.Param())
+		}
+		list = append(list, appErr)
+	}
+	return list
+}
+
+// IsCEFRLevel reports whether level is one of A1 to C2.
+func IsCEFRLevel(level string) bool {
+	return cefrLevels[level]
+}
+
+// ParseYoutubeId extracts the video id from a bare id or from a youtube.com
+// watch URL or youtu.be short link.
+func ParseYoutubeId(raw string) (string, bool) {
+	raw = strings.TrimSpace(raw)
+	if youtubeIdRe.MatchString(raw) {
+		return raw, true
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", false
+	}
+	var id string
+	switch {
+	case u.Host == "youtu.be":
+		id = strings.TrimPrefix(u.Path, "/")
+	case youtubeHosts[u.Host] && u.Path == "/watch":
+		id = u.Query().Get("v")
+	case youtubeHosts[u.Host] && strings.HasPrefix(u.Path, "/shorts/"):
+		id = strings.TrimPrefix(u.Path, "/shorts/")
+	}
+	if !youtubeIdRe.MatchString(id) {
+		return "", false
+	}
+	return id, true
+}
+
+// decodedLen returns the size of the data encoded in s without decoding it.
+func decodedLen(s string) int64 {
+	padding := len(s) - len(strings.TrimRight(s, "="))
+	return int64(len(s)/4*3 - padding)
+}
+
+// fieldName reports fields by their json or query name so that errors match
+// what the client sent.
+func fieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "query", "form"} {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+func message(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return fmt.Sprintf("%s is required", fe.Field())
+	case "min":
+		return fmt.Sprintf("%s must be at least %s", fe.Field(), fe.Param())
+	case "max":
+		return fmt.Sprintf("%s must be at most %s", fe.Field(), fe.Param())
+	case "oneof":
+		return fmt.Sprintf("%s must be one of: %s", fe.Field(), fe.Param())
+	case "uuid":
+		return fmt.Sprintf("%s must be a valid id", fe.Field())
+	case "url":
+		return fmt.Sprintf("%s must be a valid URL", fe.Field())
+	case "base64":
+		return fmt.Sprintf("%s must be base64 encoded", fe.Field())
+	case "cefr":
+		return fmt.Sprintf("%s must be a CEFR level from A1 to C2", fe.Field())
+	case "language_code":
+		return fmt.Sprintf("%s must be a language code such as en or pt-BR", fe.Field())
+	case "youtube_id":
+		return fmt.Sprintf("%s must be a YouTube video id or URL", fe.Field())
+	case "audio_size":
+		return fmt.Sprintf("%s exceeds the maximum audio size", fe.Field())
+	default:
+		return fmt.Sprintf("%s is invalid", fe.Field())
+	}
+}
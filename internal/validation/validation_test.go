@@ -0,0 +1,125 @@
+package validation
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"shadowify/internal/apperr"
+	"shadowify/internal/response"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testRequest struct {
+	SegmentId string `json:"segment_id" validate:"required,uuid"`
+	Language  string `json:"language" validate:"omitempty,language_code"`
+	Cefr      string `query:"cefr" validate:"omitempty,cefr"`
+	Video     string `json:"video" validate:"omitempty,youtube_id"`
+	Audio     string `json:"audio" validate:"omitempty,audio_size"`
+}
+
+func TestValidate(t *testing.T) {
+	v := New(8)
+	valid := testRequest{SegmentId: "7b0f0c1e-5d0a-4a55-9e7c-1f2d3c4b5a69"}
+
+	tests := []struct {
+		name   string
+		modify func(r *testRequest)
+		want   map[string]string
+	}{
+		{name: "valid", modify: func(r *testRequest) {}},
+		{name: "missing required", modify: func(r *testRequest) { r.SegmentId = "" }, want: map[string]string{"segment_id": "validation.required"}},
+		{name: "language code", modify: func(r *testRequest) { r.Language = "pt-BR" }},
+		{name: "invalid language code", modify: func(r *testRequest) { r.Language = "English" }, want: map[string]string{"language": "validation.language_code"}},
+		{name: "cefr level", modify: func(r *testRequest) { r.Cefr = "B2" }},
+		{name: "invalid cefr level", modify: func(r *testRequest) { r.Cefr = "D1" }, want: map[string]string{"cefr": "validation.cefr"}},
+		{name: "youtube url", modify: func(r *testRequest) { r.Video = "https://www.youtube.com/watch?v=dQw4w9WgXcQ" }},
+		{name: "invalid youtube id", modify: func(r *testRequest) { r.Video = "not a video" }, want: map[string]string{"video": "validation.youtube_id"}},
+		{name: "audio within limit", modify: func(r *testRequest) { r.Audio = base64.StdEncoding.EncodeToString([]byte("12345678")) }},
+		{name: "audio too large", modify: func(r *testRequest) { r.Audio = base64.StdEncoding.EncodeToString([]byte("123456789")) }, want: map[string]string{"audio": "validation.audio_size"}},
+		{
+			name: "multiple errors",
+			modify: func(r *testRequest) {
+				r.SegmentId = "abc"
+				r.Cefr = "X"
+			},
+			want: map[string]string{"segment_id": "validation.uuid", "cefr": "validation.cefr"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := valid
+			tt.modify(&req)
+
+			err := v.Validate(&req)
+			if tt.want == nil {
+				assert.NoError(t, err)
+				return
+			}
+			var list apperr.List
+			require.ErrorAs(t, err, &list)
+			got := make(map[string]string, len(list))
+			for _, appErr := range list {
+				assert.Equal(t, apperr.KindValidation, appErr.Kind)
+				got[appErr.Field] = appErr.Code
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseYoutubeId(t *testing.T) {
+	tests := []struct {
+		raw    string
+		want   string
+		wantOk bool
+	}{
+		{raw: "dQw4w9WgXcQ", want: "dQw4w9WgXcQ", wantOk: true},
+		{raw: " dQw4w9WgXcQ ", want: "dQw4w9WgXcQ", wantOk: true},
+		{raw: "https://www.youtube.com/watch?v=dQw4w9WgXcQ&t=42", want: "dQw4w9WgXcQ", wantOk: true},
+		{raw: "https://youtu.be/dQw4w9WgXcQ", want: "dQw4w9WgXcQ", wantOk: true},
+		{raw: "https://youtube.com/shorts/dQw4w9WgXcQ", want: "dQw4w9WgXcQ", wantOk: true},
+		{raw: "https://example.com/watch?v=dQw4w9WgXcQ"},
+		{raw: "https://youtu.be/short"},
+		{raw: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, ok := ParseYoutubeId(tt.raw)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestValidateWritesAllErrors(t *testing.T) {
+	e := echo.New()
+	e.Validator = New(0)
+	e.POST("/", func(c echo.Context) error {
+		var req testRequest
+		if err := c.Bind(&req); err != nil {
+			return response.WriteError(c, apperr.BadRequest("bad_request", "Invalid request format"))
+		}
+		if err := c.Validate(&req); err != nil {
+			return response.WriteError(c, err)
+		}
+		return response.Success(c, nil)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"language":"???","video":"x"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	body := rec.Body.String()
+	for _, field := range []string{`"field":"segment_id"`, `"field":"language"`, `"field":"video"`} {
+		assert.Contains(t, body, field)
+	}
+}
@@ -0,0 +1,188 @@
+// Package views counts video views. A view is counted once per viewer and
+// video within a deduplication window. Viewers are told apart by device id,
+// or by address when they send none. Views are buffered in memory and
+// written in batches, which also updates the daily counts that trending
+// videos are ranked by.
+package views
+
+import (
+	"context"
+	"shadowify/internal/config"
+	"shadowify/internal/logger"
+	"shadowify/internal/metrics"
+	"shadowify/internal/model"
+	"sync"
+	"time"
+)
+
+const (
+	defaultDedupWindow   = 30 * time.Minute
+	defaultFlushInterval = 10 * time.Second
+	defaultBatchSize     = 500
+	defaultMaxDevices    = 20
+	// maxPendingBatches bounds the buffer while the database is unavailable.
+	maxPendingBatches = 20
+	// cleanupInterval is how often the views of past windows are deleted.
+	cleanupInterval = time.Hour
+)
+
+// Store writes the views.
+type Store interface {
+	// AddViews counts views, skipping those already counted in their window
+	// by another instance or before a restart.
+	AddViews(ctx context.Context, views []model.VideoView) error
+	// Cleanup deletes the views of windows that started before before.
+	Cleanup(ctx context.Context, before time.Time) error
+}
+
+// Counter deduplicates and buffers views until they are flushed to the store.
+type Counter struct {
+	cfg   config.ViewsConfig
+	store Store
+	now   func() time.Time
+	full  chan struct{}
+
+	mu      sync.Mutex
+	window  time.Time
+	seen    map[viewKey]struct{}
+	devices map[viewKey]int
+	pending []model.VideoView
+}
+
+type viewKey struct {
+	videoId  string
+	viewerId string
+}
+
+func NewCounter(cfg config.ViewsConfig, store Store) *Counter {
+	if cfg.DedupWindow <= 0 {
+		cfg.DedupWindow = defaultDedupWindow
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = defaultFlushInterval
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultBatchSize
+	}
+	if cfg.MaxDevicesPerIP <= 0 {
+		cfg.MaxDevicesPerIP = defaultMaxDevices
+	}
+	return &Counter{
+		cfg:     cfg,
+		store:   store,
+		now:     time.Now,
+		full:    make(chan struct{}, 1),
+		seen:    make(map[viewKey]struct{}),
+		devices: make(map[viewKey]int),
+	}
+}
+
+// Record counts a view of a video from a device, or from an address when
+// deviceId is empty, unless it was already counted in the current window.
+// The device id is chosen by the client, so at most MaxDevicesPerIP devices
+// are counted per address and video. It does not wait for the store.
+func (c *Counter) Record(videoId, deviceId, ip string) {
+	window := c.now().UTC().Truncate(c.cfg.DedupWindow)
+	viewerId := "ip:" + ip
+	if deviceId != "" {
+		viewerId = "user:" + deviceId
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !window.Equal(c.window) {
+		c.window = window
+		clear(c.seen)
+		clear(c.devices)
+	}
+	key := viewKey{videoId: videoId, viewerId: viewerId}
+	if _, ok := c.seen[key]; ok {
+		metrics.ViewsRecorded.WithLabelValues("duplicate").Inc()
+		return
+	}
+	address := viewKey{videoId: videoId, viewerId: ip}
+	if deviceId != "" && c.devices[address] >= c.cfg.MaxDevicesPerIP {
+		metrics.ViewsRecorded.WithLabelValues("capped").Inc()
+		return
+	}
+	if len(c.pending) >= c.maxPending() {
+		metrics.ViewsRecorded.WithLabelValues("dropped").Inc()
+		return
+	}
+	c.seen[key] = struct{}{}
+	if deviceId != "" {
+		c.devices[address]++
+	}
+	c.pending = append(c.pending, model.VideoView{VideoId: videoId, ViewerId: viewerId, Window: window})
+	metrics.ViewsRecorded.WithLabelValues("counted").Inc()
+
+	if len(c.pending) >= c.cfg.BatchSize {
+		select {
+		case c.full <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Flush writes the buffered views in batches. The views that could not be
+// written are kept for the next flush.
+func (c *Counter) Flush(ctx context.Context) error {
+	c.mu.Lock()
+	pending := c.pending
+	c.pending = nil
+	c.mu.Unlock()
+
+	for len(pending) > 0 {
+		batch := pending[:min(len(pending), c.cfg.BatchSize)]
+		if err := c.store.AddViews(ctx, batch); err != nil {
+			c.requeue(pending)
+			return err
+		}
+		pending = pending[len(batch):]
+	}
+	return nil
+}
+
+// requeue puts views back in front of the buffer, dropping the oldest when
+// it is full.
+func (c *Counter) requeue(views []model.VideoView) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	pending := append(views, c.pending...)
+	if excess := len(pending) - c.maxPending(); excess > 0 {
+		metrics.ViewsRecorded.WithLabelValues("dropped").Add(float64(excess))
+		pending = pending[excess:]
+	}
+	c.pending = pending
+}
+
+func (c *Counter) maxPending() int {
+	return c.cfg.BatchSize * maxPendingBatches
+}
+
+// Run flushes the buffer every FlushInterval, or as soon as a batch is full,
+// and deletes the views of past windows until ctx is done. Views recorded
+// after that are written by a last call to Flush.
+func (c *Counter) Run(ctx context.Context) {
+	flush := time.NewTicker(c.cfg.FlushInterval)
+	defer flush.Stop()
+	cleanup := time.NewTicker(cleanupInterval)
+	defer cleanup.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-cleanup.C:
+			if err := c.store.Cleanup(ctx, c.now().Add(-c.cfg.DedupWindow)); err != nil {
+				logger.WithFields(logger.Fields{"error": err.Error()}).Error("Failed to clean up views")
+			}
+			continue
+		case <-flush.C:
+		case <-c.full:
+		}
+		if err := c.Flush(ctx); err != nil {
+			logger.WithFields(logger.Fields{"error": err.Error()}).Error("Failed to flush views")
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package views
+
+import (
+	"context"
+	"errors"
+	"shadowify/internal/config"
+	"shadowify/internal/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeStore struct {
+	batches [][]model.VideoView
+	err     error
+}
+
+func (s *fakeStore) AddViews(ctx context.Context, views []model.VideoView) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.batches = append(s.batches, views)
+	return nil
+}
+
+func (s *fakeStore) Cleanup(ctx context.Context, before time.Time) error {
+	return nil
+}
+
+func newTestCounter(store Store, batchSize int, now *time.Time) *Counter {
+	counter := NewCounter(config.ViewsConfig{DedupWindow: time.Hour, BatchSize: batchSize}, store)
+	counter.now = func() time.Time { return *now }
+	return counter
+}
+
+func TestCounter_Deduplicates(t *testing.T) {
+	store := &fakeStore{}
+	now := time.Date(2024, 1, 1, 12, 10, 0, 0, time.UTC)
+	counter := newTestCounter(store, 10, &now)
+
+	counter.Record("video", "device", "10.0.0.1")
+	counter.Record("video", "device", "10.0.0.1")
+	counter.Record("video", "other", "10.0.0.1")
+	counter.Record("other", "device", "10.0.0.1")
+	now = now.Add(time.Hour)
+	counter.Record("video", "device", "10.0.0.1")
+	require.NoError(t, counter.Flush(context.Background()))
+
+	window := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	require.Len(t, store.batches, 1)
+	assert.Equal(t, []model.VideoView{
+		{VideoId: "video", ViewerId: "user:device", Window: window},
+		{VideoId: "video", ViewerId: "user:other", Window: window},
+		{VideoId: "other", ViewerId: "user:device", Window: window},
+		{VideoId: "video", ViewerId: "user:device", Window: window.Add(time.Hour)},
+	}, store.batches[0], "a view counts once per window")
+}
+
+func TestCounter_CapsDevicesPerAddress(t *testing.T) {
+	store := &fakeStore{}
+	now := time.Date(2024, 1, 1, 12, 10, 0, 0, time.UTC)
+	counter := NewCounter(config.ViewsConfig{DedupWindow: time.Hour, BatchSize: 10, MaxDevicesPerIP: 2}, store)
+	counter.now = func() time.Time { return now }
+
+	counter.Record("video", "a", "10.0.0.1")
+	counter.Record("video", "b", "10.0.0.1")
+	counter.Record("video", "c", "10.0.0.1")
+	counter.Record("video", "a", "10.0.0.1")
+	counter.Record("video", "c", "10.0.0.2")
+	counter.Record("other", "c", "10.0.0.1")
+	counter.Record("video", "", "10.0.0.1")
+	counter.Record("video", "", "10.0.0.1")
+	now = now.Add(time.Hour)
+	counter.Record("video", "c", "10.0.0.1")
+	require.NoError(t, counter.Flush(context.Background()))
+
+	var viewers []string
+	for _, view := range store.batches[0] {
+		viewers = append(viewers, view.VideoId+" "+view.ViewerId)
+	}
+	assert.Equal(t, []string{
+		"video user:a",
+		"video user:b",
+		"video user:c",
+		"other user:c",
+		"video ip:10.0.0.1",
+		"video user:c",
+	}, viewers, "devices past the cap are not counted until the next window")
+}
+
+func TestCounter_FlushesInBatches(t *testing.T) {
+	store := &fakeStore{}
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	counter := newTestCounter(store, 2, &now)
+
+	counter.Record("a", "device", "10.0.0.1")
+	assert.Empty(t, counter.full)
+	counter.Record("b", "device", "10.0.0.1")
+	assert.Len(t, counter.full, 1, "a full batch triggers a flush")
+	counter.Record("c", "device", "10.0.0.1")
+
+	require.NoError(t, counter.Flush(context.Background()))
+	require.Len(t, store.batches, 2)
+	assert.Len(t, store.batches[0], 2)
+	assert.Len(t, store.batches[1], 1)
+
+	require.NoError(t, counter.Flush(context.Background()))
+	assert.Len(t, store.batches, 2, "flushed views are not written again")
+}
+
+func TestCounter_KeepsViewsWhenStoreFails(t *testing.T) {
+	store := &fakeStore{err: errors.New("unavailable")}
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	counter := newTestCounter(store, 1, &now)
+
+	for _, video := range []string{"a", "b", "c"} {
+		counter.Record(video, "device", "10.0.0.1")
+	}
+	assert.Error(t, counter.Flush(context.Background()))
+
+	counter.Record("d", "device", "10.0.0.1")
+	store.err = nil
+	require.NoError(t, counter.Flush(context.Background()))
+
+	var videos []string
+	for _, batch := range store.batches {
+		for _, view := range batch {
+			videos = append(videos, view.VideoId)
+		}
+	}
+	assert.Equal(t, []string{"a", "b", "c", "d"}, videos)
+}
+
+func TestCounter_DropsViewsWhenFull(t *testing.T) {
+	store := &fakeStore{}
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	counter := newTestCounter(store, 1, &now)
+
+	for i := range maxPendingBatches + 5 {
+		counter.Record(string(rune('a'+i)), "device", "10.0.0.1")
+	}
+	assert.Len(t, counter.pending, maxPendingBatches)
+}
@@ -0,0 +1,243 @@
+// Package workspace allocates per-job temp directories for audio processing.
+//
+// Every job gets its own directory under a shared root which is removed as a
+// whole when the job closes its workspace, so intermediate files produced by
+// external tools (wav conversions, whisper json output, yt-dlp metadata) are
+// cleaned up on every path. A janitor purges directories left behind by
+// crashed processes. Only job directories are purged, since the root may be
+// shared with other files.
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"shadowify/internal/apperr"
+	"shadowify/internal/config"
+	"shadowify/internal/logger"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultRoot            = "./tmp"
+	defaultQuota           = 5 << 30
+	defaultOrphanMaxAge    = time.Hour
+	defaultJanitorInterval = 10 * time.Minute
+)
+
+type Manager struct {
+	cfg config.WorkspaceConfig
+
+	mu     sync.Mutex
+	active map[string]struct{}
+}
+
+func NewManager(cfg config.WorkspaceConfig) (*Manager, error) {
+	if cfg.Root == "" {
+		cfg.Root = defaultRoot
+	}
+	if cfg.Quota <= 0 {
+		cfg.Quota = defaultQuota
+	}
+	if cfg.OrphanMaxAge <= 0 {
+		cfg.OrphanMaxAge = defaultOrphanMaxAge
+	}
+	if cfg.JanitorInterval <= 0 {
+		cfg.JanitorInterval = defaultJanitorInterval
+	}
+	if err := os.MkdirAll(cfg.Root, 0755); err != nil {
+		return nil, err
+	}
+	return &Manager{
+		cfg:    cfg,
+		active: make(map[string]struct{}),
+	}, nil
+}
+
+// Acquire creates a new workspace for a job. The caller must Close it.
+func (m *Manager) Acquire(job string) (*Workspace, error) {
+	usage, err := m.Usage()
+	if err != nil {
+		return nil, apperr.NewAppErr("workspace.usage.error", "Failed to compute workspace usage").WithCause(err)
+	}
+	if usage >= m.cfg.Quota {
+		return nil, apperr.Unavailable("workspace.quota_exceeded", "Not enough disk space to process the request").
+			WithParam("quota", m.cfg.Quota)
+	}
+
+	// Register before creating the directory so the janitor never sees it as
+	// an orphan.
+	// The name must match isJobDir for the janitor to purge it.
+	dir := filepath.Join(m.cfg.Root, job+"-"+uuid.NewString())
+	m.mu.Lock()
+	m.active[dir] = struct{}{}
+	m.mu.Unlock()
+
+	if err := os.Mkdir(dir, 0755); err != nil {
+		m.release(dir)
+		return nil, apperr.NewAppErr("workspace.create.error", "Failed to create workspace").WithCause(err)
+	}
+
+	return &Workspace{m: m, dir: dir}, nil
+}
+
+// Check reports an error when the root is not a directory.
+func (m *Manager) Check() error {
+	info, err := os.Stat(m.cfg.Root)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("workspace root %s is not a directory", m.cfg.Root)
+	}
+	return nil
+}
+
+// Usage returns the number of bytes currently stored under the root.
+func (m *Manager) Usage() (int64, error) {
+	var size int64
+	err := filepath.WalkDir(m.cfg.Root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Files may disappear while workspaces are closed concurrently.
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	return size, err
+}
+
+// Available returns the number of bytes left before the quota is reached.
+func (m *Manager) Available() int64 {
+	usage, err := m.Usage()
+	if err != nil || usage >= m.cfg.Quota {
+		return 0
+	}
+	return m.cfg.Quota - usage
+}
+
+// Purge removes the job directories under the root that do not belong to an
+// active workspace and were last modified more than maxAge ago. Other entries
+// are left alone.
+func (m *Manager) Purge(maxAge time.Duration) (int, error) {
+	entries, err := os.ReadDir(m.cfg.Root)
+	if err != nil {
+		return 0, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	purged := 0
+	cutoff := time.Now().Add(-maxAge)
+	for _, entry := range entries {
+		path := filepath.Join(m.cfg.Root, entry.Name())
+		if _, ok := m.active[path]; ok || !isJobDir(entry) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.ModTime().After(cutoff) {
+			continue
+		}
+		if err := os.RemoveAll(path); err != nil {
+			logger.WithFields(logger.Fields{"path": path, "error": err.Error()}).Warn("Failed to purge workspace entry")
+			continue
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+// RunJanitor purges every orphan left by a previous process, then keeps
+// purging stale entries until ctx is done.
+func (m *Manager) RunJanitor(ctx context.Context) {
+	if purged, err := m.Purge(0); err != nil {
+		logger.WithFields(logger.Fields{"root": m.cfg.Root, "error": err.Error()}).Error("Failed to purge workspace root")
+	} else if purged > 0 {
+		logger.WithFields(logger.Fields{"purged": purged}).Info("Purged orphaned workspace entries")
+	}
+
+	ticker := time.NewTicker(m.cfg.JanitorInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if purged, err := m.Purge(m.cfg.OrphanMaxAge); err != nil {
+				logger.WithFields(logger.Fields{"root": m.cfg.Root, "error": err.Error()}).Error("Failed to purge workspace root")
+			} else if purged > 0 {
+				logger.WithFields(logger.Fields{"purged": purged}).Info("Purged stale workspace entries")
+			}
+		}
+	}
+}
+
+// isJobDir reports whether an entry of the root is named like the directories
+// created by Acquire, "<job>-<uuid>".
+func isJobDir(entry fs.DirEntry) bool {
+	name := entry.Name()
+	const idLen = 36
+	return entry.IsDir() && len(name) > idLen+1 &&
+		name[len(name)-idLen-1] == '-' && uuid.Validate(name[len(name)-idLen:]) == nil
+}
+
+func (m *Manager) release(dir string) {
+	m.mu.Lock()
+	delete(m.active, dir)
+	m.mu.Unlock()
+}
+
+// Workspace is a temp directory owned by a single job.
+type Workspace struct {
+	m    *Manager
+	dir  string
+	once sync.Once
+}
+
+// Dir returns the workspace directory.
+func (w *Workspace) Dir() string {
+	return w.dir
+}
+
+// Path returns the path of a file inside the workspace.
+func (w *Workspace) Path(name string) string {
+	return filepath.Join(w.dir, name)
+}
+
+// Available returns how many bytes the job may still write.
+func (w *Workspace) Available() int64 {
+	return w.m.Available()
+}
+
+// Close removes the workspace directory and everything in it. It is safe to
+// call Close more than once.
+func (w *Workspace) Close() error {
+	var err error
+	w.once.Do(func() {
+		err = os.RemoveAll(w.dir)
+		w.m.release(w.dir)
+		if err != nil {
+			logger.WithFields(logger.Fields{"dir": w.dir, "error": err.Error()}).Error("Failed to remove workspace")
+		}
+	})
+	return err
+}
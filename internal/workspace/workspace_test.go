@@ -0,0 +1,112 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"shadowify/internal/apperr"
+	"shadowify/internal/config"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkspace_Close(t *testing.T) {
+	m, err := NewManager(config.WorkspaceConfig{Root: t.TempDir()})
+	require.NoError(t, err)
+
+	ws, err := m.Acquire("job")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(ws.Path("input.wav"), []byte("data"), 0644))
+	assert.DirExists(t, ws.Dir())
+
+	assert.NoError(t, ws.Close())
+	assert.NoDirExists(t, ws.Dir())
+	assert.NoError(t, ws.Close())
+}
+
+func TestManager_Quota(t *testing.T) {
+	m, err := NewManager(config.WorkspaceConfig{Root: t.TempDir(), Quota: 8})
+	require.NoError(t, err)
+
+	ws, err := m.Acquire("job")
+	require.NoError(t, err)
+	defer ws.Close()
+	assert.Equal(t, int64(8), ws.Available())
+
+	require.NoError(t, os.WriteFile(ws.Path("input.wav"), []byte("0123456789"), 0644))
+	assert.Equal(t, int64(0), ws.Available())
+
+	_, err = m.Acquire("job")
+	appErr, ok := err.(*apperr.AppErr)
+	require.True(t, ok)
+	assert.Equal(t, "workspace.quota_exceeded", appErr.Code)
+}
+
+func TestManager_Purge(t *testing.T) {
+	root := t.TempDir()
+	m, err := NewManager(config.WorkspaceConfig{Root: root})
+	require.NoError(t, err)
+
+	orphan := filepath.Join(root, "job-"+uuid.NewString())
+	require.NoError(t, os.Mkdir(orphan, 0755))
+	stale := time.Now().Add(-2 * time.Hour)
+	require.NoError(t, os.Chtimes(orphan, stale, stale))
+
+	recent := filepath.Join(root, "video-"+uuid.NewString())
+	require.NoError(t, os.Mkdir(recent, 0755))
+
+	ws, err := m.Acquire("job")
+	require.NoError(t, err)
+	defer ws.Close()
+	require.NoError(t, os.Chtimes(ws.Dir(), stale, stale))
+
+	purged, err := m.Purge(time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+	assert.NoDirExists(t, orphan)
+	assert.DirExists(t, recent)
+	assert.DirExists(t, ws.Dir())
+
+	purged, err = m.Purge(0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+	assert.NoDirExists(t, recent)
+	assert.DirExists(t, ws.Dir())
+}
+
+func TestManager_PurgeKeepsOtherFiles(t *testing.T) {
+	root := t.TempDir()
+	m, err := NewManager(config.WorkspaceConfig{Root: root})
+	require.NoError(t, err)
+
+	stale := time.Now().Add(-2 * time.Hour)
+	file := filepath.Join(root, "nawe0Nl93IA.wav")
+	require.NoError(t, os.WriteFile(file, []byte("data"), 0644))
+	dir := filepath.Join(root, "models")
+	require.NoError(t, os.Mkdir(dir, 0755))
+	named := filepath.Join(root, "job-"+uuid.NewString()+".wav")
+	require.NoError(t, os.WriteFile(named, []byte("data"), 0644))
+	for _, path := range []string{file, dir, named} {
+		require.NoError(t, os.Chtimes(path, stale, stale))
+	}
+
+	purged, err := m.Purge(0)
+	require.NoError(t, err)
+	assert.Equal(t, 0, purged)
+	assert.FileExists(t, file)
+	assert.DirExists(t, dir)
+	assert.FileExists(t, named)
+}
+
+func TestManager_Check(t *testing.T) {
+	root := t.TempDir()
+	m, err := NewManager(config.WorkspaceConfig{Root: root})
+	require.NoError(t, err)
+	assert.NoError(t, m.Check())
+
+	require.NoError(t, os.RemoveAll(root))
+	assert.Error(t, m.Check())
+}
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE
+    IF NOT EXISTS rate_limit_buckets (
+        key TEXT PRIMARY KEY,
+        tokens DOUBLE PRECISION NOT NULL,
+        updated_at TIMESTAMPTZ NOT NULL
+    );
+
+CREATE INDEX IF NOT EXISTS idx_rate_limit_buckets_updated_at ON rate_limit_buckets (updated_at);
+
+CREATE TABLE
+    IF NOT EXISTS usage_quotas (
+        key TEXT NOT NULL,
+        day DATE NOT NULL,
+        used BIGINT NOT NULL DEFAULT 0,
+        PRIMARY KEY (key, day)
+    );
+
+CREATE INDEX IF NOT EXISTS idx_usage_quotas_day ON usage_quotas (day);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS usage_quotas;
+
+DROP TABLE IF EXISTS rate_limit_buckets;
+
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE INDEX IF NOT EXISTS idx_videos_created_at_id ON videos (created_at DESC, id DESC);
+
+CREATE INDEX IF NOT EXISTS idx_videos_view_count_created_at_id ON videos (view_count DESC, created_at DESC, id DESC);
+
+CREATE INDEX IF NOT EXISTS idx_words_user_id_created_at_id ON words (user_id, created_at DESC, id DESC);
+
+CREATE INDEX IF NOT EXISTS idx_sentences_user_id_created_at_id ON sentences (user_id, created_at DESC, id DESC);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_sentences_user_id_created_at_id;
+
+DROP INDEX IF EXISTS idx_words_user_id_created_at_id;
+
+DROP INDEX IF EXISTS idx_videos_view_count_created_at_id;
+
+DROP INDEX IF EXISTS idx_videos_created_at_id;
+
+-- +goose StatementEnd
@@ -0,0 +1,47 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE videos
+ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('english', coalesce(full_title, '')), 'A') ||
+    setweight(to_tsvector('english', coalesce(tags, '[]'::jsonb)), 'B') ||
+    setweight(to_tsvector('english', coalesce(description, '')), 'C')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_videos_search_vector ON videos USING GIN (search_vector);
+
+ALTER TABLE words
+ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', coalesce(meaning_en, '')), 'A') ||
+    setweight(to_tsvector('simple', coalesce(meaning_vi, '')), 'B')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_words_search_vector ON words USING GIN (search_vector);
+
+ALTER TABLE sentences
+ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', coalesce(meaning_en, '')), 'A') ||
+    setweight(to_tsvector('simple', coalesce(meaning_vi, '')), 'B')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_sentences_search_vector ON sentences USING GIN (search_vector);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_sentences_search_vector;
+
+ALTER TABLE sentences
+DROP COLUMN IF EXISTS search_vector;
+
+DROP INDEX IF EXISTS idx_words_search_vector;
+
+ALTER TABLE words
+DROP COLUMN IF EXISTS search_vector;
+
+DROP INDEX IF EXISTS idx_videos_search_vector;
+
+ALTER TABLE videos
+DROP COLUMN IF EXISTS search_vector;
+
+-- +goose StatementEnd
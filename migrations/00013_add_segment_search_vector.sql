@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE segments
+ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (to_tsvector('english', coalesce(content, ''))) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_segments_search_vector ON segments USING GIN (search_vector);
+
+CREATE INDEX IF NOT EXISTS idx_segments_video_id_start_sec ON segments (video_id, start_sec);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_segments_video_id_start_sec;
+
+DROP INDEX IF EXISTS idx_segments_search_vector;
+
+ALTER TABLE segments
+DROP COLUMN IF EXISTS search_vector;
+
+-- +goose StatementEnd
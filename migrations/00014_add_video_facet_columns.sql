@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE videos
+ADD COLUMN IF NOT EXISTS channel TEXT NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS channel_id TEXT NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS subtitle_languages jsonb NOT NULL DEFAULT '[]'::jsonb;
+
+CREATE INDEX IF NOT EXISTS idx_videos_categories ON videos USING GIN (categories jsonb_path_ops);
+
+CREATE INDEX IF NOT EXISTS idx_videos_tags ON videos USING GIN (tags jsonb_path_ops);
+
+CREATE INDEX IF NOT EXISTS idx_videos_channel_id ON videos (channel_id);
+
+CREATE INDEX IF NOT EXISTS idx_videos_cefr_duration ON videos (cefr, duration);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_videos_cefr_duration;
+
+DROP INDEX IF EXISTS idx_videos_channel_id;
+
+DROP INDEX IF EXISTS idx_videos_tags;
+
+DROP INDEX IF EXISTS idx_videos_categories;
+
+ALTER TABLE videos
+DROP COLUMN IF EXISTS subtitle_languages,
+DROP COLUMN IF EXISTS channel_id,
+DROP COLUMN IF EXISTS channel;
+
+-- +goose StatementEnd
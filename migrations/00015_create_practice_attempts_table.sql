@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE
+    IF NOT EXISTS practice_attempts (
+        id TEXT PRIMARY KEY DEFAULT gen_random_uuid (),
+        user_id TEXT NOT NULL,
+        segment_id TEXT,
+        cefr TEXT NOT NULL DEFAULT '',
+        created_at TIMESTAMPTZ NOT NULL DEFAULT now (),
+        updated_at TIMESTAMPTZ NOT NULL DEFAULT now ()
+    );
+
+CREATE INDEX IF NOT EXISTS idx_practice_attempts_user_id_created_at ON practice_attempts (user_id, created_at DESC);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS practice_attempts;
+
+-- +goose StatementEnd
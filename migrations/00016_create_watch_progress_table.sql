@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE
+    IF NOT EXISTS watch_progress (
+        id TEXT PRIMARY KEY DEFAULT gen_random_uuid (),
+        user_id TEXT NOT NULL,
+        video_id TEXT NOT NULL,
+        segment_id TEXT,
+        position_sec REAL NOT NULL DEFAULT 0.0,
+        completed BOOLEAN NOT NULL DEFAULT FALSE,
+        created_at TIMESTAMPTZ NOT NULL DEFAULT now (),
+        updated_at TIMESTAMPTZ NOT NULL DEFAULT now (),
+        UNIQUE (user_id, video_id)
+    );
+
+CREATE INDEX IF NOT EXISTS idx_watch_progress_user_id_updated_at_id ON watch_progress (user_id, updated_at DESC, id DESC);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS watch_progress;
+
+-- +goose StatementEnd
@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE
+    IF NOT EXISTS video_views (
+        video_id TEXT NOT NULL,
+        viewer_id TEXT NOT NULL,
+        window_start TIMESTAMPTZ NOT NULL,
+        PRIMARY KEY (video_id, viewer_id, window_start)
+    );
+
+CREATE INDEX IF NOT EXISTS idx_video_views_window_start ON video_views (window_start);
+
+CREATE TABLE
+    IF NOT EXISTS video_views_daily (
+        video_id TEXT NOT NULL,
+        day DATE NOT NULL,
+        views BIGINT NOT NULL DEFAULT 0,
+        PRIMARY KEY (video_id, day)
+    );
+
+CREATE INDEX IF NOT EXISTS idx_video_views_daily_day_video_id ON video_views_daily (day, video_id);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS video_views_daily;
+
+DROP TABLE IF EXISTS video_views;
+
+-- +goose StatementEnd
@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE
+    IF NOT EXISTS vocabulary (
+        id TEXT PRIMARY KEY DEFAULT gen_random_uuid (),
+        user_id TEXT NOT NULL,
+        lemma TEXT NOT NULL,
+        status TEXT NOT NULL,
+        source TEXT NOT NULL DEFAULT '',
+        created_at TIMESTAMPTZ NOT NULL DEFAULT now (),
+        updated_at TIMESTAMPTZ NOT NULL DEFAULT now (),
+        UNIQUE (user_id, lemma)
+    );
+
+CREATE INDEX IF NOT EXISTS idx_vocabulary_user_id_updated_at_id ON vocabulary (user_id, updated_at DESC, id DESC);
+
+CREATE TABLE
+    IF NOT EXISTS video_lemmas (
+        video_id TEXT NOT NULL,
+        lemma TEXT NOT NULL,
+        occurrences INTEGER NOT NULL DEFAULT 0,
+        PRIMARY KEY (video_id, lemma)
+    );
+
+CREATE INDEX IF NOT EXISTS idx_video_lemmas_lemma ON video_lemmas (lemma);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS video_lemmas;
+
+DROP TABLE IF EXISTS vocabulary;
+
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE words
+ADD COLUMN IF NOT EXISTS lemma TEXT NOT NULL DEFAULT '';
+
+-- Lowercase until cmd/lemmatize stores the real lemmas.
+UPDATE words
+SET
+    lemma = lower(btrim(meaning_en));
+
+CREATE INDEX IF NOT EXISTS idx_words_user_id_lemma ON words (user_id, lemma);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_words_user_id_lemma;
+
+ALTER TABLE words
+DROP COLUMN IF EXISTS lemma;
+
+-- +goose StatementEnd
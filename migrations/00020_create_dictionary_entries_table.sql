@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE
+    IF NOT EXISTS dictionary_entries (
+        id TEXT PRIMARY KEY DEFAULT gen_random_uuid (),
+        word TEXT NOT NULL,
+        lemma TEXT NOT NULL,
+        part_of_speech TEXT NOT NULL,
+        ipa TEXT NOT NULL DEFAULT '',
+        cefr TEXT NOT NULL DEFAULT '',
+        senses JSONB NOT NULL DEFAULT '[]',
+        source TEXT NOT NULL DEFAULT '',
+        created_at TIMESTAMPTZ NOT NULL DEFAULT now (),
+        updated_at TIMESTAMPTZ NOT NULL DEFAULT now (),
+        UNIQUE (word, part_of_speech)
+    );
+
+CREATE INDEX IF NOT EXISTS idx_dictionary_entries_lemma ON dictionary_entries (lemma);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS dictionary_entries;
+
+-- +goose StatementEnd
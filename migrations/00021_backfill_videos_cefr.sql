@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The level of a video is the median level of its segments, as computed by
+-- model.SegmentsLevel when a video is created.
+UPDATE videos
+SET
+    cefr = levels.cefr
+FROM
+    (
+        SELECT
+            video_id,
+            (ARRAY['A1', 'A2', 'B1', 'B2', 'C1', 'C2']) [
+                percentile_disc(0.5) WITHIN GROUP (
+                    ORDER BY
+                        array_position(ARRAY['A1', 'A2', 'B1', 'B2', 'C1', 'C2'], cefr)
+                )
+            ] AS cefr
+        FROM
+            segments
+        WHERE
+            cefr IN ('A1', 'A2', 'B1', 'B2', 'C1', 'C2')
+        GROUP BY
+            video_id
+    ) AS levels
+WHERE
+    videos.id = levels.video_id
+    AND videos.cefr = '';
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+-- Levels set at creation cannot be told apart from backfilled ones, so they
+-- are kept.
+SELECT 1;
+
+-- +goose StatementEnd
@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE videos
+ADD COLUMN IF NOT EXISTS cefr_shares JSONB NOT NULL DEFAULT '[]';
+
+-- The share of segments at or below each level, as computed by
+-- model.SegmentsCefrShares when a video is created.
+UPDATE videos
+SET
+    cefr_shares = shares.cefr_shares
+FROM
+    (
+        SELECT
+            video_id,
+            jsonb_build_array(
+                avg(CASE WHEN array_position(ARRAY['A1', 'A2', 'B1', 'B2', 'C1', 'C2'], cefr) <= 1 THEN 1 ELSE 0 END),
+                avg(CASE WHEN array_position(ARRAY['A1', 'A2', 'B1', 'B2', 'C1', 'C2'], cefr) <= 2 THEN 1 ELSE 0 END),
+                avg(CASE WHEN array_position(ARRAY['A1', 'A2', 'B1', 'B2', 'C1', 'C2'], cefr) <= 3 THEN 1 ELSE 0 END),
+                avg(CASE WHEN array_position(ARRAY['A1', 'A2', 'B1', 'B2', 'C1', 'C2'], cefr) <= 4 THEN 1 ELSE 0 END),
+                avg(CASE WHEN array_position(ARRAY['A1', 'A2', 'B1', 'B2', 'C1', 'C2'], cefr) <= 5 THEN 1 ELSE 0 END),
+                avg(CASE WHEN array_position(ARRAY['A1', 'A2', 'B1', 'B2', 'C1', 'C2'], cefr) <= 6 THEN 1 ELSE 0 END)
+            ) AS cefr_shares
+        FROM
+            segments
+        GROUP BY
+            video_id
+    ) AS shares
+WHERE
+    videos.id = shares.video_id;
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE videos
+DROP COLUMN IF EXISTS cefr_shares;
+
+-- +goose StatementEnd
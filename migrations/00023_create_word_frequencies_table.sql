@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE
+    IF NOT EXISTS word_frequencies (
+        lemma TEXT PRIMARY KEY,
+        rank INTEGER NOT NULL,
+        count BIGINT NOT NULL DEFAULT 0
+    );
+
+CREATE INDEX IF NOT EXISTS idx_word_frequencies_rank ON word_frequencies (rank);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS word_frequencies;
+
+-- +goose StatementEnd
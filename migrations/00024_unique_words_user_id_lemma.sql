@@ -0,0 +1,40 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Inflected forms of a word are the same saved word, the oldest is kept.
+DELETE FROM words
+WHERE
+    id IN (
+        SELECT
+            id
+        FROM
+            (
+                SELECT
+                    id,
+                    row_number() OVER (
+                        PARTITION BY
+                            user_id,
+                            lemma
+                        ORDER BY
+                            created_at,
+                            id
+                    ) AS rank
+                FROM
+                    words
+            ) AS ranked
+        WHERE
+            rank > 1
+    );
+
+DROP INDEX IF EXISTS idx_words_user_id_lemma;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_words_user_id_lemma ON words (user_id, lemma);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+-- Deleted duplicates cannot be restored.
+DROP INDEX IF EXISTS idx_words_user_id_lemma;
+
+CREATE INDEX IF NOT EXISTS idx_words_user_id_lemma ON words (user_id, lemma);
+
+-- +goose StatementEnd